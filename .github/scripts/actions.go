@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// actionsOutput is one step output for GitHub Actions
+type actionsOutput struct {
+	Key   string
+	Value string
+}
+
+// runOutputs summarizes a run as step outputs: the movies added, removed and
+// updated compared to the previous list, the failures, the list size and
+// whether the list changed. If the comparison failed, the diff outputs are
+// left out rather than reporting an unchanged list, and diff_error says why.
+func runOutputs(diff MovieDiff, diffErr error, movies []Movie, failures []Failure) []actionsOutput {
+	if diffErr != nil {
+		return []actionsOutput{
+			{Key: "failed", Value: strconv.Itoa(len(failures))},
+			{Key: "total", Value: strconv.Itoa(len(movies))},
+			// Outputs are one line each
+			{Key: "diff_error", Value: strings.Join(strings.Fields(diffErr.Error()), " ")},
+		}
+	}
+	return []actionsOutput{
+		{Key: "added", Value: strconv.Itoa(len(diff.Added))},
+		{Key: "removed", Value: strconv.Itoa(len(diff.Removed))},
+		{Key: "updated", Value: strconv.Itoa(len(diff.Changed))},
+		{Key: "failed", Value: strconv.Itoa(len(failures))},
+		{Key: "total", Value: strconv.Itoa(len(movies))},
+		{Key: "changed", Value: strconv.FormatBool(diff.HasChanges())},
+	}
+}
+
+// writeActionsOutputs appends outputs as key=value lines to the file named by
+// $GITHUB_OUTPUT. Outside GitHub Actions the variable is unset and nothing is
+// written.
+func writeActionsOutputs(outputs []actionsOutput) error {
+	filename := os.Getenv("GITHUB_OUTPUT")
+	if filename == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open GITHUB_OUTPUT: %w", err)
+	}
+	for _, output := range outputs {
+		if _, err := fmt.Fprintf(f, "%s=%s\n", output.Key, output.Value); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write GITHUB_OUTPUT: %w", err)
+		}
+	}
+	return f.Close()
+}
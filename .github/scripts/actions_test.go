@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteActionsOutputs(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "github_output")
+	if err := os.WriteFile(filename, []byte("earlier=step\n"), 0644); err != nil {
+		t.Fatalf("Failed to create output file: %v", err)
+	}
+	t.Setenv("GITHUB_OUTPUT", filename)
+
+	movies := []Movie{
+		{Title: "Space Jam", IMDBID: "tt0117705"},
+		{Title: "Ghost", IMDBID: "tt0099653"},
+	}
+	diff, err := diffAgainstFile(movies, filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("Expected a missing previous list to count as empty, got %v", err)
+	}
+	failures := []Failure{{Title: "Unknown Movie", Category: failureNoResults}}
+
+	if err := writeActionsOutputs(runOutputs(diff, nil, movies, failures)); err != nil {
+		t.Fatalf("Failed to write outputs: %v", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	expected := "earlier=step\nadded=2\nremoved=0\nupdated=0\nfailed=1\ntotal=2\nchanged=true\n"
+	if string(data) != expected {
+		t.Errorf("Unexpected outputs:\n%s\nexpected:\n%s", data, expected)
+	}
+}
+
+func TestWriteActionsOutputsOutsideActions(t *testing.T) {
+	t.Setenv("GITHUB_OUTPUT", "")
+
+	if err := writeActionsOutputs(runOutputs(MovieDiff{}, nil, nil, nil)); err != nil {
+		t.Errorf("Expected a no-op outside GitHub Actions, got %v", err)
+	}
+}
+
+func TestWriteActionsOutputsDiffFailed(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "github_output")
+	t.Setenv("GITHUB_OUTPUT", filename)
+
+	movies := []Movie{{Title: "Ghost", IMDBID: "tt0099653"}}
+	diffErr := errors.New("failed to parse previous list:\nunexpected end of JSON input")
+	if err := writeActionsOutputs(runOutputs(MovieDiff{}, diffErr, movies, nil)); err != nil {
+		t.Fatalf("Failed to write outputs: %v", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	expected := "failed=0\ntotal=1\ndiff_error=failed to parse previous list: unexpected end of JSON input\n"
+	if string(data) != expected {
+		t.Errorf("Unexpected outputs:\n%s\nexpected:\n%s", data, expected)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ArchiveIndex is the -archive-index file: every run whose timestamped copies
+// still exist, oldest first
+type ArchiveIndex struct {
+	Runs []ArchivedRun `json:"runs"`
+}
+
+// ArchivedRun is one run's timestamped copies, with paths relative to the
+// index file
+type ArchivedRun struct {
+	RunID     string    `json:"run_id"`
+	Timestamp time.Time `json:"timestamp"`
+	Movies    int       `json:"movies"`
+	Files     []string  `json:"files"`
+}
+
+// loadArchiveIndex reads an archive index, returning an empty index if the
+// file doesn't exist yet
+func loadArchiveIndex(filename string) (ArchiveIndex, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return ArchiveIndex{}, nil
+	}
+	if err != nil {
+		return ArchiveIndex{}, fmt.Errorf("failed to read archive index: %w", err)
+	}
+
+	var index ArchiveIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return ArchiveIndex{}, fmt.Errorf("failed to parse archive index: %w", err)
+	}
+	return index, nil
+}
+
+// updateArchiveIndex adds this run's timestamped copies to the index in
+// filename and drops files that no longer exist, along with runs left
+// without any
+func (s *Scraper) updateArchiveIndex(filename string, files []string, movies int) error {
+	index, err := loadArchiveIndex(filename)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(filename)
+	run := ArchivedRun{RunID: s.runID, Timestamp: s.now().UTC().Truncate(time.Second), Movies: movies}
+	for _, file := range files {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return fmt.Errorf("failed to index %s: %w", file, err)
+		}
+		run.Files = append(run.Files, filepath.ToSlash(rel))
+	}
+	index.Runs = append(index.Runs, run)
+
+	kept := make([]ArchivedRun, 0, len(index.Runs))
+	pruned := 0
+	for _, run := range index.Runs {
+		existing := run.Files[:0]
+		for _, file := range run.Files {
+			if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(file))); err == nil {
+				existing = append(existing, file)
+			}
+		}
+		if len(existing) == 0 {
+			pruned++
+			continue
+		}
+		run.Files = existing
+		kept = append(kept, run)
+	}
+	index.Runs = kept
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode archive index: %w", err)
+	}
+	if err := s.writeFile(filename, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write archive index: %w", err)
+	}
+
+	fmt.Fprintf(s.logWriter(), "Updated archive index %s: %d runs (%d pruned)\n", filename, len(index.Runs), pruned)
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUpdateArchiveIndex(t *testing.T) {
+	dir := t.TempDir()
+	index := filepath.Join(dir, "index.json")
+	writeFixture(t, dir, "scott_hasnt_seen_20240101_000000.json", "[]")
+	writeFixture(t, dir, "scott_hasnt_seen_20240102_000000.json", "[]")
+	writeFixture(t, dir, "scott_hasnt_seen_20240102_000000.xml", "<rss/>")
+
+	scraper := NewScraper("dummy_key", WithRunID("run-1"), WithClock(fixedClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))))
+	first := []string{filepath.Join(dir, "scott_hasnt_seen_20240101_000000.json")}
+	if err := scraper.updateArchiveIndex(index, first, 10); err != nil {
+		t.Fatalf("Failed to update index: %v", err)
+	}
+
+	// The first run's archive is deleted before the second run
+	if err := os.Remove(first[0]); err != nil {
+		t.Fatalf("Failed to remove archive: %v", err)
+	}
+	scraper = NewScraper("dummy_key", WithRunID("run-2"), WithClock(fixedClock(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))))
+	second := []string{
+		filepath.Join(dir, "scott_hasnt_seen_20240102_000000.json"),
+		filepath.Join(dir, "scott_hasnt_seen_20240102_000000.xml"),
+	}
+	if err := scraper.updateArchiveIndex(index, second, 12); err != nil {
+		t.Fatalf("Failed to update index: %v", err)
+	}
+
+	got, err := loadArchiveIndex(index)
+	if err != nil {
+		t.Fatalf("Failed to load index: %v", err)
+	}
+	expected := ArchiveIndex{Runs: []ArchivedRun{{
+		RunID:     "run-2",
+		Timestamp: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Movies:    12,
+		Files:     []string{"scott_hasnt_seen_20240102_000000.json", "scott_hasnt_seen_20240102_000000.xml"},
+	}}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+
+	if _, err := parseFlags([]string{"-archive-index", index, "-no-timestamp"}); err == nil {
+		t.Error("Expected -archive-index with -no-timestamp to be rejected")
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// circuitBreaker stops requests to a host after too many consecutive
+// failures, so an outage fails the rest of a run fast instead of grinding
+// through every title. With a cooldown it half-opens: once the cooldown has
+// passed a single request is let through, and its outcome closes the breaker
+// or opens it for another cooldown.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration // 0 keeps the breaker open for the rest of the run
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int // consecutive
+	open     bool
+	openedAt time.Time
+	probing  bool // a half-open trial request is in flight
+	trips    int
+	rejected int
+}
+
+// allow reports whether a request may be sent
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.open {
+		return true
+	}
+	if b.cooldown > 0 && !b.probing && b.now().Sub(b.openedAt) >= b.cooldown {
+		b.probing = true
+		return true
+	}
+	b.rejected++
+	return false
+}
+
+// record notes the outcome of a request that allow let through
+func (b *circuitBreaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !failed {
+		b.failures = 0
+		b.open = false
+		b.probing = false
+		return
+	}
+
+	b.failures++
+	if b.probing || (!b.open && b.failures >= b.threshold) {
+		if !b.open {
+			b.trips++
+		}
+		b.open = true
+		b.openedAt = b.now()
+		b.probing = false
+	}
+}
+
+// requestFailed reports whether a response counts against the breaker: a
+// network error or a server error. Other statuses, including rate limiting,
+// which -retries already backs off from, show the host is answering.
+func requestFailed(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+// breakerFor returns the breaker for a host, or nil if -breaker-threshold is 0
+func (s *Scraper) breakerFor(host string) *circuitBreaker {
+	if s.config.BreakerThreshold <= 0 {
+		return nil
+	}
+	host = strings.ToLower(host)
+
+	s.breakersMu.Lock()
+	defer s.breakersMu.Unlock()
+	if s.breakers == nil {
+		s.breakers = make(map[string]*circuitBreaker)
+	}
+	breaker, ok := s.breakers[host]
+	if !ok {
+		breaker = &circuitBreaker{threshold: s.config.BreakerThreshold, cooldown: s.config.BreakerCooldown, now: s.now}
+		s.breakers[host] = breaker
+	}
+	return breaker
+}
+
+// printBreakerTrips reports the hosts whose breaker tripped during the run
+func (s *Scraper) printBreakerTrips() {
+	s.breakersMu.Lock()
+	defer s.breakersMu.Unlock()
+
+	hosts := make([]string, 0, len(s.breakers))
+	for host, breaker := range s.breakers {
+		if breaker.trips > 0 {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+
+	for _, host := range hosts {
+		breaker := s.breakers[host]
+		breaker.mu.Lock()
+		fmt.Fprintf(s.logWriter(), "  Circuit breaker tripped for %s after %d consecutive failures: %d requests skipped\n",
+			host, breaker.threshold, breaker.rejected)
+		breaker.mu.Unlock()
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	breaker := &circuitBreaker{threshold: 2, cooldown: time.Minute, now: func() time.Time { return now }}
+
+	// A success resets the count of consecutive failures (record takes whether
+	// the request failed)
+	breaker.record(true)
+	breaker.record(false)
+	breaker.record(true)
+	if !breaker.allow() {
+		t.Fatal("Expected the breaker to stay closed")
+	}
+
+	breaker.record(true)
+	breaker.record(true)
+	if breaker.allow() {
+		t.Fatal("Expected the breaker to open after 2 consecutive failures")
+	}
+
+	// After the cooldown a single trial request goes through
+	now = now.Add(time.Minute)
+	if !breaker.allow() {
+		t.Fatal("Expected a trial request after the cooldown")
+	}
+	if breaker.allow() {
+		t.Error("Expected only one trial request at a time")
+	}
+
+	// A failed trial opens the breaker for another cooldown; a successful one closes it
+	breaker.record(true)
+	if breaker.allow() {
+		t.Error("Expected a failed trial to reopen the breaker")
+	}
+	now = now.Add(time.Minute)
+	if !breaker.allow() {
+		t.Fatal("Expected another trial request after the cooldown")
+	}
+	breaker.record(false)
+	if !breaker.allow() {
+		t.Error("Expected a successful trial to close the breaker")
+	}
+	if breaker.trips != 1 || breaker.rejected != 3 {
+		t.Errorf("Expected 1 trip and 3 rejected requests, got %d and %d", breaker.trips, breaker.rejected)
+	}
+}
+
+func TestCircuitBreakerFailsFast(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := defaultConfig()
+	cfg.BreakerThreshold = 2
+	scraper := NewScraper("dummy_key", WithConfig(cfg))
+	scraper.tmdbBaseURL = server.URL
+
+	for i := 1; i <= 5; i++ {
+		_, err := scraper.searchMovie(fmt.Sprintf("Outage %d", i))
+		expected := failureHTTPError
+		if i > 2 {
+			expected = failureCircuitOpen
+		}
+		if got := failureCategory(err); got != expected {
+			t.Errorf("Title %d: expected category %s, got %s (%v)", i, expected, got, err)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests before the breaker opened, got %d", requests)
+	}
+
+	output := captureStdout(t, scraper.printBreakerTrips)
+	if !strings.Contains(output, "Circuit breaker tripped for 127.0.0.1 after 2 consecutive failures: 3 requests skipped") {
+		t.Errorf("Expected the trip in the summary, got %q", output)
+	}
+}
+
+func TestCircuitBreakerIgnoresRateLimiting(t *testing.T) {
+	if requestFailed(&http.Response{StatusCode: http.StatusTooManyRequests}, nil) {
+		t.Error("Expected rate limiting not to count against the breaker")
+	}
+	if !requestFailed(&http.Response{StatusCode: http.StatusBadGateway}, nil) {
+		t.Error("Expected a server error to count against the breaker")
+	}
+}
+
+func TestCircuitBreakerTrialSurvivesQuota(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	cfg := defaultConfig()
+	cfg.BreakerThreshold = 1
+	cfg.MaxRequests = 1
+	scraper := NewScraper("dummy_key", WithConfig(cfg))
+	scraper.tmdbBaseURL = server.URL
+	scraper.now = func() time.Time { return now }
+
+	scraper.searchMovie("Outage")
+	now = now.Add(cfg.BreakerCooldown)
+
+	// The quota refuses the request before the breaker hands out its trial
+	if _, err := scraper.searchMovie("Outage 2"); !errors.Is(err, errQuotaExceeded) {
+		t.Fatalf("Expected the quota to refuse the request, got %v", err)
+	}
+	breaker := scraper.breakerFor("127.0.0.1")
+	if breaker.probing {
+		t.Error("Expected no trial request to be left in flight")
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request, got %d", requests)
+	}
+}
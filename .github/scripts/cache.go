@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// resolveCache remembers TMDB lookups so repeated titles and movies don't
+// need another request. It is safe for concurrent use by the workers: every
+// access holds mu, and movies are copied in and out, so no two workers share
+// a movie's slices. It is written to disk only once the workers have finished,
+// by -warm-cache and -resolved-set.
+type resolveCache struct {
+	mu      sync.Mutex
+	titles  map[string]Movie // normalized title -> resolved movie
+	imdbIDs map[int]string   // TMDB ID -> IMDB ID
+
+	hits int64 // accessed atomically
+}
+
+func newResolveCache() *resolveCache {
+	return &resolveCache{
+		titles:  make(map[string]Movie),
+		imdbIDs: make(map[int]string),
+	}
+}
+
+// title returns the cached movie for a title
+func (c *resolveCache) title(title string) (Movie, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	movie, ok := c.titles[normalizeTitle(title)]
+	if ok {
+		atomic.AddInt64(&c.hits, 1)
+	}
+	return cloneMovie(movie), ok
+}
+
+// storeTitle caches the movie a title resolved to
+func (c *resolveCache) storeTitle(title string, movie Movie) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.titles[normalizeTitle(title)] = cloneMovie(movie)
+}
+
+// imdbID returns the cached IMDB ID for a TMDB ID
+func (c *resolveCache) imdbID(tmdbID int) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	imdbID, ok := c.imdbIDs[tmdbID]
+	if ok {
+		atomic.AddInt64(&c.hits, 1)
+	}
+	return imdbID, ok
+}
+
+// storeIMDBID caches the IMDB ID of a TMDB movie
+func (c *resolveCache) storeIMDBID(tmdbID int, imdbID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.imdbIDs[tmdbID] = imdbID
+}
+
+// titlesCopy returns a copy of the cached movies, keyed by normalized title
+func (c *resolveCache) titlesCopy() map[string]Movie {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	titles := make(map[string]Movie, len(c.titles))
+	for title, movie := range c.titles {
+		titles[title] = cloneMovie(movie)
+	}
+	return titles
+}
+
+// cloneMovie copies a movie along with its slices, so the copy can be changed
+// without affecting the original
+func cloneMovie(movie Movie) Movie {
+	movie.Genres = slices.Clone(movie.Genres)
+	movie.Keywords = slices.Clone(movie.Keywords)
+	movie.Episodes = slices.Clone(movie.Episodes)
+	movie.episodeTitles = slices.Clone(movie.episodeTitles)
+	return movie
+}
+
+// seed fills the cache from a previously exported list, keyed by each movie's
+// title and original title. Placeholders and entries without a valid IMDB ID
+// and TMDB ID are skipped; the number of skipped entries is returned.
+func (c *resolveCache) seed(movies []Movie) (seeded, skipped int) {
+	for _, movie := range movies {
+		if movie.IsPlaceholder() || movie.Title == "" || movie.TMDBID <= 0 || !imdbIDPattern.MatchString(movie.IMDBID) {
+			skipped++
+			continue
+		}
+
+		// Match info describes the original resolution, not this run's
+		movie.MatchConfidence = 0
+		movie.MatchMethod = ""
+		movie.SearchResults = 0
+
+		c.storeTitle(movie.Title, movie)
+		if movie.OriginalTitle != "" {
+			c.storeTitle(movie.OriginalTitle, movie)
+		}
+		c.storeIMDBID(movie.TMDBID, movie.IMDBID)
+		seeded++
+	}
+	return seeded, skipped
+}
+
+// importCache seeds the cache from a list file written by a previous run, or
+// from a cache file written by -warm-cache
+func (s *Scraper) importCache(filename string) error {
+	if titles, ok, err := loadCacheFile(filename); err != nil {
+		return err
+	} else if ok {
+		for title, movie := range titles {
+			s.cache.storeTitle(title, movie)
+			if movie.TMDBID > 0 && movie.IMDBID != "" {
+				s.cache.storeIMDBID(movie.TMDBID, movie.IMDBID)
+			}
+		}
+		fmt.Fprintf(s.logWriter(), "Seeded cache with %d titles from %s\n", len(titles), filename)
+		return nil
+	}
+
+	movies, err := loadMovies(filename)
+	if err != nil {
+		return err
+	}
+
+	seeded, skipped := s.cache.seed(movies)
+	fmt.Fprintf(s.logWriter(), "Seeded cache with %d movies from %s (%d malformed entries skipped)\n", seeded, filename, skipped)
+	return nil
+}
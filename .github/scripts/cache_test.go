@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResolveCacheSeedSkipsMalformed(t *testing.T) {
+	cache := newResolveCache()
+	seeded, skipped := cache.seed([]Movie{
+		{Title: "Spirited Away", OriginalTitle: "千と千尋の神隠し", IMDBID: "tt0245429", TMDBID: 129, MatchMethod: matchMethodExact},
+		{Title: "No TMDB ID", IMDBID: "tt0117705"},
+		{Title: "Bad IMDB ID", IMDBID: "nm0000001", TMDBID: 2300},
+		{Title: "", IMDBID: "tt0099653", TMDBID: 251},
+		newPlaceholder("Unknown Movie"),
+	})
+	if seeded != 1 || skipped != 4 {
+		t.Errorf("Expected 1 seeded and 4 skipped, got %d and %d", seeded, skipped)
+	}
+
+	for _, title := range []string{"spirited away", "千と千尋の神隠し"} {
+		movie, ok := cache.title(title)
+		if !ok || movie.TMDBID != 129 {
+			t.Errorf("Expected a cache hit for %q, got %+v", title, movie)
+		}
+		if movie.MatchMethod != "" {
+			t.Errorf("Expected seeded match info to be cleared, got %q", movie.MatchMethod)
+		}
+	}
+	if imdbID, ok := cache.imdbID(129); !ok || imdbID != "tt0245429" {
+		t.Errorf("Expected the IMDB ID to be cached, got %q", imdbID)
+	}
+	if _, ok := cache.imdbID(2300); ok {
+		t.Error("Expected the malformed entry not to be cached")
+	}
+}
+
+func TestImportCacheAvoidsTMDBRequests(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "scott_hasnt_seen.json")
+	exported := []Movie{
+		{Title: "Space Jam", IMDBID: "tt0117705", TMDBID: 2300, Year: 1996},
+		{Title: "Ghost", IMDBID: "tt0099653", TMDBID: 251, Year: 1990},
+	}
+	if err := NewScraper("dummy_key").saveToFile(exported, filename); err != nil {
+		t.Fatalf("Failed to export list: %v", err)
+	}
+
+	// The mock knows nothing, so only cached titles can resolve
+	mock := newMockTMDB(t, []string{"Space Jam", "Ghost"}, nil)
+	scraper := mock.newTestScraper(defaultConfig())
+	if err := scraper.importCache(filename); err != nil {
+		t.Fatalf("Failed to import cache: %v", err)
+	}
+
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if len(movies) != 2 {
+		t.Errorf("Expected both movies from the cache, got %+v", movies)
+	}
+	if requests := atomic.LoadInt64(&scraper.tmdbRequests); requests != 0 {
+		t.Errorf("Expected no TMDB requests, got %d", requests)
+	}
+	if hits := atomic.LoadInt64(&scraper.cache.hits); hits != 2 {
+		t.Errorf("Expected 2 cache hits, got %d", hits)
+	}
+}
+
+func TestRepeatedTitlesLookedUpOnce(t *testing.T) {
+	mock := newMockTMDB(t, nil, mockCatalog)
+	var searches int64
+	mock.searchHook = func(query string) {
+		atomic.AddInt64(&searches, 1)
+		// Keep the first lookup in flight while the repeats are queued, so
+		// the cache can't be what saves them
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	scraper := mock.newTestScraper(defaultConfig())
+	movies, err := scraper.resolveTitles(context.Background(), []string{"Space Jam", "space jam!", "Ghost", "Space Jam"})
+	if err != nil {
+		t.Fatalf("Failed to resolve titles: %v", err)
+	}
+	if len(movies) != 2 {
+		t.Errorf("Expected Ghost and Space Jam, got %+v", movies)
+	}
+	if got := atomic.LoadInt64(&searches); got != 2 {
+		t.Errorf("Expected one search per distinct title, got %d", got)
+	}
+}
+
+// TestConcurrentCacheWrites hammers the cache and the checkpoint from many
+// goroutines at once, as the worker pool does. Run it with -race.
+func TestConcurrentCacheWrites(t *testing.T) {
+	var recorded bytes.Buffer
+	scraper := NewScraper("dummy_key")
+	WithCheckpoint(&recorded, nil)(scraper)
+
+	const workers, titles = 50, 20
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < titles; i++ {
+				title := fmt.Sprintf("Movie %d", i)
+				movie := Movie{Title: title, IMDBID: fmt.Sprintf("tt%07d", i), TMDBID: i + 1, Genres: []string{"comedy"}}
+				scraper.cache.storeTitle(title, movie)
+				scraper.cache.storeIMDBID(movie.TMDBID, movie.IMDBID)
+
+				// Changing a cached movie must not reach the cache or other workers
+				if cached, ok := scraper.cache.title(title); ok {
+					cached.Genres[0] = fmt.Sprintf("worker %d", w)
+				}
+				scraper.cache.imdbID(movie.TMDBID)
+				scraper.recordCheckpoint(title, movie)
+				if i%5 == 0 {
+					scraper.cache.titlesCopy()
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	cached := scraper.cache.titlesCopy()
+	if len(cached) != titles {
+		t.Fatalf("Expected %d cached titles, got %d", titles, len(cached))
+	}
+	for title, movie := range cached {
+		if !reflect.DeepEqual(movie.Genres, []string{"comedy"}) {
+			t.Errorf("Expected %s's cached genres to be untouched, got %v", title, movie.Genres)
+		}
+	}
+
+	// Every checkpoint line is whole, however the writes interleaved
+	lines := strings.Split(strings.TrimSpace(recorded.String()), "\n")
+	if len(lines) != workers*titles {
+		t.Fatalf("Expected %d checkpoint lines, got %d", workers*titles, len(lines))
+	}
+	for _, line := range lines {
+		var entry checkpointEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil || entry.Title == "" {
+			t.Fatalf("Malformed checkpoint line %q: %v", line, err)
+		}
+	}
+}
+
+func TestRepeatedTitlesMergeMetadata(t *testing.T) {
+	mock := newMockTMDB(t, nil, mockCatalog)
+	cfg := defaultConfig()
+	cfg.MergeEpisodes = true
+	scraper := mock.newTestScraper(cfg)
+	scraper.recordAirDate("Space Jam", time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC))
+	scraper.recordAirDate("space jam!", time.Date(2021, time.June, 14, 0, 0, 0, 0, time.UTC))
+	scraper.recordGuest("space jam!", "Paul Scheer")
+	scraper.recordEpisodeTitle("space jam!", "Jordan Rules")
+
+	movies, err := scraper.resolveTitles(context.Background(), []string{"Space Jam", "space jam!"})
+	if err != nil {
+		t.Fatalf("Failed to resolve titles: %v", err)
+	}
+	if len(movies) != 1 {
+		t.Fatalf("Expected one Space Jam, got %+v", movies)
+	}
+	movie := movies[0]
+	if len(movie.Episodes) != 2 || movie.Episodes[1] != "2021-06-14" || movie.Guest != "Paul Scheer" {
+		t.Errorf("Expected the repeat's episodes and guest on the movie, got %+v", movie)
+	}
+	if len(movie.episodeTitles) != 1 || !movie.lastAired.Equal(time.Date(2021, time.June, 14, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the repeat's episode title and air date, got %v and %s", movie.episodeTitles, movie.lastAired)
+	}
+}
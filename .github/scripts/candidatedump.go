@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// CandidateDump is one line of the -dump-candidates file: a TMDB search and
+// every candidate it returned, scored as the match strategies see them
+type CandidateDump struct {
+	Title        string            `json:"title"`
+	Query        string            `json:"query"`
+	Year         int               `json:"year,omitempty"` // the wiki's year hint
+	TotalResults int               `json:"total_results"`
+	Strategy     string            `json:"strategy"`
+	ChosenID     int               `json:"chosen_tmdb_id,omitempty"`
+	Candidates   []DumpedCandidate `json:"candidates"`
+}
+
+// DumpedCandidate is a search result in a CandidateDump
+type DumpedCandidate struct {
+	TMDBID        int     `json:"tmdb_id"`
+	Title         string  `json:"title"`
+	OriginalTitle string  `json:"original_title,omitempty"`
+	Year          int     `json:"year,omitempty"`
+	Similarity    float64 `json:"similarity"`
+	Popularity    float64 `json:"popularity"`
+	Votes         int     `json:"votes"`
+	Chosen        bool    `json:"chosen,omitempty"`
+}
+
+// candidateDump writes CandidateDumps to a writer as JSON lines. It is safe
+// for concurrent use.
+type candidateDump struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// WithCandidateDump records every TMDB search's candidates to w as JSON lines
+func WithCandidateDump(w io.Writer) Option {
+	return func(s *Scraper) {
+		s.candidateDump = &candidateDump{enc: json.NewEncoder(w)}
+	}
+}
+
+// dumpCandidates records the candidates a search for title returned and the
+// one chosen, if any. Nothing is recorded without -dump-candidates.
+func (s *Scraper) dumpCandidates(title string, query matchQuery, totalResults int, chosen int, candidates []TMDBMovie) {
+	if s.candidateDump == nil {
+		return
+	}
+
+	entry := CandidateDump{
+		Title:        title,
+		Query:        query.Title,
+		Year:         query.Year,
+		TotalResults: totalResults,
+		Strategy:     s.config.MatchStrategy,
+		ChosenID:     chosen,
+		Candidates:   make([]DumpedCandidate, 0, len(candidates)),
+	}
+	for _, candidate := range candidates {
+		dumped := DumpedCandidate{
+			TMDBID:        candidate.ID,
+			Title:         candidate.Title,
+			OriginalTitle: candidate.OriginalTitle,
+			Similarity:    titleSimilarity(query.Title, candidate.Title),
+			Popularity:    candidate.Popularity,
+			Votes:         candidate.VoteCount,
+			Chosen:        chosen != 0 && candidate.ID == chosen,
+		}
+		if !candidate.ReleaseDate.IsZero() {
+			dumped.Year = candidate.ReleaseDate.Year()
+		}
+		entry.Candidates = append(entry.Candidates, dumped)
+	}
+
+	s.candidateDump.mu.Lock()
+	defer s.candidateDump.mu.Unlock()
+	s.candidateDump.enc.Encode(entry)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestDumpCandidates(t *testing.T) {
+	mock := newMockTMDB(t, []string{"Space Jam", "Unknown Movie"}, mockCatalog)
+	scraper := mock.newTestScraper(defaultConfig())
+	var dump bytes.Buffer
+	WithCandidateDump(&dump)(scraper)
+
+	if _, err := scraper.generateRadarrList(context.Background()); err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+
+	// Titles resolve in parallel, so the lines may be in either order
+	entries := make(map[string]CandidateDump)
+	scanner := bufio.NewScanner(&dump)
+	for scanner.Scan() {
+		var entry CandidateDump
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Failed to parse dump line %q: %v", scanner.Text(), err)
+		}
+		entries[entry.Title] = entry
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected a line per title, got %+v", entries)
+	}
+
+	spaceJam := entries["Space Jam"]
+	if spaceJam.Query != "Space Jam" || spaceJam.Strategy != matchStrategyFirst || spaceJam.ChosenID != 2300 {
+		t.Errorf("Unexpected Space Jam entry %+v", spaceJam)
+	}
+	if len(spaceJam.Candidates) != 1 || !spaceJam.Candidates[0].Chosen || spaceJam.Candidates[0].Year != 1996 || spaceJam.Candidates[0].Similarity != 1 {
+		t.Errorf("Expected the scored, chosen candidate, got %+v", spaceJam.Candidates)
+	}
+
+	if unknown := entries["Unknown Movie"]; unknown.ChosenID != 0 || len(unknown.Candidates) != 0 {
+		t.Errorf("Expected a search without candidates, got %+v", unknown)
+	}
+}
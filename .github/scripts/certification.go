@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// certificationCountry is the release country whose certification is used
+const certificationCountry = "US"
+
+// releaseTypeTheatrical is TMDB's release type for a theatrical release
+const releaseTypeTheatrical = 3
+
+// certificationRanks orders the US (MPA) certifications from least to most
+// restrictive for -max-certification
+var certificationRanks = map[string]int{
+	"G":     1,
+	"PG":    2,
+	"PG-13": 3,
+	"R":     4,
+	"NC-17": 5,
+}
+
+// TMDBReleaseDates represents the response from the TMDB release dates endpoint
+type TMDBReleaseDates struct {
+	Results []struct {
+		Country      string `json:"iso_3166_1"`
+		ReleaseDates []struct {
+			Certification string `json:"certification"`
+			Type          int    `json:"type"`
+		} `json:"release_dates"`
+	} `json:"results"`
+}
+
+// usCertification picks the US certification from a release dates response,
+// preferring the theatrical release. It returns "" if there is none.
+func usCertification(releases TMDBReleaseDates) string {
+	certification := ""
+	for _, country := range releases.Results {
+		if country.Country != certificationCountry {
+			continue
+		}
+		for _, release := range country.ReleaseDates {
+			if release.Certification == "" {
+				continue
+			}
+			if release.Type == releaseTypeTheatrical {
+				return release.Certification
+			}
+			if certification == "" {
+				certification = release.Certification
+			}
+		}
+	}
+	return certification
+}
+
+// wantsCertification reports whether movies need their certification looked up
+func (c Config) wantsCertification() bool {
+	return c.FetchCertification || c.MaxCertification != ""
+}
+
+// getCertification fetches a movie's US certification from TMDB. This is one
+// extra request per movie, so it is only made with -fetch-certification or
+// -max-certification.
+func (s *Scraper) getCertification(tmdbID int) (string, error) {
+	apiURL := fmt.Sprintf("%s/movie/%d/release_dates", s.tmdbBaseURL, tmdbID)
+
+	params := url.Values{}
+	params.Add("api_key", s.tmdbAPIKey)
+
+	req, err := http.NewRequest("GET", apiURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.doRequest(req, endpointReleaseDates, strconv.Itoa(tmdbID))
+	if err != nil {
+		return "", fmt.Errorf("failed to get release dates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", &tmdbStatusError{StatusCode: resp.StatusCode, Target: "release dates"}
+	}
+
+	var releases TMDBReleaseDates
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return "", fmt.Errorf("failed to decode release dates response: %w", err)
+	}
+
+	return usCertification(releases), nil
+}
+
+// withinCertification returns the movies rated at most max, keeping unmatched
+// placeholders and movies carried over from the previous list without a saved
+// certification, along with the number of movies dropped. Other movies
+// without a known US certification are dropped while the filter is active.
+func withinCertification(movies []Movie, max string) ([]Movie, int) {
+	limit := certificationRanks[max]
+
+	kept := make([]Movie, 0, len(movies))
+	for _, movie := range movies {
+		rank, ok := certificationRanks[movie.Certification]
+		if movie.IsPlaceholder() || (movie.carried && movie.Certification == "") || (ok && rank <= limit) {
+			kept = append(kept, movie)
+		}
+	}
+	return kept, len(movies) - len(kept)
+}
+
+// validateCertification checks a -max-certification value
+func validateCertification(certification string) error {
+	if _, ok := certificationRanks[certification]; !ok {
+		return fmt.Errorf("unknown certification %q (expected G, PG, PG-13, R or NC-17)", certification)
+	}
+	return nil
+}
+
+// normalizeCertification upper-cases a certification given on the command line
+func normalizeCertification(certification string) string {
+	return strings.ToUpper(strings.TrimSpace(certification))
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestUSCertification(t *testing.T) {
+	data := `{"results": [
+		{"iso_3166_1": "GB", "release_dates": [{"certification": "12A", "type": 3}]},
+		{"iso_3166_1": "US", "release_dates": [
+			{"certification": "", "type": 1},
+			{"certification": "NR", "type": 5},
+			{"certification": "PG-13", "type": 3}
+		]}
+	]}`
+	var releases TMDBReleaseDates
+	if err := json.Unmarshal([]byte(data), &releases); err != nil {
+		t.Fatalf("Failed to decode release dates: %v", err)
+	}
+	if got := usCertification(releases); got != "PG-13" {
+		t.Errorf("Expected the theatrical PG-13, got %q", got)
+	}
+
+	releases.Results = releases.Results[:1]
+	if got := usCertification(releases); got != "" {
+		t.Errorf("Expected no certification without a US release, got %q", got)
+	}
+}
+
+func TestWithinCertification(t *testing.T) {
+	movies := []Movie{
+		{Title: "Space Jam", Certification: "PG"},
+		{Title: "Ghost", Certification: "PG-13"},
+		{Title: "RoboCop", Certification: "R"},
+		{Title: "Unrated Film", Certification: "NR"},
+		{Title: "Unknown Film"},
+		newPlaceholder("Some Obscure Film"),
+	}
+
+	kept, dropped := withinCertification(movies, "PG-13")
+	if dropped != 3 || len(kept) != 3 || kept[0].Title != "Space Jam" || kept[1].Title != "Ghost" || !kept[2].IsPlaceholder() {
+		t.Errorf("Expected Space Jam, Ghost and the placeholder with 3 dropped, got %+v (%d)", kept, dropped)
+	}
+}
+
+func TestFetchCertification(t *testing.T) {
+	catalog := append([]mockMovie{}, mockCatalog...)
+	catalog[0].Certification = "PG"    // Space Jam
+	catalog[1].Certification = "PG-13" // The Addams Family
+	catalog[2].Certification = "PG-13" // Ghost
+	mock := newMockTMDB(t, []string{"Space Jam", "The Addams Family", "Ghost"}, catalog)
+
+	cfg := defaultConfig()
+	scraper := mock.newTestScraper(cfg)
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if movies[0].Certification != "" {
+		t.Errorf("Expected no certification by default, got %+v", movies[0])
+	}
+	for _, summary := range scraper.latency.summaries() {
+		if summary.Endpoint == endpointReleaseDates {
+			t.Errorf("Expected no release dates requests by default, got %d", summary.Count)
+		}
+	}
+
+	cfg.MaxCertification = "PG"
+	movies, err = mock.newTestScraper(cfg).generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if len(movies) != 1 || movies[0].Title != "Space Jam" || movies[0].Certification != "PG" {
+		t.Errorf("Expected only Space Jam, got %+v", movies)
+	}
+
+	// The certification is only written out with -fetch-certification
+	scraper = mock.newTestScraper(cfg)
+	if data, _ := encodeMovies(scraper.prepareForOutput(movies), formatNative); containsKey(t, data, "certification") {
+		t.Errorf("Expected certification to be omitted without -fetch-certification, got %s", data)
+	}
+	cfg.FetchCertification = true
+	scraper = mock.newTestScraper(cfg)
+	if data, _ := encodeMovies(scraper.prepareForOutput(movies), formatNative); !containsKey(t, data, "certification") {
+		t.Errorf("Expected certification in the output, got %s", data)
+	}
+}
+
+// containsKey reports whether the first entry of an encoded movie list has key
+func containsKey(t *testing.T, data []byte, key string) bool {
+	t.Helper()
+	var entries []map[string]interface{}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("Failed to decode %s: %v", data, err)
+	}
+	_, ok := entries[0][key]
+	return ok
+}
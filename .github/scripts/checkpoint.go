@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// checkpointEntry is one line of the -checkpoint file: a wiki title and the
+// movie it resolved to
+type checkpointEntry struct {
+	Title string `json:"title"`
+	Movie Movie  `json:"movie"`
+}
+
+// checkpoint records each resolved title to a writer as a JSON line as soon
+// as it resolves, so a crashed run can be resumed without repeating its
+// lookups. It is safe for concurrent use.
+type checkpoint struct {
+	mu      sync.Mutex
+	enc     *json.Encoder
+	failed  bool             // a write failed, which is only reported once
+	resumed map[string]Movie // titles resolved by the run being resumed
+}
+
+// WithCheckpoint records resolved titles to w. Titles resolved by an
+// interrupted run, loaded with -resume, are not looked up again; w should
+// already hold them, as written by createCheckpoint.
+func WithCheckpoint(w io.Writer, resumed []checkpointEntry) Option {
+	return func(s *Scraper) {
+		s.checkpoint = &checkpoint{enc: json.NewEncoder(w), resumed: make(map[string]Movie, len(resumed))}
+		for _, entry := range resumed {
+			s.checkpoint.resumed[entry.Title] = entry.Movie
+		}
+	}
+}
+
+// createCheckpoint starts a run's -checkpoint file with the titles resumed
+// from the interrupted run, and opens it for the titles this run resolves.
+// The old file is replaced atomically, so a crash while rewriting it can't
+// lose the titles it held.
+func createCheckpoint(filename string, resumed []checkpointEntry, perm os.FileMode) (*os.File, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, entry := range resumed {
+		if err := enc.Encode(entry); err != nil {
+			return nil, fmt.Errorf("failed to encode checkpoint entry for '%s': %w", entry.Title, err)
+		}
+	}
+	if err := writeFileMode(filename, buf.Bytes(), perm); err != nil {
+		return nil, fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return os.OpenFile(filename, os.O_WRONLY|os.O_APPEND, perm)
+}
+
+// resumedMovie returns the movie a title resolved to before the run was
+// interrupted, if any
+func (s *Scraper) resumedMovie(title string) (*Movie, bool) {
+	if s.checkpoint == nil {
+		return nil, false
+	}
+	movie, ok := s.checkpoint.resumed[title]
+	if !ok {
+		return nil, false
+	}
+	movie = cloneMovie(movie)
+	return &movie, true
+}
+
+// recordCheckpoint records a resolved title. Nothing is recorded without
+// -checkpoint. A failed write is logged rather than failing the run, which
+// can still finish; only a resume would repeat the title's lookups.
+func (s *Scraper) recordCheckpoint(title string, movie Movie) {
+	if s.checkpoint == nil {
+		return
+	}
+	s.checkpoint.mu.Lock()
+	defer s.checkpoint.mu.Unlock()
+	if err := s.checkpoint.enc.Encode(checkpointEntry{Title: title, Movie: movie}); err != nil && !s.checkpoint.failed {
+		s.checkpoint.failed = true
+		fmt.Fprintf(s.logWriter(), "Failed to write checkpoint, so a resumed run will look up titles again: %v\n", err)
+	}
+}
+
+// loadCheckpoint reads the titles resolved by an interrupted run. A missing
+// file means there is nothing to resume. The last line may have been cut
+// off by the crash, so a malformed last line is ignored.
+func loadCheckpoint(filename string) ([]checkpointEntry, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var entries []checkpointEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	var malformed error
+	for line := 1; scanner.Scan(); line++ {
+		if malformed != nil {
+			return nil, malformed
+		}
+		var entry checkpointEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Title == "" {
+			malformed = fmt.Errorf("malformed checkpoint line %d in %s", line, filename)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// removeCheckpoint deletes the checkpoint once the run has completed, so the
+// next -resume starts afresh
+func (s *Scraper) removeCheckpoint(filename string) {
+	if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(s.logWriter(), "Failed to remove checkpoint: %v\n", err)
+	}
+}
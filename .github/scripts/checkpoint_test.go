@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	mock := newMockTMDB(t, nil, mockCatalog)
+	var searches int64
+	mock.searchHook = func(query string) { atomic.AddInt64(&searches, 1) }
+
+	// The interrupted run resolved two titles, and crashed partway through
+	// writing a third
+	var interrupted bytes.Buffer
+	scraper := mock.newTestScraper(defaultConfig())
+	WithCheckpoint(&interrupted, nil)(scraper)
+	if _, err := scraper.resolveTitles(context.Background(), []string{"Space Jam", "Ghost", "Unknown Movie"}); err != nil {
+		t.Fatalf("Failed to resolve titles: %v", err)
+	}
+	if lines := strings.Count(interrupted.String(), "\n"); lines != 2 {
+		t.Fatalf("Expected a checkpoint line per resolved title, got:\n%s", interrupted.String())
+	}
+	filename := filepath.Join(t.TempDir(), "checkpoint.jsonl")
+	if err := os.WriteFile(filename, append(interrupted.Bytes(), `{"title":"The Addams`...), 0o644); err != nil {
+		t.Fatalf("Failed to write checkpoint: %v", err)
+	}
+
+	entries, err := loadCheckpoint(filename)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("Expected the 2 complete entries, got %+v (%v)", entries, err)
+	}
+
+	// The resumed titles start the new checkpoint, which replaces the old one
+	atomic.StoreInt64(&searches, 0)
+	resumed, err := createCheckpoint(filename, entries, 0o644)
+	if err != nil {
+		t.Fatalf("Failed to create checkpoint: %v", err)
+	}
+	defer resumed.Close()
+	scraper = mock.newTestScraper(defaultConfig())
+	WithCheckpoint(resumed, entries)(scraper)
+	movies, err := scraper.resolveTitles(context.Background(), []string{"Space Jam", "Ghost", "The Addams Family"})
+	if err != nil {
+		t.Fatalf("Failed to resolve titles: %v", err)
+	}
+	if len(movies) != 3 {
+		t.Errorf("Expected the resumed titles merged with the new one, got %+v", movies)
+	}
+	if got := atomic.LoadInt64(&searches); got != 1 {
+		t.Errorf("Expected only the unresolved title to be searched, got %d searches", got)
+	}
+	if entries, err := loadCheckpoint(filename); err != nil || len(entries) != 3 {
+		t.Errorf("Expected the resumed checkpoint to hold all 3 titles, got %+v (%v)", entries, err)
+	}
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestCheckpointWriteFailureIsLogged(t *testing.T) {
+	var log bytes.Buffer
+	scraper := NewScraper("dummy_key", WithLogOutput(&log), WithCheckpoint(failingWriter{}, nil))
+	scraper.recordCheckpoint("Space Jam", Movie{Title: "Space Jam"})
+	scraper.recordCheckpoint("Ghost", Movie{Title: "Ghost"})
+
+	if got := strings.Count(log.String(), "Failed to write checkpoint"); got != 1 || !strings.Contains(log.String(), "disk full") {
+		t.Errorf("Expected the failure to be logged once, got %q", log.String())
+	}
+}
+
+func TestLoadCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	if entries, err := loadCheckpoint(filepath.Join(dir, "missing.jsonl")); err != nil || entries != nil {
+		t.Errorf("Expected nothing to resume without a checkpoint, got %+v (%v)", entries, err)
+	}
+
+	writeFixture(t, dir, "corrupt.jsonl", "{\"title\":\"Ghost\",\"movie\":{}}\nnot json\n{\"title\":\"Dune\",\"movie\":{}}\n")
+	if _, err := loadCheckpoint(filepath.Join(dir, "corrupt.jsonl")); err == nil {
+		t.Error("Expected a malformed line before the last to be rejected")
+	}
+
+	NewScraper("dummy_key").removeCheckpoint(filepath.Join(dir, "corrupt.jsonl"))
+	if _, err := os.Stat(filepath.Join(dir, "corrupt.jsonl")); !os.IsNotExist(err) {
+		t.Errorf("Expected the checkpoint to be removed, got %v", err)
+	}
+
+	if _, err := parseFlags([]string{"-resume"}); err == nil {
+		t.Error("Expected -resume without -checkpoint to be rejected")
+	}
+}
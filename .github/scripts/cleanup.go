@@ -0,0 +1,124 @@
+package main
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// cleanupStep is a named transformation applied to every scraped title
+type cleanupStep struct {
+	name  string
+	apply func(string) string
+}
+
+// defaultCleanupPipeline is applied in order to each italic entry before filtering:
+//
+//  1. decode-entities: unescape HTML entities left in the text ("&amp;" -> "&")
+//  2. normalize-unicode: map non-breaking spaces, curly quotes and zero-width
+//     characters to their plain equivalents
+//  3. strip-footnotes: remove wiki footnote markers such as "[1]" or "[citation needed]"
+//  4. strip-year: remove a trailing release year such as " (1999)"
+//  5. strip-quotes: remove quotation marks wrapped around the whole title
+//  6. collapse-whitespace: trim and collapse runs of whitespace
+var defaultCleanupPipeline = []cleanupStep{
+	{"decode-entities", decodeEntities},
+	{"normalize-unicode", normalizeUnicode},
+	{"strip-footnotes", stripFootnotes},
+	{"strip-year", stripYear},
+	{"strip-quotes", stripQuotes},
+	{"collapse-whitespace", collapseWhitespace},
+}
+
+// WithCleanupPipeline replaces the title cleanup pipeline
+func WithCleanupPipeline(steps []cleanupStep) Option {
+	return func(s *Scraper) {
+		s.cleanup = steps
+	}
+}
+
+// cleanTitle runs a scraped title through the cleanup pipeline
+func (s *Scraper) cleanTitle(title string) string {
+	for _, step := range s.cleanup {
+		title = step.apply(title)
+	}
+	return title
+}
+
+// decodeEntities unescapes HTML entities
+func decodeEntities(title string) string {
+	return html.UnescapeString(title)
+}
+
+var unicodeReplacer = strings.NewReplacer(
+	"\u00a0", " ", // no-break space
+	"\u2007", " ", // figure space
+	"\u202f", " ", // narrow no-break space
+	"\u200b", "", // zero-width space
+	"\u200c", "", // zero-width non-joiner
+	"\u200d", "", // zero-width joiner
+	"\ufeff", "", // byte order mark
+	"\u2018", "'", // left single quote
+	"\u2019", "'", // right single quote
+	"\u201c", "\"", // left double quote
+	"\u201d", "\"", // right double quote
+)
+
+// normalizeUnicode maps look-alike characters to their plain ASCII forms
+func normalizeUnicode(title string) string {
+	return unicodeReplacer.Replace(title)
+}
+
+var footnotePattern = regexp.MustCompile(`(?i)\[\s*(?:\d+|[a-z]|note \d+|citation needed)\s*\]`)
+
+// stripFootnotes removes wiki footnote markers
+func stripFootnotes(title string) string {
+	return footnotePattern.ReplaceAllString(title, "")
+}
+
+var trailingYearPattern = regexp.MustCompile(`\s*\((?:19|20)\d{2}\)\s*$`)
+
+// stripYear removes a trailing parenthesized release year
+func stripYear(title string) string {
+	return trailingYearPattern.ReplaceAllString(title, "")
+}
+
+// releaseYearHint returns the parenthesized release year at the end of a raw
+// wiki title, or 0 if there is none
+func releaseYearHint(raw string) int {
+	match := trailingYearPattern.FindString(strings.TrimSpace(stripFootnotes(raw)))
+	if match == "" {
+		return 0
+	}
+	year, _ := strconv.Atoi(strings.Trim(strings.TrimSpace(match), "()"))
+	return year
+}
+
+// quotePairs are the opening and closing quotation marks stripQuotes removes
+var quotePairs = [][2]string{{`"`, `"`}, {"'", "'"}, {"\u201c", "\u201d"}, {"\u2018", "\u2019"}}
+
+// stripQuotes removes one matched pair of quotation marks wrapped around the
+// whole title, as in "\"Jaws\"". Quotes inside a title are kept, and so are
+// outer ones when the same mark also appears inside, since then they are
+// more likely two quoted parts, as in "\"Weird Al\" Yankovic: The \"Movie\"".
+func stripQuotes(title string) string {
+	trimmed := strings.TrimSpace(title)
+	for _, pair := range quotePairs {
+		open, close := pair[0], pair[1]
+		if len(trimmed) < len(open)+len(close)+1 || !strings.HasPrefix(trimmed, open) || !strings.HasSuffix(trimmed, close) {
+			continue
+		}
+		inner := trimmed[len(open) : len(trimmed)-len(close)]
+		if strings.Contains(inner, open) || strings.Contains(inner, close) {
+			continue
+		}
+		return inner
+	}
+	return title
+}
+
+// collapseWhitespace trims a title and collapses internal runs of whitespace
+func collapseWhitespace(title string) string {
+	return strings.Join(strings.Fields(title), " ")
+}
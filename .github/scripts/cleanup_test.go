@@ -0,0 +1,55 @@
+package main
+
+import "testing"
+
+func TestCleanupSteps(t *testing.T) {
+	testCases := []struct {
+		step     func(string) string
+		input    string
+		expected string
+	}{
+		{decodeEntities, "Bill &amp; Ted&#39;s Excellent Adventure", "Bill & Ted's Excellent Adventure"},
+		{normalizeUnicode, "Ferris Bueller’s Day Off​", "Ferris Bueller's Day Off"},
+		{stripFootnotes, "Jaws[1]", "Jaws"},
+		{stripFootnotes, "Rocky [citation needed]", "Rocky "},
+		{stripFootnotes, "[REC]", "[REC]"},
+		{stripYear, "The Mummy (1999)", "The Mummy"},
+		{stripYear, "1917", "1917"},
+		{stripYear, "Blade Runner 2049", "Blade Runner 2049"},
+		{stripQuotes, `"Jaws"`, "Jaws"},
+		{stripQuotes, " \u201cJaws\u201d ", "Jaws"},
+		{stripQuotes, "\u2018Jaws\u2019", "Jaws"},
+		{stripQuotes, `"Crocodile" Dundee`, `"Crocodile" Dundee`},
+		{stripQuotes, `"Weird Al" Yankovic: The "Movie"`, `"Weird Al" Yankovic: The "Movie"`},
+		{stripQuotes, `'Salem's Lot'`, `'Salem's Lot'`},
+		{stripQuotes, `""`, `""`},
+		{collapseWhitespace, "  Space   Jam \n", "Space Jam"},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.step(tc.input); got != tc.expected {
+			t.Errorf("Cleanup of %q: expected %q, got %q", tc.input, tc.expected, got)
+		}
+	}
+}
+
+func TestCleanTitlePipeline(t *testing.T) {
+	scraper := NewScraper("dummy_key")
+
+	got := scraper.cleanTitle("  The Addams Family (1991)[2] ")
+	if got != "The Addams Family" {
+		t.Errorf("Expected %q, got %q", "The Addams Family", got)
+	}
+
+	// Curly quotes are straightened before they are stripped
+	if got := scraper.cleanTitle("\u201cJaws\u201d (1975)"); got != "Jaws" {
+		t.Errorf("Expected %q, got %q", "Jaws", got)
+	}
+
+	// A custom pipeline replaces the default steps
+	scraper = NewScraper("dummy_key", WithCleanupPipeline([]cleanupStep{{"collapse-whitespace", collapseWhitespace}}))
+	got = scraper.cleanTitle(" The Mummy (1999) ")
+	if got != "The Mummy (1999)" {
+		t.Errorf("Expected custom pipeline to keep the year, got %q", got)
+	}
+}
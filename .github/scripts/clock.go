@@ -0,0 +1,11 @@
+package main
+
+import "time"
+
+// WithClock replaces the clock used for timestamped filenames, the RSS build
+// date, the titles artifact and run summaries, so tests can pin them
+func WithClock(now func() time.Time) Option {
+	return func(s *Scraper) {
+		s.now = now
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixedClock returns a clock that always reports t
+func fixedClock(t time.Time) func() time.Time {
+	return func() time.Time { return t }
+}
+
+func TestArchiveFilenameUsesClock(t *testing.T) {
+	clock := fixedClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	scraper := NewScraper("dummy_key", WithClock(clock))
+	if got := scraper.archiveFilename(".json"); got != mainOutputBase+"_20240102_030405.json" {
+		t.Errorf("Unexpected archive filename %s", got)
+	}
+
+	cfg := defaultConfig()
+	cfg.TimestampFormat = "2006-01-02"
+	cfg.Gzip = true
+	scraper = NewScraper("dummy_key", WithConfig(cfg), WithClock(clock))
+	if got := scraper.archiveFilename(".xml"); got != mainOutputBase+"_2024-01-02.xml.gz" {
+		t.Errorf("Unexpected archive filename %s", got)
+	}
+}
+
+func TestSaveToRSSUsesClock(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "scott_hasnt_seen.xml")
+	clock := fixedClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	scraper := NewScraper("dummy_key", WithClock(clock))
+	if err := scraper.saveToRSS([]Movie{{Title: "Space Jam", IMDBID: "tt0117705"}}, filename); err != nil {
+		t.Fatalf("Failed to save RSS: %v", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read RSS: %v", err)
+	}
+	if !strings.Contains(string(data), "<lastBuildDate>Tue, 02 Jan 2024 03:04:05 +0000</lastBuildDate>") {
+		t.Errorf("Expected the pinned build date, got:\n%s", data)
+	}
+}
+
+func TestWithRandSeed(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.RateJitter = 100 * time.Millisecond
+	a := NewScraper("dummy_key", WithConfig(cfg), WithRandSeed(7))
+	b := NewScraper("dummy_key", WithConfig(cfg), WithRandSeed(7))
+
+	for i := 0; i < 10; i++ {
+		if pa, pb := a.rateLimitPause(), b.rateLimitPause(); pa != pb {
+			t.Fatalf("Pause %d: expected scrapers with the same seed to agree, got %s and %s", i, pa, pb)
+		}
+	}
+}
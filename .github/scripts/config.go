@@ -0,0 +1,638 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultTMDBBaseURL is the public TMDB API
+const defaultTMDBBaseURL = "https://api.themoviedb.org/3"
+
+// Output formats for the JSON list
+const (
+	formatNative     = "native"
+	formatRadarr     = "radarr"
+	formatLetterboxd = "letterboxd"
+	formatIMDBIDs    = "imdb-ids"
+	formatWrapped    = "wrapped"
+)
+
+// List orders accepted by -sort
+const (
+	sortTitle       = "title"
+	sortPopularity  = "popularity"
+	sortWiki        = "wiki"
+	sortEpisodeDesc = "episode-desc"
+)
+
+// Commands select which stages of the pipeline a run performs; serve runs
+// the pipeline on demand over HTTP
+const (
+	commandRun     = "run"
+	commandScrape  = "scrape"
+	commandResolve = "resolve"
+	commandServe   = "serve"
+)
+
+// Config holds the command-line options that shape a run
+type Config struct {
+	Command    string
+	TitlesFile string
+
+	Format        string
+	KeepUnmatched bool
+	Force         bool
+	StateFile     string
+
+	IncludeMatchInfo bool
+	SearchPages      int
+	ValidateIMDB     bool
+	FailuresFile     string
+	Plain            bool
+	OverridesFile    string
+	GenreStatsFile   string
+	FileMode         os.FileMode
+
+	PreferOriginalTitle bool
+	DedupeFile          string
+	LogLevel            string
+	TMDBBaseURL         string
+	MaxRequests         int
+	NoTimestamp         bool
+	StatsOnly           bool
+	Progress            bool
+	ProgressInterval    time.Duration
+	APIKeyFile          string
+	KeepTMDBOnly        bool
+	StreamTitles        bool
+	Since               time.Time
+	OutputValidation    string
+	AmbiguityThreshold  int
+	WriteIfChanged      bool
+	ImportCache         string
+	VerifyPosters       string
+	ClearBrokenPosters  bool
+	TimestampFormat     string
+	IncludeGuest        bool
+	PosterConcurrency   int
+	SelfTest            bool
+	Gzip                bool
+	MatchStrategy       string
+	RequirePoster       bool
+	RateJitter          time.Duration
+	Stdout              bool
+	Merge               bool
+	TitleVariants       bool
+	SummaryInterval     time.Duration
+	SummaryFile         string
+	GenreAliasesFile    string
+	ExcludeGenres       []string
+	NoDocs              bool
+	NoTVMovies          bool
+	FetchCertification  bool
+	MaxCertification    string
+	Only                string
+	Explain             bool
+	RadarrURL           string
+	RadarrRootFolder    string
+	RadarrProfile       int
+	SplitByGenre        string
+	FailedRequestsLog   string
+	RequireFields       []string
+	Retries             int
+	RetryBackoff        time.Duration
+	NoSort              bool
+	Offline             string
+	HostLimits          map[string]hostLimit
+	Listen              string
+	MultiSearch         bool
+	DiffComment         string
+	MergeEpisodes       bool
+	Prune               bool
+	IMDBFallback        bool
+	ListName            string
+	IncludePopularity   bool
+	MinPopularity       float64
+	SortByPopularity    bool
+	LogCandidates       int
+	NoFollowRedirects   bool
+	RunIDInFilename     bool
+	ValidateConfig      bool
+	ExtraFormats        []string // formats after the first in -format
+	BreakerThreshold    int
+	BreakerCooldown     time.Duration
+	OverridesTemplate   string
+	RampUp              time.Duration
+	Sort                string
+	IncludeAdult        bool
+	Language            string
+	PostHook            string
+	MaxQueryLength      int
+	IncludeRawTitle     bool
+	YearTieBreak        string
+	ConfigDir           string
+	WarmCache           string
+	ExcludeEpisodes     string
+	DumpCandidates      string
+	RequireYear         bool
+	ArchiveIndex        string
+	TitleListFile       string // plain-text -titles-file read instead of the wiki
+	Strict              bool
+	IncludeOverview     bool
+	MaxOverviewLength   int
+	WikiCookie          string
+	WikiCookieFile      string
+	ResolvedSet         string
+	ResolvedTTL         time.Duration
+	FetchKeywords       bool
+	Keywords            []string
+	ExcludeKeywords     []string
+	Checkpoint          string
+	Resume              bool
+	EpisodeHeaders      bool
+	AutoConfirm         bool
+}
+
+// defaultConfig returns the configuration used when no flags are given
+func defaultConfig() Config {
+	return Config{
+		Command:            commandRun,
+		TitlesFile:         "titles.json",
+		Format:             formatNative,
+		StateFile:          ".wiki_state.json",
+		SearchPages:        1,
+		RetryBackoff:       time.Second,
+		Sort:               sortTitle,
+		Language:           "en-US",
+		MaxQueryLength:     100,
+		AutoConfirm:        true,
+		ResolvedTTL:        30 * 24 * time.Hour,
+		YearTieBreak:       defaultYearTieBreak,
+		BreakerThreshold:   10,
+		BreakerCooldown:    time.Minute,
+		HostLimits:         defaultHostLimits(),
+		Listen:             "localhost:8080",
+		ListName:           "Scott Hasn't Seen",
+		RequireFields:      []string{fieldIMDBID},
+		RadarrProfile:      1,
+		ValidateIMDB:       true,
+		FileMode:           0644,
+		LogLevel:           logLevelInfo,
+		OutputValidation:   outputValidationWarn,
+		AmbiguityThreshold: 20,
+		TimestampFormat:    "20060102_150405",
+		PosterConcurrency:  5,
+		MatchStrategy:      matchStrategyFirst,
+		TMDBBaseURL:        defaultTMDBBaseURL,
+	}
+}
+
+// parseFlags parses command-line arguments into a Config. The arguments may
+// start with a command (run, scrape, resolve or serve); run is the default.
+func parseFlags(args []string) (Config, error) {
+	cfg := defaultConfig()
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cfg.Command, args = args[0], args[1:]
+	}
+
+	fs := flag.NewFlagSet("scott-hasnt-seen-radarr", flag.ContinueOnError)
+	fs.StringVar(&cfg.TitlesFile, "titles", cfg.TitlesFile, "titles artifact written by scrape and read by resolve")
+	fs.StringVar(&cfg.Format, "format", cfg.Format, "output format: native, radarr, letterboxd, imdb-ids or wrapped; several comma-separated formats each get their own file")
+	fs.BoolVar(&cfg.KeepUnmatched, "keep-unmatched", cfg.KeepUnmatched, "keep unmatched titles as placeholder entries (native format only)")
+	fs.BoolVar(&cfg.Force, "force", cfg.Force, "run even if the wiki page has not changed since the last run")
+	fs.StringVar(&cfg.StateFile, "state-file", cfg.StateFile, "file storing the wiki page ETag/Last-Modified between runs (empty disables)")
+	fs.IntVar(&cfg.SearchPages, "search-pages", cfg.SearchPages, "number of TMDB search result pages to consider per title")
+	fs.BoolVar(&cfg.ValidateIMDB, "validate-imdb", cfg.ValidateIMDB, "reject IMDB IDs that don't match ^tt\\d{7,8}$")
+	fs.StringVar(&cfg.FailuresFile, "failures", cfg.FailuresFile, "write titles that could not be resolved to this JSON file")
+	fs.BoolVar(&cfg.Plain, "plain", cfg.Plain, "use ASCII-only progress markers (OK/FAIL) for CI log viewers")
+	fs.StringVar(&cfg.OverridesFile, "overrides", cfg.OverridesFile, "JSON file mapping wiki titles to IMDB IDs, resolved via TMDB find instead of title search")
+	fs.StringVar(&cfg.GenreStatsFile, "genre-stats", cfg.GenreStatsFile, "write the per-genre movie counts to this JSON file")
+	fs.Var((*fileModeValue)(&cfg.FileMode), "file-mode", "octal permission mode for output files")
+	fs.StringVar(&cfg.Language, "language", cfg.Language, "TMDB language for searches and titles, e.g. en-US or de-DE")
+	fs.BoolVar(&cfg.PreferOriginalTitle, "prefer-original-title", cfg.PreferOriginalTitle, "use TMDB's original-language title as the movie title")
+	fs.StringVar(&cfg.DedupeFile, "dedupe-file", cfg.DedupeFile, "remove duplicate movies from an existing output file and exit")
+	fs.StringVar(&cfg.LogLevel, "log-level", cfg.LogLevel, "log verbosity: info or debug")
+	fs.StringVar(&cfg.TMDBBaseURL, "tmdb-base-url", cfg.TMDBBaseURL, "TMDB API base URL, e.g. a mirror with the same API (env TMDB_BASE_URL)")
+	fs.IntVar(&cfg.MaxRequests, "max-requests", cfg.MaxRequests, "stop making TMDB requests after this many in one run (0 for no limit)")
+	fs.BoolVar(&cfg.NoTimestamp, "no-timestamp", cfg.NoTimestamp, "skip writing timestamped copies of the output files")
+	fs.BoolVar(&cfg.StatsOnly, "stats-only", cfg.StatsOnly, "compare against the committed scott_hasnt_seen.json instead of writing, exiting non-zero on drift")
+	fs.BoolVar(&cfg.Progress, "progress", cfg.Progress, "show a single updating progress bar instead of per-title lines")
+	fs.DurationVar(&cfg.ProgressInterval, "progress-interval", cfg.ProgressInterval, "print progress at most this often, e.g. 5s (0 prints every completed title)")
+	fs.StringVar(&cfg.APIKeyFile, "api-key-file", cfg.APIKeyFile, "read the TMDB API key from this file instead of TMDB_API_KEY (env TMDB_API_KEY_FILE)")
+	fs.BoolVar(&cfg.KeepTMDBOnly, "keep-tmdb-only", cfg.KeepTMDBOnly, "keep movies that have a TMDB ID but no IMDB ID instead of reporting them as failures")
+	fs.BoolVar(&cfg.StreamTitles, "stream-titles", cfg.StreamTitles, "start TMDB lookups while the wiki page is still being parsed")
+	fs.Var((*dateValue)(&cfg.Since), "since", "only include movies from episodes aired on or after this date (YYYY-MM-DD)")
+	fs.StringVar(&cfg.OutputValidation, "output-validation", cfg.OutputValidation, "on an invalid output record: warn (drop it and report it as a failure) or fail (abort before writing)")
+	fs.IntVar(&cfg.AmbiguityThreshold, "ambiguity-threshold", cfg.AmbiguityThreshold, "flag matches whose title search returned at least this many results for review (0 disables)")
+	fs.BoolVar(&cfg.WriteIfChanged, "write-if-changed", cfg.WriteIfChanged, "skip writing output files when the list matches the existing main file (-force rewrites anyway)")
+	fs.StringVar(&cfg.ImportCache, "import-cache", cfg.ImportCache, "seed the lookup cache from a list written by a previous run, e.g. scott_hasnt_seen.json")
+	fs.StringVar(&cfg.VerifyPosters, "verify-posters", cfg.VerifyPosters, "check the poster URLs in an existing output file and exit")
+	fs.BoolVar(&cfg.ClearBrokenPosters, "clear-broken-posters", cfg.ClearBrokenPosters, "with -verify-posters, remove poster URLs that return 404 and rewrite the file")
+	fs.StringVar(&cfg.TimestampFormat, "timestamp-format", cfg.TimestampFormat, "Go time layout for the timestamp in archival filenames")
+	fs.BoolVar(&cfg.IncludeGuest, "include-guest", cfg.IncludeGuest, "include the guest who picked each movie in native output, where the wiki names one")
+	fs.BoolVar(&cfg.IncludeMatchInfo, "include-match-info", cfg.IncludeMatchInfo, "include match_confidence and match_method in native output")
+	fs.IntVar(&cfg.PosterConcurrency, "poster-concurrency", cfg.PosterConcurrency, "number of poster requests in flight at once, separate from the TMDB API limit")
+	fs.BoolVar(&cfg.SelfTest, "selftest", cfg.SelfTest, "check TMDB authentication, wiki reachability and a known title, then exit")
+	fs.BoolVar(&cfg.Gzip, "gzip", cfg.Gzip, "gzip the timestamped archive files (the main files stay uncompressed)")
+	fs.StringVar(&cfg.MatchStrategy, "match-strategy", cfg.MatchStrategy, "which search result wins: first, most-popular, highest-voted or exact-year-then-popular")
+	fs.BoolVar(&cfg.RequirePoster, "require-poster", cfg.RequirePoster, "drop resolved movies that have no poster")
+	fs.DurationVar(&cfg.RateJitter, "rate-jitter", cfg.RateJitter, "randomly vary the 250ms pause between requests by up to this much, e.g. 50ms")
+	fs.BoolVar(&cfg.Stdout, "stdout", cfg.Stdout, "write the list to standard output instead of files, sending all other output to standard error")
+	fs.BoolVar(&cfg.Merge, "merge", cfg.Merge, "keep entries marked \"manual\": true in the existing scott_hasnt_seen.json")
+	fs.BoolVar(&cfg.TitleVariants, "title-variants", cfg.TitleVariants, "when a title finds nothing, retry with roman/arabic numerals and &/and swapped, before the leading-article fallback that always runs")
+	fs.DurationVar(&cfg.SummaryInterval, "summary-interval", cfg.SummaryInterval, "print an interim run summary this often while titles resolve, e.g. 1m (0 disables)")
+	fs.StringVar(&cfg.SummaryFile, "summary-file", cfg.SummaryFile, "write the run summary to this JSON file, replaced at each -summary-interval")
+	fs.StringVar(&cfg.GenreAliasesFile, "genre-aliases", cfg.GenreAliasesFile, "JSON file mapping TMDB genre names to the labels written to the output, e.g. {\"science_fiction\": \"sci-fi\"}")
+	fs.Var((*genreListValue)(&cfg.ExcludeGenres), "exclude-genres", "comma-separated TMDB genre names to drop from the list, e.g. horror,war")
+	fs.BoolVar(&cfg.NoDocs, "no-docs", cfg.NoDocs, "drop documentaries from the list (shortcut for -exclude-genres documentary)")
+	fs.BoolVar(&cfg.NoTVMovies, "no-tv-movies", cfg.NoTVMovies, "drop TV movies from the list (shortcut for -exclude-genres tv_movie)")
+	fs.BoolVar(&cfg.FetchCertification, "fetch-certification", cfg.FetchCertification, "look up each movie's US certification (G/PG/PG-13/R/NC-17) and include it in native output; costs one extra TMDB request per movie")
+	fs.StringVar(&cfg.MaxCertification, "max-certification", cfg.MaxCertification, "drop movies rated above this US certification, or unrated, e.g. PG-13")
+	fs.StringVar(&cfg.Only, "only", cfg.Only, "resolve just this title, print the result and exit without fetching the wiki or writing the list")
+	fs.BoolVar(&cfg.Explain, "explain", cfg.Explain, "with -only, print a step-by-step trace of how the title was resolved")
+	fs.StringVar(&cfg.RadarrURL, "radarr-url", cfg.RadarrURL, "also add the list to the Radarr instance at this URL (API key from RADARR_API_KEY)")
+	fs.StringVar(&cfg.RadarrRootFolder, "radarr-root-folder", cfg.RadarrRootFolder, "Radarr root folder for added movies, required with -radarr-url")
+	fs.IntVar(&cfg.RadarrProfile, "radarr-quality-profile", cfg.RadarrProfile, "Radarr quality profile ID for added movies")
+	fs.StringVar(&cfg.SplitByGenre, "split-by-genre", cfg.SplitByGenre, "also write one list file per genre (also), or only the per-genre files (only)")
+	fs.StringVar(&cfg.FailedRequestsLog, "failed-requests-log", cfg.FailedRequestsLog, "append every failed request (method, URL with the API key redacted, status, error) to this file as JSON lines")
+	fs.Var(&fieldListValue{fields: &cfg.RequireFields}, "require-fields", "comma-separated fields a movie must have to be kept: imdb_id, tmdb_id, year, poster")
+	fs.IntVar(&cfg.Retries, "retries", cfg.Retries, "retry a title up to this many times after rate limiting, server or network errors")
+	fs.DurationVar(&cfg.RetryBackoff, "retry-backoff", cfg.RetryBackoff, "pause before the first retry, doubled for each further retry")
+	fs.BoolVar(&cfg.NoSort, "no-sort", cfg.NoSort, "keep movies in the order their titles appear on the wiki page instead of sorting by title")
+	fs.StringVar(&cfg.Offline, "offline", cfg.Offline, "read the wiki page and all TMDB responses from this fixtures directory instead of the network")
+	fs.Var((*hostLimitsValue)(&cfg.HostLimits), "host-limit", "per-host request limits as host=concurrency[/interval], e.g. comedybangbang.fandom.com=1/2s; comma-separate or repeat for several hosts")
+	fs.StringVar(&cfg.Listen, "listen", cfg.Listen, "address the serve command listens on")
+	fs.BoolVar(&cfg.MultiSearch, "multi-search", cfg.MultiSearch, "search movies and TV together, keeping movie matches and listing close TV matches for review in the -failures file")
+	fs.StringVar(&cfg.DiffComment, "diff-comment", cfg.DiffComment, "write the changes against the previous list to this Markdown file, e.g. for a pull request comment")
+	fs.BoolVar(&cfg.MergeEpisodes, "merge-episodes", cfg.MergeEpisodes, "list the air date of every episode a movie was picked on in native output, merging them when several titles resolve to the same movie")
+	fs.BoolVar(&cfg.Prune, "prune", cfg.Prune, "remove movies in the existing scott_hasnt_seen.json that this run didn't produce, listing each one, instead of keeping them")
+	fs.BoolVar(&cfg.IMDBFallback, "imdb-fallback", cfg.IMDBFallback, "when TMDB search finds nothing, ask IMDB's suggestion endpoint for an IMDB ID and confirm it with TMDB find")
+	fs.StringVar(&cfg.ListName, "list-name", cfg.ListName, "name written at the top of the wrapped format, to tell lists apart downstream (ignored by the other formats)")
+	fs.BoolVar(&cfg.IncludePopularity, "include-popularity", cfg.IncludePopularity, "include each movie's TMDB popularity score in native output")
+	fs.Float64Var(&cfg.MinPopularity, "min-popularity", cfg.MinPopularity, "drop movies whose TMDB popularity score is below this (0 keeps all)")
+	fs.BoolVar(&cfg.SortByPopularity, "sort-by-popularity", cfg.SortByPopularity, "sort the list by TMDB popularity, most popular first, instead of by title")
+	fs.IntVar(&cfg.LogCandidates, "log-candidates", cfg.LogCandidates, "with -log-level debug, log each title's chosen candidate and up to this many rejected ones with their scores (0 disables)")
+	fs.BoolVar(&cfg.NoFollowRedirects, "no-follow-redirects", cfg.NoFollowRedirects, "fail instead of following a redirect from the wiki page (redirects are always logged)")
+	fs.BoolVar(&cfg.RunIDInFilename, "run-id-in-filename", cfg.RunIDInFilename, "add the run ID after the timestamp in archive filenames")
+	fs.BoolVar(&cfg.ValidateConfig, "validate-config", cfg.ValidateConfig, "check the flags and every config file they name, report any problems and exit without running")
+	fs.IntVar(&cfg.BreakerThreshold, "breaker-threshold", cfg.BreakerThreshold, "stop requesting from a host after this many consecutive failed requests, failing the remaining titles fast (0 disables)")
+	fs.DurationVar(&cfg.BreakerCooldown, "breaker-cooldown", cfg.BreakerCooldown, "after a tripped breaker has been open this long, try one request again (0 keeps it open for the rest of the run)")
+	fs.StringVar(&cfg.OverridesTemplate, "overrides-template", cfg.OverridesTemplate, "write unresolved titles to this file as an -overrides file with empty IMDB IDs to fill in")
+	fs.DurationVar(&cfg.RampUp, "ramp-up", cfg.RampUp, "start with one title worker and add the rest evenly over this long (0 starts them all at once)")
+	fs.StringVar(&cfg.Sort, "sort", cfg.Sort, "list order: title, popularity (same as -sort-by-popularity), wiki (same as -no-sort) or episode-desc (most recently discussed first)")
+	fs.BoolVar(&cfg.IncludeAdult, "include-adult", cfg.IncludeAdult, "include titles TMDB flags as adult in searches; only for specific films TMDB has mis-flagged")
+	fs.StringVar(&cfg.PostHook, "post-hook", cfg.PostHook, "after a successful run, run this command with the main list's path as its last argument and the run summary as JSON on stdin")
+	fs.IntVar(&cfg.MaxQueryLength, "max-query-length", cfg.MaxQueryLength, "record titles longer than this many characters as suspicious_length failures instead of searching for them (0 disables)")
+	fs.BoolVar(&cfg.IncludeRawTitle, "include-raw-title", cfg.IncludeRawTitle, "include each movie's title as written on the wiki, before cleanup, in native output")
+	fs.StringVar(&cfg.YearTieBreak, "year-tiebreak", cfg.YearTieBreak, "comma-separated order for choosing between candidates from the wiki's year: votes, popularity and id (lowest TMDB ID)")
+	fs.StringVar(&cfg.ConfigDir, "config-dir", cfg.ConfigDir, "load and merge skip/*.txt title lists, overrides/*.json and aliases/*.json genre aliases from this directory")
+	fs.StringVar(&cfg.WarmCache, "warm-cache", cfg.WarmCache, "resolve every title only to fill the lookup cache, saved to this file for -import-cache, writing no list files")
+	fs.StringVar(&cfg.ExcludeEpisodes, "exclude-episode-pattern", cfg.ExcludeEpisodes, "drop movies discussed only on episodes whose titles match this regular expression, e.g. (?i)crossover")
+	fs.StringVar(&cfg.DumpCandidates, "dump-candidates", cfg.DumpCandidates, "write every TMDB search's candidates and their scores to this file as JSON lines, for tuning the matching")
+	fs.BoolVar(&cfg.RequireYear, "require-year", cfg.RequireYear, "drop movies TMDB has no release date for (shortcut for adding year to -require-fields)")
+	fs.StringVar(&cfg.ArchiveIndex, "archive-index", cfg.ArchiveIndex, "keep an index of every run's timestamped files, with run ID, time and movie count, in this JSON file")
+	fs.StringVar(&cfg.TitleListFile, "titles-file", cfg.TitleListFile, "read titles from this plain-text file, one per line, instead of scraping the wiki")
+	fs.BoolVar(&cfg.Strict, "strict", cfg.Strict, "exit non-zero without writing the list if any title fails to resolve; failures are written to -failures (default failures.json)")
+	fs.BoolVar(&cfg.IncludeOverview, "include-overview", cfg.IncludeOverview, "include each movie's TMDB synopsis in native output")
+	fs.IntVar(&cfg.MaxOverviewLength, "max-overview-length", cfg.MaxOverviewLength, "truncate -include-overview synopses to this many characters (0 keeps them whole)")
+	fs.StringVar(&cfg.WikiCookie, "cookie", cfg.WikiCookie, "send this Cookie header with wiki requests, for a page behind a login or anti-bot check")
+	fs.StringVar(&cfg.WikiCookieFile, "cookie-file", cfg.WikiCookieFile, "read the -cookie header value from this file, keeping it out of the process list")
+	fs.StringVar(&cfg.ResolvedSet, "resolved-set", cfg.ResolvedSet, "keep resolved titles in this file between runs and look up only titles it doesn't have")
+	fs.DurationVar(&cfg.ResolvedTTL, "resolved-ttl", cfg.ResolvedTTL, "look a -resolved-set title up again once its result is this old (0 keeps results forever)")
+	fs.BoolVar(&cfg.FetchKeywords, "fetch-keywords", cfg.FetchKeywords, "look up each movie's TMDB keywords and include them in native output; costs one extra TMDB request per movie")
+	fs.Var((*keywordListValue)(&cfg.Keywords), "keyword", "keep only movies with one of these comma-separated TMDB keywords, e.g. superhero; may be repeated")
+	fs.Var((*keywordListValue)(&cfg.ExcludeKeywords), "exclude-keyword", "drop movies with any of these comma-separated TMDB keywords, e.g. based on true story; may be repeated")
+	fs.StringVar(&cfg.Checkpoint, "checkpoint", cfg.Checkpoint, "record each resolved title to this file as it resolves, deleted when the run completes, so an interrupted run can be resumed")
+	fs.BoolVar(&cfg.Resume, "resume", cfg.Resume, "skip the titles already resolved in the -checkpoint file of an interrupted run")
+	fs.BoolVar(&cfg.EpisodeHeaders, "episode-headers", cfg.EpisodeHeaders, "attribute each movie to the <h3> episode header above it, for pages laid out as headers rather than a table")
+	fs.BoolVar(&cfg.AutoConfirm, "auto-confirm", cfg.AutoConfirm, "accept the only exact title match from the wiki's year with full confidence, before -match-strategy runs (-auto-confirm=false always runs the strategy)")
+
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	// Environment variables apply only when the flag wasn't given explicitly
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	if baseURL := os.Getenv("TMDB_BASE_URL"); baseURL != "" && !explicit["tmdb-base-url"] {
+		cfg.TMDBBaseURL = baseURL
+	}
+	if keyFile := os.Getenv("TMDB_API_KEY_FILE"); keyFile != "" && !explicit["api-key-file"] {
+		cfg.APIKeyFile = keyFile
+	}
+	cfg.TMDBBaseURL = strings.TrimRight(cfg.TMDBBaseURL, "/")
+	if cfg.Offline != "" {
+		// Fixtures are a fixed snapshot; don't skip them as unchanged or
+		// record their state as the live page's
+		cfg.Force = true
+		cfg.StateFile = ""
+	}
+	cfg.MaxCertification = normalizeCertification(cfg.MaxCertification)
+	switch cfg.Sort {
+	case sortWiki:
+		cfg.NoSort = true
+	case sortPopularity:
+		cfg.SortByPopularity = true
+	}
+	if cfg.Strict && cfg.FailuresFile == "" {
+		cfg.FailuresFile = strictFailuresFile
+	}
+	if cfg.RequireYear && !cfg.requiresField(fieldYear) {
+		cfg.RequireFields = append(cfg.RequireFields, fieldYear)
+	}
+	if formats := strings.Split(cfg.Format, ","); len(formats) > 1 {
+		cfg.Format = strings.TrimSpace(formats[0])
+		for _, format := range formats[1:] {
+			cfg.ExtraFormats = append(cfg.ExtraFormats, strings.TrimSpace(format))
+		}
+	}
+
+	if err := cfg.validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// validate checks that the configuration is usable
+func (c Config) validate() error {
+	switch c.Command {
+	case commandRun, commandScrape, commandResolve, commandServe:
+	default:
+		return fmt.Errorf("unknown command %q (expected %s, %s, %s or %s)", c.Command, commandRun, commandScrape, commandResolve, commandServe)
+	}
+	if (c.Command == commandScrape || c.Command == commandResolve) && c.TitlesFile == "" {
+		return fmt.Errorf("-titles is required for the %s command", c.Command)
+	}
+	seenFormats := make(map[string]bool)
+	for _, format := range append([]string{c.Format}, c.ExtraFormats...) {
+		switch format {
+		case formatNative, formatRadarr, formatLetterboxd, formatIMDBIDs, formatWrapped:
+		default:
+			return fmt.Errorf("unknown format %q (expected %s, %s, %s, %s or %s)", format, formatNative, formatRadarr, formatLetterboxd, formatIMDBIDs, formatWrapped)
+		}
+		if seenFormats[format] {
+			return fmt.Errorf("format %s is given more than once", format)
+		}
+		seenFormats[format] = true
+	}
+	switch c.LogLevel {
+	case logLevelInfo, logLevelDebug:
+	default:
+		return fmt.Errorf("unknown log level %q (expected %s or %s)", c.LogLevel, logLevelInfo, logLevelDebug)
+	}
+	if _, ok := matchStrategies[c.MatchStrategy]; !ok {
+		return fmt.Errorf("unknown match strategy %q (expected %s, %s, %s or %s)", c.MatchStrategy, matchStrategyFirst, matchStrategyMostPopular, matchStrategyHighestVoted, matchStrategyExactYearThenPopular)
+	}
+	switch c.OutputValidation {
+	case outputValidationWarn, outputValidationFail:
+	default:
+		return fmt.Errorf("unknown output validation mode %q (expected %s or %s)", c.OutputValidation, outputValidationWarn, outputValidationFail)
+	}
+	if err := validateBaseURL(c.TMDBBaseURL); err != nil {
+		return fmt.Errorf("invalid TMDB base URL: %w", err)
+	}
+	if c.MaxQueryLength < 0 {
+		return fmt.Errorf("-max-query-length must not be negative, got %d", c.MaxQueryLength)
+	}
+	if c.MaxRequests < 0 {
+		return fmt.Errorf("-max-requests must not be negative, got %d", c.MaxRequests)
+	}
+	if err := validateTimestampFormat(c.TimestampFormat); err != nil {
+		return fmt.Errorf("invalid -timestamp-format: %w", err)
+	}
+	if c.AmbiguityThreshold < 0 {
+		return fmt.Errorf("-ambiguity-threshold must not be negative, got %d", c.AmbiguityThreshold)
+	}
+	if c.RateJitter < 0 || c.RateJitter > rateLimitDelay {
+		return fmt.Errorf("-rate-jitter must be between 0 and %s, got %s", rateLimitDelay, c.RateJitter)
+	}
+	if c.RadarrURL != "" {
+		if err := validateBaseURL(c.RadarrURL); err != nil {
+			return fmt.Errorf("invalid -radarr-url: %w", err)
+		}
+		if c.RadarrRootFolder == "" {
+			return fmt.Errorf("-radarr-root-folder is required with -radarr-url")
+		}
+	}
+	switch c.SplitByGenre {
+	case "", splitByGenreAlso, splitByGenreOnly:
+	default:
+		return fmt.Errorf("unknown -split-by-genre mode %q (expected %s or %s)", c.SplitByGenre, splitByGenreAlso, splitByGenreOnly)
+	}
+	if c.Explain && c.Only == "" {
+		return fmt.Errorf("-explain requires -only")
+	}
+	if c.MaxCertification != "" {
+		if err := validateCertification(c.MaxCertification); err != nil {
+			return fmt.Errorf("invalid -max-certification: %w", err)
+		}
+	}
+	if c.MinPopularity < 0 {
+		return fmt.Errorf("-min-popularity must not be negative, got %g", c.MinPopularity)
+	}
+	if c.PostHook != "" && c.Stdout {
+		return fmt.Errorf("-post-hook needs an output file and can't be used with -stdout")
+	}
+	if c.WarmCache != "" && c.Command != commandRun && c.Command != commandResolve {
+		return fmt.Errorf("-warm-cache can only be used with the %s and %s commands", commandRun, commandResolve)
+	}
+	if c.WarmCache != "" && (c.Only != "" || c.Stdout || c.StatsOnly) {
+		return fmt.Errorf("-warm-cache writes only the cache and can't be used with -only, -stdout or -stats-only")
+	}
+	if _, err := regexp.Compile(c.ExcludeEpisodes); err != nil {
+		return fmt.Errorf("invalid -exclude-episode-pattern: %w", err)
+	}
+	if c.TitleListFile != "" && (c.Command == commandResolve || c.Command == commandServe) {
+		return fmt.Errorf("-titles-file replaces the wiki and can't be used with the %s command", c.Command)
+	}
+	if c.TitleListFile != "" && c.Command == commandRun && !c.Stdout && c.RadarrURL == "" && c.WarmCache == "" {
+		return fmt.Errorf("-titles-file doesn't write the committed list; use -stdout or -radarr-url to get its results")
+	}
+	if c.TitleListFile != "" && c.StreamTitles {
+		return fmt.Errorf("-titles-file can't be used with -stream-titles, which streams the wiki page")
+	}
+	if c.MaxOverviewLength < 0 {
+		return fmt.Errorf("-max-overview-length must not be negative")
+	}
+	if c.WikiCookie != "" && c.WikiCookieFile != "" {
+		return fmt.Errorf("-cookie can't be used with -cookie-file")
+	}
+	if c.ResolvedSet != "" && c.Command != commandRun && c.Command != commandResolve {
+		return fmt.Errorf("-resolved-set can only be used with the %s and %s commands", commandRun, commandResolve)
+	}
+	if c.ResolvedSet != "" && (c.Only != "" || c.WarmCache != "") {
+		return fmt.Errorf("-resolved-set can't be used with -only or -warm-cache")
+	}
+	if c.ResolvedTTL < 0 {
+		return fmt.Errorf("-resolved-ttl must not be negative")
+	}
+	if c.Resume && c.Checkpoint == "" {
+		return fmt.Errorf("-resume needs the -checkpoint file to resume from")
+	}
+	if c.Checkpoint != "" && c.Command != commandRun && c.Command != commandResolve {
+		return fmt.Errorf("-checkpoint can only be used with the %s and %s commands", commandRun, commandResolve)
+	}
+	if c.Checkpoint != "" && c.Only != "" {
+		return fmt.Errorf("-checkpoint can't be used with -only")
+	}
+	if c.Strict && c.Command == commandServe {
+		return fmt.Errorf("-strict can't be used with the %s command", commandServe)
+	}
+	if c.ArchiveIndex != "" && c.NoTimestamp {
+		return fmt.Errorf("-archive-index indexes timestamped files and can't be used with -no-timestamp")
+	}
+	if c.ConfigDir != "" && c.OverridesFile != "" {
+		return fmt.Errorf("-overrides can't be used with -config-dir; move the file into its overrides directory")
+	}
+	if c.ConfigDir != "" && c.GenreAliasesFile != "" {
+		return fmt.Errorf("-genre-aliases can't be used with -config-dir; move the file into its aliases directory")
+	}
+	if _, err := parseTieBreak(c.YearTieBreak); err != nil {
+		return fmt.Errorf("invalid -year-tiebreak: %w", err)
+	}
+	if !languagePattern.MatchString(c.Language) {
+		return fmt.Errorf("invalid -language %q (expected a language code such as en-US or de)", c.Language)
+	}
+	switch c.Sort {
+	case sortTitle, sortPopularity, sortWiki, sortEpisodeDesc:
+	default:
+		return fmt.Errorf("unknown -sort order %q (expected %s, %s, %s or %s)", c.Sort, sortTitle, sortPopularity, sortWiki, sortEpisodeDesc)
+	}
+	if c.Sort == sortEpisodeDesc && (c.NoSort || c.SortByPopularity) {
+		return fmt.Errorf("-sort %s can't be combined with -no-sort or -sort-by-popularity", sortEpisodeDesc)
+	}
+	if c.SortByPopularity && c.NoSort {
+		return fmt.Errorf("-sort-by-popularity and -no-sort can't be used together")
+	}
+	if c.BreakerThreshold < 0 {
+		return fmt.Errorf("-breaker-threshold must not be negative, got %d", c.BreakerThreshold)
+	}
+	if c.BreakerCooldown < 0 {
+		return fmt.Errorf("-breaker-cooldown must not be negative, got %s", c.BreakerCooldown)
+	}
+	if c.RampUp < 0 {
+		return fmt.Errorf("-ramp-up must not be negative, got %s", c.RampUp)
+	}
+	if c.LogCandidates < 0 {
+		return fmt.Errorf("-log-candidates must not be negative, got %d", c.LogCandidates)
+	}
+	if c.LogCandidates > 0 && c.LogLevel != logLevelDebug {
+		return fmt.Errorf("-log-candidates requires -log-level %s", logLevelDebug)
+	}
+	if c.Retries < 0 {
+		return fmt.Errorf("-retries must not be negative, got %d", c.Retries)
+	}
+	if c.RetryBackoff < 0 {
+		return fmt.Errorf("-retry-backoff must not be negative, got %s", c.RetryBackoff)
+	}
+	if c.SummaryInterval < 0 {
+		return fmt.Errorf("-summary-interval must not be negative, got %s", c.SummaryInterval)
+	}
+	if c.ProgressInterval < 0 {
+		return fmt.Errorf("-progress-interval must not be negative, got %s", c.ProgressInterval)
+	}
+	if c.PosterConcurrency < 1 {
+		return fmt.Errorf("-poster-concurrency must be at least 1, got %d", c.PosterConcurrency)
+	}
+	if c.SearchPages < 1 {
+		return fmt.Errorf("-search-pages must be at least 1, got %d", c.SearchPages)
+	}
+	return nil
+}
+
+// apiKey returns the TMDB API key, read from -api-key-file when one is set
+// and from the TMDB_API_KEY environment variable otherwise
+func (c Config) apiKey() (string, error) {
+	if c.APIKeyFile == "" {
+		return os.Getenv("TMDB_API_KEY"), nil
+	}
+
+	data, err := os.ReadFile(c.APIKeyFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read API key file: %w", err)
+	}
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", fmt.Errorf("API key file %s is empty", c.APIKeyFile)
+	}
+	return key, nil
+}
+
+// languagePattern matches the ISO 639-1 language codes TMDB accepts,
+// optionally with an ISO 3166-1 region, e.g. en-US
+var languagePattern = regexp.MustCompile(`^[a-z]{2}(-[A-Z]{2})?$`)
+
+// filenameSafePattern matches strings that are safe in a filename on any
+// common filesystem
+var filenameSafePattern = regexp.MustCompile(`^[A-Za-z0-9._+-]+$`)
+
+// validateTimestampFormat checks that a time layout produces a filesystem-safe
+// timestamp that actually varies with the time
+func validateTimestampFormat(layout string) error {
+	reference := time.Date(2024, 12, 31, 23, 59, 58, 0, time.UTC)
+	formatted := reference.Format(layout)
+	if !filenameSafePattern.MatchString(formatted) {
+		return fmt.Errorf("%q produces %q; only letters, digits, '.', '_', '+' and '-' are allowed", layout, formatted)
+	}
+	if formatted == reference.Add(time.Second).Format(layout) && formatted == reference.AddDate(0, 0, 1).Format(layout) {
+		return fmt.Errorf("%q contains no date or time elements", layout)
+	}
+	return nil
+}
+
+// validateBaseURL checks that a base URL is an absolute http(s) URL
+func validateBaseURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%q must use http or https", raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%q has no host", raw)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("%q must not include a query or fragment", raw)
+	}
+	return nil
+}
+
+// fileModeValue parses an octal permission mode such as 0640 from a flag
+type fileModeValue os.FileMode
+
+func (m *fileModeValue) String() string {
+	return fmt.Sprintf("%#o", os.FileMode(*m))
+}
+
+func (m *fileModeValue) Set(value string) error {
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil {
+		return fmt.Errorf("invalid octal mode %q", value)
+	}
+	if mode > 0777 {
+		return fmt.Errorf("mode %q has bits outside 0777", value)
+	}
+	*m = fileModeValue(mode)
+	return nil
+}
+
+// Option configures a Scraper
+type Option func(*Scraper)
+
+// WithConfig applies command-line configuration to a Scraper
+func WithConfig(cfg Config) Option {
+	return func(s *Scraper) {
+		s.config = cfg
+		if cfg.TMDBBaseURL != "" {
+			s.tmdbBaseURL = cfg.TMDBBaseURL
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseFlagsFileMode(t *testing.T) {
+	cfg, err := parseFlags(nil)
+	if err != nil {
+		t.Fatalf("Failed to parse default flags: %v", err)
+	}
+	if cfg.FileMode != 0644 {
+		t.Errorf("Expected default file mode 0644, got %#o", cfg.FileMode)
+	}
+
+	cfg, err = parseFlags([]string{"-file-mode", "0640"})
+	if err != nil {
+		t.Fatalf("Failed to parse -file-mode: %v", err)
+	}
+	if cfg.FileMode != 0640 {
+		t.Errorf("Expected file mode 0640, got %#o", cfg.FileMode)
+	}
+
+	for _, invalid := range []string{"rw-r--r--", "0999", "01777"} {
+		if _, err := parseFlags([]string{"-file-mode", invalid}); err == nil {
+			t.Errorf("Expected -file-mode %s to be rejected", invalid)
+		}
+	}
+}
+
+func TestWriteFileModeAppliesToExistingFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "list.json")
+	if err := os.WriteFile(filename, []byte("[]"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	if err := writeFileMode(filename, []byte("[]\n"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600, got %#o", info.Mode().Perm())
+	}
+}
+
+func TestParseFlagsTMDBBaseURL(t *testing.T) {
+	t.Setenv("TMDB_BASE_URL", "")
+
+	cfg, err := parseFlags(nil)
+	if err != nil {
+		t.Fatalf("Failed to parse default flags: %v", err)
+	}
+	if cfg.TMDBBaseURL != defaultTMDBBaseURL {
+		t.Errorf("Expected default base URL, got %s", cfg.TMDBBaseURL)
+	}
+
+	t.Setenv("TMDB_BASE_URL", "https://tmdb-mirror.example.com/3/")
+	cfg, err = parseFlags(nil)
+	if err != nil {
+		t.Fatalf("Failed to parse flags with TMDB_BASE_URL: %v", err)
+	}
+	if cfg.TMDBBaseURL != "https://tmdb-mirror.example.com/3" {
+		t.Errorf("Expected base URL from environment without trailing slash, got %s", cfg.TMDBBaseURL)
+	}
+
+	// The flag takes precedence over the environment
+	cfg, err = parseFlags([]string{"-tmdb-base-url", "http://localhost:8080/3"})
+	if err != nil {
+		t.Fatalf("Failed to parse -tmdb-base-url: %v", err)
+	}
+	if cfg.TMDBBaseURL != "http://localhost:8080/3" {
+		t.Errorf("Expected base URL from flag, got %s", cfg.TMDBBaseURL)
+	}
+	if NewScraper("dummy_key", WithConfig(cfg)).tmdbBaseURL != "http://localhost:8080/3" {
+		t.Errorf("Expected the scraper to use the configured base URL")
+	}
+
+	for _, invalid := range []string{"api.themoviedb.org/3", "ftp://mirror.example.com", "https://", "https://mirror.example.com/3?x=1"} {
+		if _, err := parseFlags([]string{"-tmdb-base-url", invalid}); err == nil {
+			t.Errorf("Expected base URL %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestConfigAPIKey(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "tmdb_api_key")
+	if err := os.WriteFile(keyFile, []byte("  file_key\n"), 0600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+	t.Setenv("TMDB_API_KEY", "env_key")
+	t.Setenv("TMDB_API_KEY_FILE", "")
+
+	cfg, err := parseFlags(nil)
+	if err != nil {
+		t.Fatalf("Failed to parse default flags: %v", err)
+	}
+	if key, err := cfg.apiKey(); err != nil || key != "env_key" {
+		t.Errorf("Expected the environment key without a key file, got %q (%v)", key, err)
+	}
+
+	// The file wins over TMDB_API_KEY, and is trimmed
+	t.Setenv("TMDB_API_KEY_FILE", keyFile)
+	cfg, err = parseFlags(nil)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if key, err := cfg.apiKey(); err != nil || key != "file_key" {
+		t.Errorf("Expected the key from TMDB_API_KEY_FILE, got %q (%v)", key, err)
+	}
+
+	// The flag wins over TMDB_API_KEY_FILE
+	emptyFile := filepath.Join(dir, "empty")
+	if err := os.WriteFile(emptyFile, []byte("\n"), 0600); err != nil {
+		t.Fatalf("Failed to write empty key file: %v", err)
+	}
+	cfg, err = parseFlags([]string{"-api-key-file", emptyFile})
+	if err != nil {
+		t.Fatalf("Failed to parse -api-key-file: %v", err)
+	}
+	if _, err := cfg.apiKey(); err == nil {
+		t.Error("Expected an empty key file to be rejected")
+	}
+
+	cfg.APIKeyFile = filepath.Join(dir, "missing")
+	if _, err := cfg.apiKey(); err == nil {
+		t.Error("Expected a missing key file to be rejected")
+	}
+}
+
+func TestValidateTimestampFormat(t *testing.T) {
+	for _, layout := range []string{"20060102_150405", "2006-01-02T150405Z0700", "2006-01-02"} {
+		if err := validateTimestampFormat(layout); err != nil {
+			t.Errorf("Expected %q to be accepted, got %v", layout, err)
+		}
+	}
+
+	// RFC 3339 contains ':', which isn't allowed in filenames on Windows
+	for _, layout := range []string{time.RFC3339, "2006/01/02", "Jan 2 2006", "latest"} {
+		if err := validateTimestampFormat(layout); err == nil {
+			t.Errorf("Expected %q to be rejected", layout)
+		}
+	}
+
+	if _, err := parseFlags([]string{"-timestamp-format", "2006-01-02T15:04:05"}); err == nil {
+		t.Error("Expected an unsafe -timestamp-format to be rejected")
+	}
+}
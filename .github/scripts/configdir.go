@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// configDir is the configuration loaded from a -config-dir directory, merged
+// from every file in its skip, overrides and aliases subdirectories
+type configDir struct {
+	skip         map[string]bool   // normalized titles never searched
+	overrides    map[string]string // as loaded by loadOverrides
+	genreAliases map[string]string // as loaded by loadGenreAliases
+}
+
+// loadConfigDir loads and merges skip/*.txt, overrides/*.json and
+// aliases/*.json from dir, in filename order. A title overridden with
+// different IMDB IDs, or a genre aliased to different labels, in two files is
+// an error. Missing subdirectories are simply empty.
+func loadConfigDir(dir string) (configDir, error) {
+	if info, err := os.Stat(dir); err != nil {
+		return configDir{}, err
+	} else if !info.IsDir() {
+		return configDir{}, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	loaded := configDir{
+		skip:         make(map[string]bool),
+		overrides:    make(map[string]string),
+		genreAliases: make(map[string]string),
+	}
+
+	skipFiles, _ := filepath.Glob(filepath.Join(dir, "skip", "*.txt"))
+	for _, file := range skipFiles {
+		titles, err := loadSkipList(file)
+		if err != nil {
+			return configDir{}, err
+		}
+		for _, title := range titles {
+			loaded.skip[title] = true
+		}
+	}
+
+	overrideFiles, _ := filepath.Glob(filepath.Join(dir, "overrides", "*.json"))
+	sources := make(map[string]string)
+	for _, file := range overrideFiles {
+		overrides, err := loadOverrides(file)
+		if err != nil {
+			return configDir{}, fmt.Errorf("%s: %w", file, err)
+		}
+		if err := mergeConfigFile("override", loaded.overrides, overrides, sources, file); err != nil {
+			return configDir{}, err
+		}
+	}
+
+	aliasFiles, _ := filepath.Glob(filepath.Join(dir, "aliases", "*.json"))
+	sources = make(map[string]string)
+	for _, file := range aliasFiles {
+		aliases, err := loadGenreAliases(file)
+		if err != nil {
+			return configDir{}, fmt.Errorf("%s: %w", file, err)
+		}
+		if err := mergeConfigFile("genre alias", loaded.genreAliases, aliases, sources, file); err != nil {
+			return configDir{}, err
+		}
+	}
+	// Each file is valid on its own, but two files may give different
+	// genres the same label
+	if err := validateGenreAliases(loaded.genreAliases); err != nil {
+		return configDir{}, err
+	}
+
+	return loaded, nil
+}
+
+// mergeConfigFile adds the entries loaded from file to merged, recording the
+// file each key came from in sources. A key another file gave a different
+// value is an error naming both files.
+func mergeConfigFile(kind string, merged, entries, sources map[string]string, file string) error {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if existing, ok := merged[key]; ok && existing != entries[key] {
+			return fmt.Errorf("conflicting %s for %q: %s in %s, %s in %s", kind, key, existing, sources[key], entries[key], file)
+		}
+		merged[key] = entries[key]
+		sources[key] = file
+	}
+	return nil
+}
+
+// loadSkipList reads a skip list of titles, one per line, returned normalized
+func loadSkipList(filename string) ([]string, error) {
+	lines, err := readTitleLines(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read skip list: %w", err)
+	}
+
+	titles := make([]string, 0, len(lines))
+	for _, line := range lines {
+		titles = append(titles, normalizeTitle(line))
+	}
+	return titles, nil
+}
+
+// readTitleLines reads a plain-text file of titles, one per line, with blank
+// lines and lines starting with # ignored
+func readTitleLines(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// WithSkipTitles sets the normalized titles dropped from the scraped list
+// before they are searched
+func WithSkipTitles(titles map[string]bool) Option {
+	return func(s *Scraper) {
+		s.skipTitles = titles
+	}
+}
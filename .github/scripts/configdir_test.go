@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFixture(t, dir, "skip/specials.txt", "# Not films\nThe Scott Hasn't Seenies\n\n")
+	writeFixture(t, dir, "skip/tv.txt", "Ghost\n")
+	writeFixture(t, dir, "skip/notes.md", "Space Jam\n")
+	writeFixture(t, dir, "overrides/01-classics.json", `{"Space Jam": "tt0117705", "Ghost Dad": ""}`)
+	writeFixture(t, dir, "overrides/02-remakes.json", `{"space jam": "tt0117705", "Dune": "tt0087182"}`)
+	writeFixture(t, dir, "aliases/genres.json", `{"science_fiction": "sci-fi"}`)
+
+	loaded, err := loadConfigDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to load config directory: %v", err)
+	}
+	if expected := map[string]bool{"the scott hasnt seenies": true, "ghost": true}; !reflect.DeepEqual(loaded.skip, expected) {
+		t.Errorf("Expected skipped titles %v, got %v", expected, loaded.skip)
+	}
+	if expected := map[string]string{"space jam": "tt0117705", "dune": "tt0087182"}; !reflect.DeepEqual(loaded.overrides, expected) {
+		t.Errorf("Expected overrides %v, got %v", expected, loaded.overrides)
+	}
+	if expected := map[string]string{"science_fiction": "sci-fi"}; !reflect.DeepEqual(loaded.genreAliases, expected) {
+		t.Errorf("Expected genre aliases %v, got %v", expected, loaded.genreAliases)
+	}
+
+	// Skipped titles are dropped before they are searched
+	mock := newMockTMDB(t, []string{"Space Jam", "Ghost"}, mockCatalog)
+	scraper := mock.newTestScraper(defaultConfig())
+	WithSkipTitles(loaded.skip)(scraper)
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if len(movies) != 1 || movies[0].Title != "Space Jam" {
+		t.Errorf("Expected only Space Jam, got %+v", movies)
+	}
+	if scraper.dropCounts[dropRuleSkipList] != 1 {
+		t.Errorf("Expected one skip-list drop, got %v", scraper.dropCounts)
+	}
+
+	// The same title overridden differently in two files is a conflict
+	writeFixture(t, dir, "overrides/03-mistake.json", `{"Dune": "tt1160419"}`)
+	_, err = loadConfigDir(dir)
+	if err == nil || !strings.Contains(err.Error(), `conflicting override for "dune": tt0087182 in`) || !strings.Contains(err.Error(), "03-mistake.json") {
+		t.Errorf("Expected a conflicting override error, got %v", err)
+	}
+
+	// So are two files giving different genres the same label
+	conflicting := t.TempDir()
+	writeFixture(t, conflicting, "aliases/a.json", `{"thriller": "suspense"}`)
+	writeFixture(t, conflicting, "aliases/b.json", `{"mystery": "suspense"}`)
+	if _, err := loadConfigDir(conflicting); err == nil {
+		t.Error("Expected aliases from two files sharing a label to be rejected")
+	}
+
+	if _, err := parseFlags([]string{"-config-dir", dir, "-overrides", "overrides.json"}); err == nil {
+		t.Error("Expected -config-dir with -overrides to be rejected")
+	}
+}
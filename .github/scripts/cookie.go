@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// wikiCookie returns the Cookie header sent with wiki requests, read from
+// -cookie-file when one is set and from -cookie otherwise. It is empty
+// unless the page needs a session captured from a logged-in browser.
+func (c Config) wikiCookie() (string, error) {
+	if c.WikiCookieFile == "" {
+		return c.WikiCookie, nil
+	}
+
+	data, err := os.ReadFile(c.WikiCookieFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read cookie file: %w", err)
+	}
+	cookie := strings.TrimSpace(string(data))
+	if cookie == "" {
+		return "", fmt.Errorf("cookie file %s is empty", c.WikiCookieFile)
+	}
+	return cookie, nil
+}
+
+// sendsWikiCookie reports whether a wiki request to target gets the -cookie
+// header: only when it goes to the wiki's own scheme and host, so a page
+// linked from the wiki never receives the session
+func (s *Scraper) sendsWikiCookie(target *url.URL) bool {
+	if s.wikiCookie == "" {
+		return false
+	}
+	wiki, err := url.Parse(s.wikiURL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(target.Scheme, wiki.Scheme) && strings.EqualFold(target.Host, wiki.Host)
+}
+
+// WithWikiCookie sets the Cookie header sent with wiki requests
+func WithWikiCookie(cookie string) Option {
+	return func(s *Scraper) {
+		s.wikiCookie = cookie
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWikiCookie(t *testing.T) {
+	var mu sync.Mutex
+	cookies := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		cookies[r.URL.Path] = r.Header.Get("Cookie")
+		mu.Unlock()
+		if strings.HasPrefix(r.URL.Path, "/wiki") {
+			w.Write([]byte("<html><body></body></html>"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	scraper := NewScraper("dummy_key", WithWikiCookie("session=abc123"))
+	scraper.wikiURL = server.URL + "/wiki"
+	scraper.tmdbBaseURL = server.URL
+	if _, err := scraper.scrapeWikiPage(); err != nil {
+		t.Fatalf("Failed to scrape wiki page: %v", err)
+	}
+	scraper.searchMovie("Ghost")
+
+	if cookies["/wiki"] != "session=abc123" {
+		t.Errorf("Expected the cookie on the wiki request, got %q", cookies["/wiki"])
+	}
+	if cookies["/search/movie"] != "" {
+		t.Errorf("Expected no cookie on TMDB requests, got %q", cookies["/search/movie"])
+	}
+
+	// A wiki request that leaves the wiki's host doesn't get the cookie
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		cookies["other"] = r.Header.Get("Cookie")
+		mu.Unlock()
+		w.Write([]byte("<html><body></body></html>"))
+	}))
+	defer other.Close()
+	if _, err := scraper.fetchWikiDocument(other.URL + "/wiki?page=2"); err != nil {
+		t.Fatalf("Failed to fetch page: %v", err)
+	}
+	if cookies["other"] != "" {
+		t.Errorf("Expected no cookie on a request to another host, got %q", cookies["other"])
+	}
+}
+
+func TestWikiCookieFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFixture(t, dir, "cookie.txt", "  session=abc123\n")
+	writeFixture(t, dir, "empty.txt", "\n")
+	cfg, err := parseFlags([]string{"-cookie-file", filepath.Join(dir, "cookie.txt")})
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if cookie, err := cfg.wikiCookie(); err != nil || cookie != "session=abc123" {
+		t.Errorf("Expected the trimmed cookie from the file, got %q (%v)", cookie, err)
+	}
+
+	cfg.WikiCookieFile = filepath.Join(dir, "empty.txt")
+	if _, err := cfg.wikiCookie(); err == nil {
+		t.Error("Expected an empty cookie file to be rejected")
+	}
+	cfg.WikiCookieFile = filepath.Join(dir, "missing.txt")
+	if problems := validateConfigInputs(cfg); len(problems) != 1 {
+		t.Errorf("Expected -validate-config to report the missing cookie file, got %v", problems)
+	}
+
+	if _, err := parseFlags([]string{"-cookie", "a=b", "-cookie-file", "cookie.txt"}); err == nil {
+		t.Error("Expected -cookie and -cookie-file together to be rejected")
+	}
+}
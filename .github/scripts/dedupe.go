@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// movieKey identifies a movie for deduplication: its IMDB ID, then its TMDB ID,
+// falling back to the normalized title for entries without either
+func movieKey(movie Movie) string {
+	switch {
+	case movie.IMDBID != "":
+		return "imdb:" + movie.IMDBID
+	case movie.TMDBID != 0:
+		return "tmdb:" + strconv.Itoa(movie.TMDBID)
+	default:
+		return "title:" + normalizeTitle(movie.Title)
+	}
+}
+
+// dedupeMovies removes movies that share an ID with an earlier entry,
+// returning the remaining movies and the number removed
+func dedupeMovies(movies []Movie) ([]Movie, int) {
+	kept := make(map[string]int, len(movies))
+	deduped := make([]Movie, 0, len(movies))
+
+	for _, movie := range movies {
+		key := movieKey(movie)
+		if i, ok := kept[key]; ok {
+			deduped[i].lastAired = latestTime(deduped[i].lastAired, movie.lastAired)
+			continue
+		}
+		kept[key] = len(deduped)
+		deduped = append(deduped, movie)
+	}
+
+	return deduped, len(movies) - len(deduped)
+}
+
+// loadMovies reads a previously written JSON movie list, which may be a
+// gzip-compressed archive
+func loadMovies(filename string) ([]Movie, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	if strings.HasSuffix(filename, gzipExtension) {
+		if data, err = gunzipBytes(data); err != nil {
+			return nil, fmt.Errorf("failed to decompress %s: %w", filename, err)
+		}
+	}
+	return parseMovies(data, filename)
+}
+
+// parseMovies decodes a JSON movie list in the native or wrapped format.
+// source names the list in errors.
+func parseMovies(data []byte, source string) ([]Movie, error) {
+	// Lists in the wrapped format hold the movies in an object
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var wrapped wrappedList
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", source, err)
+		}
+		return wrapped.Movies, nil
+	}
+
+	var movies []Movie
+	if err := json.Unmarshal(data, &movies); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", source, err)
+	}
+
+	return movies, nil
+}
+
+// dedupeFile removes duplicates from an existing output file and rewrites it
+// in sorted order, without scraping or calling TMDB
+func (s *Scraper) dedupeFile(filename string) error {
+	movies, err := loadMovies(filename)
+	if err != nil {
+		return err
+	}
+
+	movies, removed := dedupeMovies(movies)
+	sortMovies(movies)
+
+	if err := s.saveToFile(movies, filename); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(s.logWriter(), "Removed %d duplicates from %s\n", removed, filename)
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDedupeMovies(t *testing.T) {
+	movies := []Movie{
+		{Title: "Ghost", IMDBID: "tt0099653", TMDBID: 251},
+		{Title: "Ghost (1990)", IMDBID: "tt0099653", TMDBID: 251},
+		{Title: "Dune", TMDBID: 841},
+		{Title: "Dune", TMDBID: 841},
+		newPlaceholder("Unknown Film"),
+		newPlaceholder("Unknown Film"),
+		{Title: "Dune", IMDBID: "tt1160419", TMDBID: 438631},
+	}
+
+	deduped, removed := dedupeMovies(movies)
+
+	if removed != 3 {
+		t.Errorf("Expected 3 duplicates removed, got %d", removed)
+	}
+	if len(deduped) != 4 {
+		t.Fatalf("Expected 4 movies left, got %d", len(deduped))
+	}
+	if deduped[0].Title != "Ghost" {
+		t.Errorf("Expected the first occurrence to be kept, got %s", deduped[0].Title)
+	}
+}
+
+func TestDedupeFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "scott_hasnt_seen.json")
+	content := `[{"title":"Space Jam","imdb_id":"tt0117705","poster_url":"","year":1996},` +
+		`{"title":"Dune","imdb_id":"tt0087182","poster_url":"","year":1984},` +
+		`{"title":"Space Jam","imdb_id":"tt0117705","poster_url":"","year":1996}]`
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if err := NewScraper("").dedupeFile(filename); err != nil {
+		t.Fatalf("Failed to dedupe file: %v", err)
+	}
+
+	movies, err := loadMovies(filename)
+	if err != nil {
+		t.Fatalf("Failed to reload file: %v", err)
+	}
+	if len(movies) != 2 || movies[0].Title != "Dune" || movies[1].Title != "Space Jam" {
+		t.Errorf("Expected deduplicated, sorted movies, got %+v", movies)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// MovieDiff lists the movies added and removed between two lists, and the
+// movies in both whose metadata changed
+type MovieDiff struct {
+	Added   []Movie `json:"added"`
+	Removed []Movie `json:"removed"`
+	Changed []Movie `json:"changed"`
+}
+
+// HasChanges reports whether the lists differ
+func (d MovieDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Changed) > 0
+}
+
+// movieHash returns a stable hash of the fields that describe a movie, so a
+// changed poster or title shows up even when the movie set is the same. Match
+// info and the manual flag aren't metadata and are left out, as are fields
+// such as the certification and guest that are only output with a flag, so
+// turning the flag on or off doesn't change every movie.
+func movieHash(movie Movie) string {
+	data, _ := json.Marshal(struct {
+		Title         string   `json:"title"`
+		OriginalTitle string   `json:"original_title"`
+		IMDBID        string   `json:"imdb_id"`
+		TMDBID        int      `json:"tmdb_id"`
+		PosterURL     string   `json:"poster_url"`
+		Year          int      `json:"year"`
+		Genres        []string `json:"genres"`
+	}{
+		movie.Title, movie.OriginalTitle, movie.IMDBID, movie.TMDBID, movie.PosterURL,
+		movie.Year, movie.Genres,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// diffMovies compares two lists by movie ID, ignoring placeholders. A movie in
+// both lists is changed when its hash differs from the one stored in the
+// previous list; entries written before hashes were stored are never reported
+// as changed.
+func diffMovies(previous, current []Movie) MovieDiff {
+	previousKeys := make(map[string]bool)
+	previousHashes := make(map[string]string)
+	for _, movie := range matchedOnly(previous) {
+		previousKeys[movieKey(movie)] = true
+		previousHashes[movieKey(movie)] = movie.Hash
+	}
+	currentKeys := make(map[string]bool)
+	for _, movie := range matchedOnly(current) {
+		currentKeys[movieKey(movie)] = true
+	}
+
+	var diff MovieDiff
+	for _, movie := range matchedOnly(current) {
+		key := movieKey(movie)
+		if !previousKeys[key] {
+			diff.Added = append(diff.Added, movie)
+			continue
+		}
+		if hash := previousHashes[key]; hash != "" && hash != movieHash(movie) {
+			diff.Changed = append(diff.Changed, movie)
+		}
+	}
+	for _, movie := range matchedOnly(previous) {
+		if !currentKeys[movieKey(movie)] {
+			diff.Removed = append(diff.Removed, movie)
+		}
+	}
+
+	sortMovies(diff.Added)
+	sortMovies(diff.Removed)
+	sortMovies(diff.Changed)
+	return diff
+}
+
+// printDiff prints the added, removed and changed titles against a previous
+// list
+func printDiff(w io.Writer, diff MovieDiff, against string) {
+	fmt.Fprintf(w, "\nChanges against %s:\n", against)
+	fmt.Fprintf(w, "  Added: %d\n", len(diff.Added))
+	for _, movie := range diff.Added {
+		fmt.Fprintf(w, "    + %s\n", describeMovie(movie))
+	}
+	fmt.Fprintf(w, "  Removed: %d\n", len(diff.Removed))
+	for _, movie := range diff.Removed {
+		fmt.Fprintf(w, "    - %s\n", describeMovie(movie))
+	}
+	fmt.Fprintf(w, "  Changed: %d\n", len(diff.Changed))
+	for _, movie := range diff.Changed {
+		fmt.Fprintf(w, "    ~ %s\n", describeMovie(movie))
+	}
+}
+
+// diffMarkdown formats a diff as a Markdown comment for a pull request
+func diffMarkdown(diff MovieDiff, against string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### Scott Hasn't Seen list changes\n\nCompared against `%s`.\n", against)
+	if !diff.HasChanges() {
+		b.WriteString("\nNo changes.\n")
+		return b.String()
+	}
+
+	sections := []struct {
+		heading string
+		movies  []Movie
+	}{
+		{"Added", diff.Added},
+		{"Removed", diff.Removed},
+		{"Changed", diff.Changed},
+	}
+	for _, section := range sections {
+		if len(section.movies) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n**%s (%d)**\n\n", section.heading, len(section.movies))
+		for _, movie := range section.movies {
+			fmt.Fprintf(&b, "- %s\n", describeMovie(movie))
+		}
+	}
+	return b.String()
+}
+
+// describeMovie formats a movie as "Title (Year) [IMDB ID]" for reports
+func describeMovie(movie Movie) string {
+	description := movie.Title
+	if movie.Year > 0 {
+		description = fmt.Sprintf("%s (%d)", description, movie.Year)
+	}
+	if movie.IMDBID != "" {
+		description = fmt.Sprintf("%s [%s]", description, movie.IMDBID)
+	}
+	return description
+}
+
+// checkDrift compares freshly resolved movies against a previously written
+// list file and prints the differences to w
+func checkDrift(w io.Writer, movies []Movie, filename string) (MovieDiff, error) {
+	previous, err := loadMovies(filename)
+	if err != nil {
+		return MovieDiff{}, err
+	}
+
+	diff := diffMovies(previous, movies)
+	printDiff(w, diff, filename)
+	return diff, nil
+}
+
+// diffAgainstFile compares movies against a previously written list file
+// without printing. A missing file counts as an empty list.
+func diffAgainstFile(movies []Movie, filename string) (MovieDiff, error) {
+	if _, err := os.Stat(filename); errors.Is(err, os.ErrNotExist) {
+		return diffMovies(nil, movies), nil
+	}
+
+	previous, err := loadMovies(filename)
+	if err != nil {
+		return MovieDiff{}, err
+	}
+	return diffMovies(previous, movies), nil
+}
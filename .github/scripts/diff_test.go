@@ -0,0 +1,120 @@
+package main
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiffMovies(t *testing.T) {
+	previous := []Movie{
+		{Title: "Space Jam", IMDBID: "tt0117705", Year: 1996},
+		{Title: "Dune", IMDBID: "tt0087182", Year: 1984},
+		{Title: "Ghost", IMDBID: "tt0099653", Year: 1990},
+	}
+	current := []Movie{
+		{Title: "Ghost", IMDBID: "tt0099653", Year: 1990},
+		{Title: "Dune", IMDBID: "tt0087182", Year: 1984},
+		{Title: "Sister Act", IMDBID: "tt0105417", Year: 1992},
+		newPlaceholder("Unknown Movie"),
+	}
+
+	diff := diffMovies(previous, current)
+	if !diff.HasChanges() {
+		t.Fatal("Expected changes to be reported")
+	}
+	if len(diff.Added) != 1 || diff.Added[0].Title != "Sister Act" {
+		t.Errorf("Expected Sister Act to be added, got %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Title != "Space Jam" {
+		t.Errorf("Expected Space Jam to be removed, got %+v", diff.Removed)
+	}
+
+	if diff := diffMovies(current, previous[1:]); !diff.HasChanges() {
+		t.Errorf("Expected Sister Act to be reported as removed")
+	}
+	if diff := diffMovies(previous, previous); diff.HasChanges() {
+		t.Errorf("Expected no changes for identical lists, got %+v", diff)
+	}
+}
+
+func TestCheckDrift(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "scott_hasnt_seen.json")
+	committed := []Movie{{Title: "Dune", IMDBID: "tt0087182", Year: 1984}}
+	if err := NewScraper("dummy_key").saveToFile(committed, filename); err != nil {
+		t.Fatalf("Failed to write committed list: %v", err)
+	}
+
+	diff, err := checkDrift(io.Discard, committed, filename)
+	if err != nil {
+		t.Fatalf("checkDrift failed: %v", err)
+	}
+	if diff.HasChanges() {
+		t.Errorf("Expected no drift, got %+v", diff)
+	}
+
+	diff, err = checkDrift(io.Discard, append(committed, Movie{Title: "Ghost", IMDBID: "tt0099653"}), filename)
+	if err != nil {
+		t.Fatalf("checkDrift failed: %v", err)
+	}
+	if len(diff.Added) != 1 || len(diff.Removed) != 0 {
+		t.Errorf("Expected one added movie, got %+v", diff)
+	}
+
+	if _, err := checkDrift(io.Discard, committed, filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected an error for a missing committed list")
+	}
+}
+
+func TestDiffMoviesChanged(t *testing.T) {
+	scraper := NewScraper("dummy_key")
+	previous := scraper.prepareForOutput([]Movie{
+		{Title: "Space Jam", IMDBID: "tt0117705", Year: 1996, PosterURL: "https://example.com/old.jpg"},
+		{Title: "Ghost", IMDBID: "tt0099653", Year: 1990},
+	})
+	current := []Movie{
+		{Title: "Space Jam", IMDBID: "tt0117705", Year: 1996, PosterURL: "https://example.com/new.jpg"},
+		{Title: "Ghost", IMDBID: "tt0099653", Year: 1990, MatchMethod: matchMethodExact},
+	}
+
+	diff := diffMovies(previous, scraper.prepareForOutput(current))
+	if len(diff.Added) != 0 || len(diff.Removed) != 0 {
+		t.Errorf("Expected the same movie set, got %+v", diff)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].PosterURL != "https://example.com/new.jpg" {
+		t.Errorf("Expected Space Jam's new poster to be reported as changed, got %+v", diff.Changed)
+	}
+	if !diff.HasChanges() {
+		t.Error("Expected an in-place update to count as a change")
+	}
+
+	// Lists written before hashes were stored can't be compared
+	for i := range previous {
+		previous[i].Hash = ""
+	}
+	if diff := diffMovies(previous, scraper.prepareForOutput(current)); diff.HasChanges() {
+		t.Errorf("Expected no changes against entries without hashes, got %+v", diff)
+	}
+}
+
+func TestMovieHashStable(t *testing.T) {
+	movie := Movie{Title: "Space Jam", IMDBID: "tt0117705", Year: 1996}
+	if hash := movieHash(movie); hash != "5c35e94a74784bab" {
+		t.Errorf("Expected the hash to stay stable across releases, got %s", hash)
+	}
+
+	movie.Manual = true
+	movie.MatchConfidence = 0.9
+	if movieHash(movie) != "5c35e94a74784bab" {
+		t.Error("Expected the manual flag and match info to be left out of the hash")
+	}
+	movie.Certification = "PG"
+	movie.Guest = "Paul F. Tompkins"
+	if movieHash(movie) != "5c35e94a74784bab" {
+		t.Error("Expected the flag-dependent certification and guest to be left out of the hash")
+	}
+	movie.Genres = []string{"comedy"}
+	if movieHash(movie) == "5c35e94a74784bab" {
+		t.Error("Expected genres to change the hash")
+	}
+}
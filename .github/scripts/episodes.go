@@ -0,0 +1,374 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// airDateLayout is the format air dates are written in by -since and the
+// titles artifact
+const airDateLayout = "2006-01-02"
+
+// airDateHeaderPattern matches the header of a table column holding episode
+// air dates. Release date columns describe the movie, not the episode.
+var airDateHeaderPattern = regexp.MustCompile(`(?i)^(original )?(air ?date|aired|date)$`)
+
+// guestHeaderPattern matches the header of a table column naming the guest
+// who picked the movie
+var guestHeaderPattern = regexp.MustCompile(`(?i)^(guests?|picked by|chosen by|host)$`)
+
+// episodeHeaderPattern matches the header of a table column holding the
+// episode's title
+var episodeHeaderPattern = regexp.MustCompile(`(?i)^(episode|episode (title|name)|ep\.? title)$`)
+
+// airDateLayouts are the date formats found in wiki air date columns
+var airDateLayouts = []string{
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+	"2006-01-02",
+	"1/2/2006",
+}
+
+// parseAirDate parses a wiki air date cell
+func parseAirDate(text string) (time.Time, bool) {
+	text = strings.Join(strings.Fields(stripFootnotes(text)), " ")
+	for _, layout := range airDateLayouts {
+		if date, err := time.Parse(layout, text); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// rowCell returns the text of the cell in the table row containing sel whose
+// column header matches headerPattern
+func rowCell(sel *goquery.Selection, headerPattern *regexp.Regexp) (string, bool) {
+	row := sel.Closest("tr")
+	if row.Length() == 0 {
+		return "", false
+	}
+
+	column := -1
+	row.Closest("table").Find("tr").First().Children().EachWithBreak(func(i int, header *goquery.Selection) bool {
+		if headerPattern.MatchString(strings.TrimSpace(header.Text())) {
+			column = i
+			return false
+		}
+		return true
+	})
+	if column < 0 {
+		return "", false
+	}
+
+	cell := row.Children().Eq(column)
+	if cell.Length() == 0 {
+		return "", false
+	}
+	return cell.Text(), true
+}
+
+// rowAirDate finds the air date of the episode whose table row contains sel,
+// using the column whose header names an air date
+func rowAirDate(sel *goquery.Selection) (time.Time, bool) {
+	text, ok := rowCell(sel, airDateHeaderPattern)
+	if !ok {
+		return time.Time{}, false
+	}
+	return parseAirDate(text)
+}
+
+// rowGuest finds the guest who picked the movie in the table row containing
+// sel, using the column whose header names the guest
+func rowGuest(sel *goquery.Selection) (string, bool) {
+	text, ok := rowCell(sel, guestHeaderPattern)
+	if !ok {
+		return "", false
+	}
+	guest := strings.Join(strings.Fields(stripFootnotes(text)), " ")
+	return guest, guest != ""
+}
+
+// recordGuest remembers the guest of the first episode a title was discussed on
+func (s *Scraper) recordGuest(title, guest string) {
+	s.metaMu.Lock()
+	defer s.metaMu.Unlock()
+	if _, ok := s.guests[title]; !ok {
+		s.guests[title] = guest
+	}
+}
+
+// rowEpisode finds the title of the episode whose table row contains sel,
+// using the column whose header names the episode
+func rowEpisode(sel *goquery.Selection) (string, bool) {
+	text, ok := rowCell(sel, episodeHeaderPattern)
+	if !ok {
+		return "", false
+	}
+	episode := strings.Join(strings.Fields(stripFootnotes(text)), " ")
+	return episode, episode != ""
+}
+
+// headerEpisodes walks the page in order with -episode-headers, attributing
+// each italicized title to the <h3> episode header above it. Titles before
+// the first header, or after an <h2> section header and before the next <h3>,
+// have no episode and are left out. It returns nil without -episode-headers.
+func (s *Scraper) headerEpisodes(doc *goquery.Document) map[*html.Node]string {
+	if !s.config.EpisodeHeaders {
+		return nil
+	}
+
+	episodes := make(map[*html.Node]string)
+	current := ""
+	doc.Find("h2, h3, i").Each(func(i int, sel *goquery.Selection) {
+		switch goquery.NodeName(sel) {
+		case "h2":
+			// A new section, such as a season or "See also", ends the
+			// last episode
+			current = ""
+			return
+		case "h3":
+			// Fandom wraps the header text in a headline span next to its
+			// edit link
+			header := sel.Find(".mw-headline")
+			if header.Length() == 0 {
+				header = sel
+			}
+			current = strings.Join(strings.Fields(stripFootnotes(header.Text())), " ")
+			return
+		}
+		if current != "" {
+			episodes[sel.Get(0)] = current
+		}
+	})
+	return episodes
+}
+
+// titleEpisode finds the episode a title was discussed on: the <h3> header
+// above it with -episode-headers, or its table row's episode column otherwise
+func (s *Scraper) titleEpisode(sel *goquery.Selection, headerEpisodes map[*html.Node]string) (string, bool) {
+	if s.config.EpisodeHeaders {
+		episode, ok := headerEpisodes[sel.Get(0)]
+		return episode, ok
+	}
+	return rowEpisode(sel)
+}
+
+// recordEpisodeTitle remembers every episode a title was discussed on, for
+// -exclude-episode-pattern
+func (s *Scraper) recordEpisodeTitle(title, episode string) {
+	s.metaMu.Lock()
+	defer s.metaMu.Unlock()
+	for _, known := range s.episodeTitles[title] {
+		if known == episode {
+			return
+		}
+	}
+	s.episodeTitles[title] = append(s.episodeTitles[title], episode)
+}
+
+// excludeEpisodePattern returns the compiled -exclude-episode-pattern, or nil
+// if it isn't set. The pattern is checked by validate.
+func (c Config) excludeEpisodePattern() *regexp.Regexp {
+	if c.ExcludeEpisodes == "" {
+		return nil
+	}
+	return regexp.MustCompile(c.ExcludeEpisodes)
+}
+
+// withoutEpisodes returns the movies not discussed only on episodes whose
+// titles match pattern, along with the number dropped. A movie also
+// discussed on another episode is kept, as are movies without episode titles.
+func withoutEpisodes(movies []Movie, pattern *regexp.Regexp) ([]Movie, int) {
+	kept := make([]Movie, 0, len(movies))
+	for _, movie := range movies {
+		if !onlyOnEpisodes(movie.episodeTitles, pattern) {
+			kept = append(kept, movie)
+		}
+	}
+	return kept, len(movies) - len(kept)
+}
+
+// onlyOnEpisodes reports whether there are episodes and all match pattern
+func onlyOnEpisodes(episodes []string, pattern *regexp.Regexp) bool {
+	for _, episode := range episodes {
+		if !pattern.MatchString(episode) {
+			return false
+		}
+	}
+	return len(episodes) > 0
+}
+
+// recordRawTitle remembers the wiki text of a title's first entry, before
+// cleanup, for -include-raw-title
+func (s *Scraper) recordRawTitle(title, raw string) {
+	s.metaMu.Lock()
+	defer s.metaMu.Unlock()
+	if _, ok := s.rawTitles[title]; !ok {
+		s.rawTitles[title] = raw
+	}
+}
+
+// recordAirDate remembers the earliest air date a title was discussed on,
+// and every air date for -merge-episodes
+func (s *Scraper) recordAirDate(title string, date time.Time) {
+	s.metaMu.Lock()
+	defer s.metaMu.Unlock()
+	if previous, ok := s.airDates[title]; !ok || date.Before(previous) {
+		s.airDates[title] = date
+	}
+	s.episodeDates[title] = mergeEpisodeDates(s.episodeDates[title], []string{date.Format(airDateLayout)})
+}
+
+// mergeEpisodeDates combines two lists of YYYY-MM-DD air dates, sorted and
+// without repeats
+func mergeEpisodeDates(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var merged []string
+	for _, date := range append(append([]string(nil), a...), b...) {
+		if !seen[date] {
+			seen[date] = true
+			merged = append(merged, date)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+// dedupeMergingEpisodes removes movies that share an ID with an earlier
+// entry like dedupeMovies, but adds the removed entries' episodes to the
+// one that is kept
+func dedupeMergingEpisodes(movies []Movie) ([]Movie, int) {
+	kept := make(map[string]int, len(movies))
+	deduped := make([]Movie, 0, len(movies))
+
+	for _, movie := range movies {
+		key := movieKey(movie)
+		if i, ok := kept[key]; ok {
+			deduped[i].Episodes = mergeEpisodeDates(deduped[i].Episodes, movie.Episodes)
+			deduped[i].lastAired = latestTime(deduped[i].lastAired, movie.lastAired)
+			continue
+		}
+		kept[key] = len(deduped)
+		deduped = append(deduped, movie)
+	}
+
+	return deduped, len(movies) - len(deduped)
+}
+
+// applyWikiMetadata copies what the wiki said about a title onto its movie:
+// the guest, raw title and episodes. titles are every spelling of the title
+// the wiki used, first one first; their episodes are merged, and the first
+// guest and raw title found are kept. A placeholder gets only the raw title
+// and episode titles. The slices are built afresh, as the maps belong to the
+// scraper.
+func (s *Scraper) applyWikiMetadata(movie *Movie, titles ...string) {
+	s.metaMu.Lock()
+	defer s.metaMu.Unlock()
+
+	var guest string
+	var dates []string
+	movie.RawTitle, movie.episodeTitles = "", nil
+	for _, title := range titles {
+		if movie.RawTitle == "" {
+			movie.RawTitle = s.rawTitles[title]
+		}
+		if guest == "" {
+			guest = s.guests[title]
+		}
+		movie.episodeTitles = append(movie.episodeTitles, s.episodeTitles[title]...)
+		dates = mergeEpisodeDates(dates, s.episodeDates[title])
+	}
+	if movie.IsPlaceholder() {
+		return
+	}
+
+	movie.Guest = guest
+	movie.lastAired = lastAired(dates)
+	if s.config.MergeEpisodes {
+		movie.Episodes = dates
+	}
+}
+
+// lastAired returns the newest of a title's sorted air dates, or the zero
+// time if the wiki gave none
+func lastAired(dates []string) time.Time {
+	if len(dates) == 0 {
+		return time.Time{}
+	}
+	date, _ := time.Parse(airDateLayout, dates[len(dates)-1])
+	return date
+}
+
+// latestTime returns the later of two times
+func latestTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// latestEpisode returns when a movie was last discussed, from the episodes
+// it was picked on in this run or, for a movie kept from a previous list,
+// its -merge-episodes dates. It is the zero time if neither is known.
+func latestEpisode(movie Movie) time.Time {
+	latest := movie.lastAired
+	for _, episode := range movie.Episodes {
+		if date, err := time.Parse(airDateLayout, episode); err == nil {
+			latest = latestTime(latest, date)
+		}
+	}
+	return latest
+}
+
+// sortByEpisodeDesc orders movies most recently discussed first, breaking
+// ties in the usual title order. Movies without a known episode go last.
+func sortByEpisodeDesc(movies []Movie) {
+	sortWith(movies, compareByEpisodeDesc)
+}
+
+// compareByEpisodeDesc is the -sort episode-desc order
+func compareByEpisodeDesc(a, b Movie) int {
+	if c := latestEpisode(b).Compare(latestEpisode(a)); c != 0 {
+		return c
+	}
+	return compareMovies(a, b)
+}
+
+// airedSince reports whether a title passes the -since filter. Titles without
+// a known air date are excluded while the filter is active.
+func (s *Scraper) airedSince(title string) bool {
+	if s.config.Since.IsZero() {
+		return true
+	}
+	s.metaMu.Lock()
+	date, ok := s.airDates[title]
+	s.metaMu.Unlock()
+	return ok && !date.Before(s.config.Since)
+}
+
+// dateValue parses a YYYY-MM-DD date from a flag
+type dateValue time.Time
+
+func (d *dateValue) String() string {
+	if time.Time(*d).IsZero() {
+		return ""
+	}
+	return time.Time(*d).Format(airDateLayout)
+}
+
+func (d *dateValue) Set(value string) error {
+	date, err := time.Parse(airDateLayout, value)
+	if err != nil {
+		return fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", value)
+	}
+	*d = dateValue(date)
+	return nil
+}
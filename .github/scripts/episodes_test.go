@@ -0,0 +1,337 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// episodeTable is a wiki episode table with air date and guest columns
+const episodeTable = `<html><body><table>
+	<tr><th>#</th><th>Film</th><th>Guest</th><th>Air date</th></tr>
+	<tr><td>1</td><td><i>Space Jam</i></td><td>Paul F. Tompkins</td><td>March 4, 2023</td></tr>
+	<tr><td>2</td><td><i>The Addams Family</i></td><td> Lauren  Lapkus[2] </td><td>January 8, 2024[1]</td></tr>
+	<tr><td>3</td><td><i>Ghost</i></td><td></td><td>TBA</td></tr>
+	<tr><td>4</td><td><i>Space Jam</i></td><td>Jason Mantzoukas</td><td>February 1, 2024</td></tr>
+</table></body></html>`
+
+func TestParseAirDate(t *testing.T) {
+	testCases := map[string]string{
+		"January 8, 2024":    "2024-01-08",
+		"Jan 8, 2024":        "2024-01-08",
+		"8 January 2024":     "2024-01-08",
+		" 2024-01-08 [2] ":   "2024-01-08",
+		"January  8,\n 2024": "2024-01-08",
+		"1/8/2024":           "2024-01-08",
+	}
+	for text, expected := range testCases {
+		date, ok := parseAirDate(text)
+		if !ok || date.Format(airDateLayout) != expected {
+			t.Errorf("parseAirDate(%q) = %v, %v; expected %s", text, date, ok, expected)
+		}
+	}
+
+	for _, text := range []string{"", "TBA", "2024"} {
+		if _, ok := parseAirDate(text); ok {
+			t.Errorf("Expected %q not to parse", text)
+		}
+	}
+}
+
+func TestExtractAirDates(t *testing.T) {
+	scraper := NewScraper("dummy_key")
+	if _, err := scraper.extractMovieTitles(episodeTable); err != nil {
+		t.Fatalf("Failed to extract movie titles: %v", err)
+	}
+
+	expected := map[string]string{
+		"Space Jam":         "2023-03-04", // earliest of its two episodes
+		"The Addams Family": "2024-01-08",
+	}
+	if len(scraper.airDates) != len(expected) {
+		t.Errorf("Expected %d air dates, got %v", len(expected), scraper.airDates)
+	}
+	for title, date := range expected {
+		if got := scraper.airDates[title].Format(airDateLayout); got != date {
+			t.Errorf("%s: expected air date %s, got %s", title, date, got)
+		}
+	}
+}
+
+func TestSinceFilter(t *testing.T) {
+	mock := newMockTMDB(t, nil, mockCatalog)
+	mock.wikiPage = episodeTable
+
+	cfg := defaultConfig()
+	cfg.Since = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	movies, err := mock.newTestScraper(cfg).generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+
+	// Space Jam first aired before the date and Ghost has no known air date
+	if len(movies) != 1 || movies[0].Title != "The Addams Family" {
+		t.Errorf("Expected only The Addams Family, got %+v", movies)
+	}
+
+	// The air dates survive a scrape/resolve round trip
+	filename := filepath.Join(t.TempDir(), "titles.json")
+	if err := mock.newTestScraper(defaultConfig()).scrapeToFile(filename); err != nil {
+		t.Fatalf("Failed to scrape titles: %v", err)
+	}
+	movies, err = mock.newTestScraper(cfg).resolveTitlesFile(context.Background(), filename)
+	if err != nil {
+		t.Fatalf("Failed to resolve titles: %v", err)
+	}
+	if len(movies) != 1 || movies[0].Title != "The Addams Family" {
+		t.Errorf("Expected only The Addams Family after resolve, got %+v", movies)
+	}
+}
+
+func TestParseFlagsSince(t *testing.T) {
+	cfg, err := parseFlags([]string{"-since", "2024-01-01"})
+	if err != nil {
+		t.Fatalf("Failed to parse -since: %v", err)
+	}
+	if !cfg.Since.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected -since date %v", cfg.Since)
+	}
+
+	if _, err := parseFlags([]string{"-since", "01/01/2024"}); err == nil {
+		t.Error("Expected a non-ISO date to be rejected")
+	}
+}
+
+func TestIncludeGuest(t *testing.T) {
+	mock := newMockTMDB(t, nil, mockCatalog)
+	mock.wikiPage = episodeTable
+
+	cfg := defaultConfig()
+	cfg.IncludeGuest = true
+	scraper := mock.newTestScraper(cfg)
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+
+	// Ghost has no guest listed, and Space Jam keeps the guest of its first episode
+	expected := map[string]string{
+		"Ghost":             "",
+		"Space Jam":         "Paul F. Tompkins",
+		"The Addams Family": "Lauren Lapkus",
+	}
+	if len(movies) != len(expected) {
+		t.Fatalf("Expected %d movies, got %+v", len(expected), movies)
+	}
+	for _, movie := range scraper.prepareForOutput(movies) {
+		if movie.Guest != expected[movie.Title] {
+			t.Errorf("%s: expected guest %q, got %q", movie.Title, expected[movie.Title], movie.Guest)
+		}
+	}
+
+	// The guest is left out of the output unless requested
+	for _, movie := range NewScraper("dummy_key").prepareForOutput(movies) {
+		if movie.Guest != "" {
+			t.Errorf("%s: expected no guest without -include-guest, got %q", movie.Title, movie.Guest)
+		}
+	}
+}
+
+func TestExcludeEpisodePattern(t *testing.T) {
+	mock := newMockTMDB(t, nil, mockCatalog)
+	mock.wikiPage = `<html><body><table>
+	<tr><th>#</th><th>Episode</th><th>Film</th></tr>
+	<tr><td>1</td><td>Space Jam</td><td><i>Space Jam</i></td></tr>
+	<tr><td>2</td><td>Crossover Special[1]</td><td><i>Ghost</i></td></tr>
+	<tr><td>3</td><td>CROSSOVER Special 2</td><td><i>Space Jam</i></td></tr>
+	<tr><td>4</td><td>The Addams Family</td><td><i>The Addams Family</i></td></tr>
+</table></body></html>`
+
+	cfg, err := parseFlags([]string{"-exclude-episode-pattern", "(?i)crossover"})
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	scraper := mock.newTestScraper(cfg)
+	var movies []Movie
+	output := captureStdout(t, func() {
+		movies, err = scraper.generateRadarrList(context.Background())
+	})
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+
+	// Ghost was only on a crossover; Space Jam was also on a regular episode
+	var titles []string
+	for _, movie := range movies {
+		titles = append(titles, movie.Title)
+	}
+	if expected := []string{"Space Jam", "The Addams Family"}; !reflect.DeepEqual(titles, expected) {
+		t.Errorf("Expected %v, got %v", expected, titles)
+	}
+	if !strings.Contains(output, "Excluded by episode ((?i)crossover): 1") {
+		t.Errorf("Expected the excluded count in the summary, got:\n%s", output)
+	}
+
+	if _, err := parseFlags([]string{"-exclude-episode-pattern", "crossover("}); err == nil {
+		t.Error("Expected an invalid pattern to be rejected")
+	}
+}
+
+func TestEpisodeHeaders(t *testing.T) {
+	page := `<html><body>
+<p>Coming up: <i>Dune</i></p>
+<h3><span class="mw-headline" id="ep1">Episode 1: Crossover Special</span><span class="mw-editsection">edit</span></h3>
+<p>Scott watches <i>Ghost</i> and mentions <i>Space Jam</i>.</p>
+<h3><span class="mw-headline" id="ep2">Episode 2: Slam Dunk[1]</span></h3>
+<ul><li><i>Space Jam</i></li></ul>
+<h2><span class="mw-headline" id="see-also">See also</span></h2>
+<p>Listeners also suggested <i>The Addams Family</i>.</p>
+</body></html>`
+
+	cfg := defaultConfig()
+	cfg.EpisodeHeaders = true
+	scraper := NewScraper("dummy_key", WithConfig(cfg))
+	if _, err := scraper.extractMovieTitles(page); err != nil {
+		t.Fatalf("Failed to extract titles: %v", err)
+	}
+
+	// The section header ends the last episode, so The Addams Family has none
+	expected := map[string][]string{
+		"Ghost":     {"Episode 1: Crossover Special"},
+		"Space Jam": {"Episode 1: Crossover Special", "Episode 2: Slam Dunk"},
+	}
+	if !reflect.DeepEqual(scraper.episodeTitles, expected) {
+		t.Errorf("Expected episodes %v, got %v", expected, scraper.episodeTitles)
+	}
+
+	// Without the flag there is no table to read episodes from
+	scraper = NewScraper("dummy_key")
+	if _, err := scraper.extractMovieTitles(page); err != nil {
+		t.Fatalf("Failed to extract titles: %v", err)
+	}
+	if len(scraper.episodeTitles) != 0 {
+		t.Errorf("Expected no episodes without -episode-headers, got %v", scraper.episodeTitles)
+	}
+}
+
+func TestIncludeRawTitle(t *testing.T) {
+	mock := newMockTMDB(t, nil, mockCatalog)
+	mock.wikiPage = `<html><body><ul>
+	<li><i> Space Jam (1996)[1] </i></li>
+	<li><i>Ghost</i></li>
+	<li><i>Space Jam</i></li>
+	<li><i>Ghost Dad[2]</i></li>
+</ul></body></html>`
+
+	cfg := defaultConfig()
+	cfg.IncludeRawTitle = true
+	cfg.KeepUnmatched = true
+	scraper := mock.newTestScraper(cfg)
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+
+	// Space Jam keeps the text of its first entry, and unmatched placeholders
+	// carry theirs too
+	expected := map[string]string{
+		"Ghost":     "Ghost",
+		"Ghost Dad": "Ghost Dad[2]",
+		"Space Jam": " Space Jam (1996)[1] ",
+	}
+	if len(movies) != len(expected) {
+		t.Fatalf("Expected %d movies, got %+v", len(expected), movies)
+	}
+	for _, movie := range scraper.prepareForOutput(movies) {
+		if movie.RawTitle != expected[movie.Title] {
+			t.Errorf("%s: expected raw title %q, got %q", movie.Title, expected[movie.Title], movie.RawTitle)
+		}
+	}
+
+	// The raw title is left out of the output unless requested
+	for _, movie := range NewScraper("dummy_key").prepareForOutput(movies) {
+		if movie.RawTitle != "" {
+			t.Errorf("%s: expected no raw title without -include-raw-title, got %q", movie.Title, movie.RawTitle)
+		}
+	}
+}
+
+func TestMergeEpisodes(t *testing.T) {
+	mock := newMockTMDB(t, nil, mockCatalog)
+	mock.wikiPage = `<html><body><table>
+	<tr><th>#</th><th>Film</th><th>Air date</th></tr>
+	<tr><td>1</td><td><i>Space Jam</i></td><td>February 1, 2024</td></tr>
+	<tr><td>2</td><td><i>Ghost</i></td><td>March 4, 2023</td></tr>
+	<tr><td>3</td><td><i>Space Jam: The Movie</i></td><td>March 4, 2023</td></tr>
+	<tr><td>4</td><td><i>Space Jam</i></td><td>May 5, 2024</td></tr>
+</table></body></html>`
+	mock.movies["Space Jam: The Movie"] = mockCatalog[0]
+
+	cfg := defaultConfig()
+	cfg.MergeEpisodes = true
+	movies, err := mock.newTestScraper(cfg).generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+
+	expected := map[string][]string{
+		"Ghost":     {"2023-03-04"},
+		"Space Jam": {"2023-03-04", "2024-02-01", "2024-05-05"},
+	}
+	if len(movies) != len(expected) {
+		t.Fatalf("Expected one entry per film, got %+v", movies)
+	}
+	for _, movie := range movies {
+		if !reflect.DeepEqual(movie.Episodes, expected[movie.Title]) {
+			t.Errorf("%s: expected episodes %v, got %v", movie.Title, expected[movie.Title], movie.Episodes)
+		}
+	}
+
+	// Without the flag duplicates are dropped along with their episodes
+	movies, err = mock.newTestScraper(defaultConfig()).generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	for _, movie := range movies {
+		if movie.Episodes != nil {
+			t.Errorf("Expected no episodes without -merge-episodes, got %+v", movie)
+		}
+	}
+}
+
+func TestSortEpisodeDesc(t *testing.T) {
+	mock := newMockTMDB(t, nil, mockCatalog)
+	mock.wikiPage = episodeTable
+
+	cfg, err := parseFlags([]string{"-sort", "episode-desc"})
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	movies, err := mock.newTestScraper(cfg).generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+
+	// Space Jam was last picked in February 2024, after The Addams Family;
+	// Ghost has no known air date and goes last
+	var titles []string
+	for _, movie := range movies {
+		titles = append(titles, movie.Title)
+	}
+	if expected := []string{"Space Jam", "The Addams Family", "Ghost"}; !reflect.DeepEqual(titles, expected) {
+		t.Errorf("Expected %q, got %q", expected, titles)
+	}
+
+	// -sort also covers the older sort flags, which can't be combined with it
+	if cfg, err := parseFlags([]string{"-sort", "wiki"}); err != nil || !cfg.NoSort {
+		t.Errorf("Expected -sort wiki to mean -no-sort, got %v (%v)", cfg.NoSort, err)
+	}
+	for _, args := range [][]string{{"-sort", "episode-desc", "-no-sort"}, {"-sort", "newest"}} {
+		if _, err := parseFlags(args); err == nil {
+			t.Errorf("Expected %q to be rejected", args)
+		}
+	}
+}
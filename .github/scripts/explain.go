@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// explainf prints one step of the -explain resolution trace
+func (s *Scraper) explainf(format string, args ...interface{}) {
+	if !s.config.Explain {
+		return
+	}
+	fmt.Fprintf(s.logWriter(), "[explain] "+format+"\n", args...)
+}
+
+// resolveOnly resolves a single title given with -only, without fetching the
+// wiki page, and writes the movie to w in the configured format. The title is
+// cleaned the same way as a scraped one, and a year in parentheses is used as
+// the year hint, so "Dune (1984)" resolves like the wiki entry would.
+func (s *Scraper) resolveOnly(raw string, w io.Writer) error {
+	title := s.explainCleanup(raw)
+	if year := releaseYearHint(raw); year > 0 {
+		s.recordYearHint(title, year)
+		s.explainf("Year hint from the title: %d", year)
+	}
+	if rule, detail := dropReason(title); rule != "" {
+		s.explainf("A scraped title like this would be dropped by the %s rule %s", rule, detail)
+	}
+
+	movie, err := s.searchMovie(title)
+	if err != nil {
+		s.explainf("Failed: %v", err)
+		return err
+	}
+	if err := s.validateMovie(movie); err != nil {
+		s.explainf("Rejected: %v", err)
+		return err
+	}
+	if s.config.wantsCertification() {
+		certification, err := s.getCertification(movie.TMDBID)
+		if err != nil {
+			return fmt.Errorf("failed to get certification: %w", err)
+		}
+		s.explainf("US certification: %q", certification)
+		movie.Certification = certification
+	}
+	if s.config.wantsKeywords() {
+		keywords, err := s.getKeywords(movie.TMDBID)
+		if err != nil {
+			return fmt.Errorf("failed to get keywords: %w", err)
+		}
+		s.explainf("Keywords: %q", keywords)
+		movie.Keywords = keywords
+	}
+
+	s.explainf("Result: %s via %s (confidence %.2f)", describeMovie(*movie), movie.MatchMethod, movie.MatchConfidence)
+	return s.writeList(w, []Movie{*movie})
+}
+
+// explainCleanup runs a title through the cleanup pipeline, tracing each step
+// that changes it
+func (s *Scraper) explainCleanup(raw string) string {
+	s.explainf("Raw title: %q", raw)
+	title := raw
+	for _, step := range s.cleanup {
+		cleaned := step.apply(title)
+		if cleaned != title {
+			s.explainf("  %s: %q -> %q", step.name, title, cleaned)
+		}
+		title = cleaned
+	}
+	s.explainf("Cleaned query: %q", title)
+	return title
+}
+
+// explainCandidates traces the search results considered for a title
+func (s *Scraper) explainCandidates(title string, candidates []TMDBMovie, totalResults int) {
+	if !s.config.Explain {
+		return
+	}
+	s.explainf("Search %q: %d candidates (%d total results)", title, len(candidates), totalResults)
+	for i, candidate := range candidates {
+		s.explainf("  %2d. %s (%s) tmdb=%d popularity=%.1f votes=%d similarity=%.2f", i+1,
+			candidate.Title, candidateYear(candidate), candidate.ID, candidate.Popularity, candidate.VoteCount,
+			titleSimilarity(title, candidate.Title))
+	}
+}
+
+// candidateYear formats a candidate's release year, or "?" if it has none
+func candidateYear(candidate TMDBMovie) string {
+	if candidate.ReleaseDate.IsZero() {
+		return "?"
+	}
+	return strconv.Itoa(candidate.ReleaseDate.Year())
+}
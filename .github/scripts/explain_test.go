@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// captureStdout returns what fn prints to standard output
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- string(data)
+	}()
+
+	fn()
+	w.Close()
+	return <-output
+}
+
+func TestResolveOnlyExplain(t *testing.T) {
+	mock := newMockTMDB(t, nil, []mockMovie{
+		{ID: 841, Title: "Dune", ReleaseDate: "1984-12-14", IMDBID: "tt0087182"},
+	})
+
+	cfg := defaultConfig()
+	cfg.Only = "Dune (1984)[1]"
+	cfg.Explain = true
+	scraper := mock.newTestScraper(cfg)
+
+	var list bytes.Buffer
+	trace := captureStdout(t, func() {
+		if err := scraper.resolveOnly(cfg.Only, &list); err != nil {
+			t.Errorf("Failed to resolve: %v", err)
+		}
+	})
+
+	if !strings.HasPrefix(list.String(), `[{"title":"Dune","imdb_id":"tt0087182","tmdb_id":841,`) {
+		t.Errorf("Unexpected list output %s", list.String())
+	}
+	for _, want := range []string{
+		`strip-footnotes: "Dune (1984)[1]" -> "Dune (1984)"`,
+		`strip-year: "Dune (1984)" -> "Dune"`,
+		`Cleaned query: "Dune"`,
+		"Year hint from the title: 1984",
+		`Search "Dune": 1 candidates (1 total results)`,
+		"1. Dune (1984) tmdb=841 popularity=0.0 votes=0 similarity=1.00",
+		"Auto-confirmed exact title and year: Dune (1984) tmdb=841",
+		`External IDs for TMDB 841: IMDB "tt0087182"`,
+		"Result: Dune (1984) [tt0087182] via exact (confidence 1.00)",
+	} {
+		if !strings.Contains(trace, want) {
+			t.Errorf("Expected the trace to contain %q, got:\n%s", want, trace)
+		}
+	}
+
+	// Explaining doesn't cost extra requests: one search and one external IDs lookup
+	if requests := atomic.LoadInt64(&scraper.tmdbRequests); requests != 2 {
+		t.Errorf("Expected 2 TMDB requests, got %d", requests)
+	}
+}
+
+func TestResolveOnlyNotFound(t *testing.T) {
+	mock := newMockTMDB(t, nil, mockCatalog)
+	cfg := defaultConfig()
+	cfg.Only = "A Film TMDB Has Never Heard Of"
+
+	var list bytes.Buffer
+	if err := mock.newTestScraper(cfg).resolveOnly(cfg.Only, &list); err == nil {
+		t.Errorf("Expected an error for an unknown title")
+	}
+	if list.Len() != 0 {
+		t.Errorf("Expected nothing written, got %s", list.String())
+	}
+}
+
+func TestParseFlagsExplainRequiresOnly(t *testing.T) {
+	if _, err := parseFlags([]string{"-explain"}); err == nil {
+		t.Errorf("Expected -explain without -only to be rejected")
+	}
+	if _, err := parseFlags([]string{"-only", "Dune", "-explain"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
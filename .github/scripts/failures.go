@@ -0,0 +1,363 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Failure categories recorded in the failures report
+const (
+	failureNoResults     = "no_results"
+	failureNoIMDBID      = "no_imdb_id"
+	failureNoTMDBID      = "no_tmdb_id"
+	failureNoYear        = "no_year"
+	failureNoPoster      = "no_poster"
+	failureInvalidIMDBID = "invalid_imdb_id"
+	failureHTTPError     = "http_error"
+	failureQuotaExceeded = "quota_exceeded"
+	failureInvalidRecord = "invalid_record"
+	failureEmptyTitle    = "empty_title"
+	failureCircuitOpen   = "circuit_open"
+	failureSuspiciousLen = "suspicious_length"
+	failureMetadata      = "metadata_error"
+)
+
+// Output validation modes accepted by -output-validation
+const (
+	outputValidationWarn = "warn"
+	outputValidationFail = "fail"
+)
+
+// errNoResults is wrapped when a TMDB search returns no candidates
+var errNoResults = errors.New("no results found")
+
+// errQuotaExceeded is returned instead of making a TMDB request once -max-requests is reached
+var errQuotaExceeded = &categorizedError{
+	Category: failureQuotaExceeded,
+	Err:      errors.New("TMDB request quota for this run reached"),
+}
+
+// errEmptyTitle is returned instead of searching for a title that cleans to nothing
+var errEmptyTitle = &categorizedError{
+	Category: failureEmptyTitle,
+	Err:      errors.New("title is empty after cleanup"),
+}
+
+// errSuspiciousLength is wrapped instead of searching for a title longer than
+// -max-query-length, which is usually an extraction bug grabbing a sentence
+var errSuspiciousLength = &categorizedError{
+	Category: failureSuspiciousLen,
+	Err:      errors.New("title is suspiciously long"),
+}
+
+// errCircuitOpen is returned instead of making a request to a host whose
+// circuit breaker is open
+var errCircuitOpen = &categorizedError{
+	Category: failureCircuitOpen,
+	Err:      errors.New("circuit breaker open after repeated request failures"),
+}
+
+// metadataError wraps a failed certification or keyword lookup. It fails
+// the title, since the list filters can't judge a movie without them; an
+// error that already has a category, such as an exhausted quota, keeps it.
+func metadataError(what string, err error) error {
+	err = fmt.Errorf("could not get %s: %w", what, err)
+	var categorized *categorizedError
+	if errors.As(err, &categorized) {
+		return err
+	}
+	return &categorizedError{Category: failureMetadata, Err: err}
+}
+
+// imdbIDPattern matches well-formed IMDB title IDs
+var imdbIDPattern = regexp.MustCompile(`^tt\d{7,8}$`)
+
+// Failure records a title that could not be added to the list
+type Failure struct {
+	Title    string `json:"title"`
+	Category string `json:"category"`
+	Error    string `json:"error,omitempty"`
+	Attempts int    `json:"attempts,omitempty"` // lookups made, including -retries
+}
+
+// AmbiguousMatch records a title that was matched, but whose search returned
+// so many results that the match deserves a second look
+type AmbiguousMatch struct {
+	Title           string  `json:"title"`
+	IMDBID          string  `json:"imdb_id,omitempty"`
+	TMDBID          int     `json:"tmdb_id,omitempty"`
+	SearchResults   int     `json:"search_results"`
+	MatchConfidence float64 `json:"match_confidence"`
+	MatchMethod     string  `json:"match_method,omitempty"`
+}
+
+// FailureReport is the structure written to the failures file
+type FailureReport struct {
+	Failures  []Failure        `json:"failures"`
+	Ambiguous []AmbiguousMatch `json:"ambiguous,omitempty"`
+	TVMatches []TVMatch        `json:"tv_matches,omitempty"`
+}
+
+// categorizedError attaches a failure category to an error
+type categorizedError struct {
+	Category string
+	Err      error
+}
+
+func (e *categorizedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *categorizedError) Unwrap() error {
+	return e.Err
+}
+
+// tmdbStatusError is returned when TMDB responds with a non-OK status
+type tmdbStatusError struct {
+	StatusCode int
+	Target     string
+}
+
+func (e *tmdbStatusError) Error() string {
+	return fmt.Sprintf("TMDB API returned status %d for %s", e.StatusCode, e.Target)
+}
+
+// failureCategory classifies a resolution error for the failures report
+func failureCategory(err error) string {
+	var categorized *categorizedError
+	if errors.As(err, &categorized) {
+		return categorized.Category
+	}
+	if errors.Is(err, errNoResults) {
+		return failureNoResults
+	}
+	return failureHTTPError
+}
+
+// newFailure creates a failure record for a title
+func newFailure(title string, err error) Failure {
+	return Failure{
+		Title:    title,
+		Category: failureCategory(err),
+		Error:    err.Error(),
+	}
+}
+
+// countFailures returns how many failures fall into a category
+func countFailures(failures []Failure, category string) int {
+	count := 0
+	for _, failure := range failures {
+		if failure.Category == category {
+			count++
+		}
+	}
+	return count
+}
+
+// failureExamples is how many example titles each failure group lists
+const failureExamples = 3
+
+// FailureGroup is the failures in one category, with a few example titles
+type FailureGroup struct {
+	Category string   `json:"category"`
+	Count    int      `json:"count"`
+	Examples []string `json:"examples"`
+}
+
+// groupFailures groups failures by category, largest group first, keeping the
+// first few titles of each as examples
+func groupFailures(failures []Failure) []FailureGroup {
+	var groups []FailureGroup
+	index := make(map[string]int)
+	for _, failure := range failures {
+		i, ok := index[failure.Category]
+		if !ok {
+			i = len(groups)
+			index[failure.Category] = i
+			groups = append(groups, FailureGroup{Category: failure.Category})
+		}
+		groups[i].Count++
+		if len(groups[i].Examples) < failureExamples {
+			groups[i].Examples = append(groups[i].Examples, failure.Title)
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Category < groups[j].Category
+	})
+	return groups
+}
+
+// printFailureGroups prints the failures by category, so a glance tells
+// matching problems apart from network ones
+func printFailureGroups(w io.Writer, groups []FailureGroup) {
+	for _, group := range groups {
+		examples := strings.Join(group.Examples, ", ")
+		if group.Count > len(group.Examples) {
+			examples += ", ..."
+		}
+		fmt.Fprintf(w, "    %-18s %d (%s)\n", group.Category, group.Count, examples)
+	}
+}
+
+// validateMovie checks that a resolved movie has the fields required by
+// -require-fields, which by default is the IMDB ID Radarr imports by
+func (s *Scraper) validateMovie(movie *Movie) error {
+	if err := s.checkRequiredFields(movie); err != nil {
+		return err
+	}
+	if movie.IMDBID != "" && s.config.ValidateIMDB && !imdbIDPattern.MatchString(movie.IMDBID) {
+		return &categorizedError{Category: failureInvalidIMDBID, Err: fmt.Errorf("invalid IMDB ID %q", movie.IMDBID)}
+	}
+	return nil
+}
+
+// validateRecord checks the invariants every entry in the written list must
+// satisfy. Unmatched placeholders only need a title.
+func (s *Scraper) validateRecord(movie Movie) error {
+	if strings.TrimSpace(movie.Title) == "" {
+		return errors.New("empty title")
+	}
+	if movie.IsPlaceholder() {
+		return nil
+	}
+	if movie.IMDBID == "" && movie.TMDBID == 0 {
+		return errors.New("no IMDB or TMDB ID")
+	}
+	if movie.IMDBID != "" && s.config.ValidateIMDB && !imdbIDPattern.MatchString(movie.IMDBID) {
+		return fmt.Errorf("invalid IMDB ID %q", movie.IMDBID)
+	}
+	if movie.TMDBID < 0 {
+		return fmt.Errorf("invalid TMDB ID %d", movie.TMDBID)
+	}
+	for _, genre := range movie.Genres {
+		if genre == "" {
+			return errors.New("empty genre")
+		}
+	}
+	return nil
+}
+
+// checkOutput validates the list before it is written. In warn mode invalid
+// records are dropped and recorded as failures; in fail mode the first
+// invalid record is returned as an error.
+func (s *Scraper) checkOutput(movies []Movie) ([]Movie, error) {
+	valid := make([]Movie, 0, len(movies))
+	for _, movie := range movies {
+		err := s.validateRecord(movie)
+		if err == nil {
+			valid = append(valid, movie)
+			continue
+		}
+		if s.config.OutputValidation == outputValidationFail {
+			return nil, fmt.Errorf("invalid record %q: %w", movie.Title, err)
+		}
+		log.Printf("Warning: dropping invalid record %q: %v", movie.Title, err)
+		s.failures = append(s.failures, newFailure(movie.Title, &categorizedError{Category: failureInvalidRecord, Err: err}))
+	}
+	return valid, nil
+}
+
+// ambiguousMatches returns the matched movies whose title search returned at
+// least -ambiguity-threshold results, most ambiguous first
+func (s *Scraper) ambiguousMatches(movies []Movie) []AmbiguousMatch {
+	if s.config.AmbiguityThreshold <= 0 {
+		return nil
+	}
+
+	var ambiguous []AmbiguousMatch
+	for _, movie := range movies {
+		if movie.SearchResults < s.config.AmbiguityThreshold {
+			continue
+		}
+		ambiguous = append(ambiguous, AmbiguousMatch{
+			Title:           movie.Title,
+			IMDBID:          movie.IMDBID,
+			TMDBID:          movie.TMDBID,
+			SearchResults:   movie.SearchResults,
+			MatchConfidence: movie.MatchConfidence,
+			MatchMethod:     movie.MatchMethod,
+		})
+	}
+
+	sort.SliceStable(ambiguous, func(i, j int) bool {
+		return ambiguous[i].SearchResults > ambiguous[j].SearchResults
+	})
+	return ambiguous
+}
+
+// strictFailuresFile is where -strict writes the failures report when
+// -failures isn't set, so a blocked run always says what blocked it
+const strictFailuresFile = "failures.json"
+
+// checkStrict returns an error under -strict if any title failed to resolve
+func (s *Scraper) checkStrict() error {
+	if !s.config.Strict || len(s.failures) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d titles failed to resolve, first %q (%s)", len(s.failures), s.failures[0].Title, s.failures[0].Category)
+}
+
+// saveFailures writes the failures collected during the run to a JSON file
+func (s *Scraper) saveFailures(filename string) error {
+	report := FailureReport{Failures: s.failures, Ambiguous: s.ambiguous, TVMatches: s.tvMatches.sorted()}
+	if report.Failures == nil {
+		report.Failures = []Failure{}
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal failures: %w", err)
+	}
+	data = append(data, '\n')
+
+	if err := s.writeFile(filename, data); err != nil {
+		return fmt.Errorf("failed to write failures file: %w", err)
+	}
+
+	fmt.Fprintf(s.logWriter(), "Saved %d failures and %d ambiguous matches to %s\n", len(report.Failures), len(report.Ambiguous), filename)
+	return nil
+}
+
+// templateCategories are the failures worth an override: titles TMDB search
+// couldn't resolve to a usable IMDB ID. Transient failures such as
+// quota_exceeded are left out, since the next run may resolve them.
+var templateCategories = map[string]bool{
+	failureNoResults:     true,
+	failureNoIMDBID:      true,
+	failureInvalidIMDBID: true,
+}
+
+// saveOverridesTemplate writes the unresolved titles in the shape of an
+// -overrides file, each with an empty IMDB ID to fill in. Entries left empty
+// are skipped when the file is loaded, so it can be used as-is.
+func (s *Scraper) saveOverridesTemplate(filename string) error {
+	template := make(map[string]string)
+	for _, failure := range s.failures {
+		if templateCategories[failure.Category] {
+			template[failure.Title] = ""
+		}
+	}
+
+	data, err := json.MarshalIndent(template, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal overrides template: %w", err)
+	}
+	data = append(data, '\n')
+
+	if err := s.writeFile(filename, data); err != nil {
+		return fmt.Errorf("failed to write overrides template: %w", err)
+	}
+
+	fmt.Fprintf(s.logWriter(), "Saved %d unresolved titles to %s\n", len(template), filename)
+	return nil
+}
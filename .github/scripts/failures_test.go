@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateMovieRejectsMalformedIMDBID(t *testing.T) {
+	scraper := NewScraper("dummy_key")
+
+	testCases := []struct {
+		imdbID   string
+		category string // empty if the movie should be accepted
+	}{
+		{"tt0117705", ""},
+		{"tt10872600", ""},
+		{"", failureNoIMDBID},
+		{"tt123", failureInvalidIMDBID},
+		{"nm0000123", failureInvalidIMDBID},
+		{"tt0117705 ", failureInvalidIMDBID},
+	}
+
+	for _, tc := range testCases {
+		err := scraper.validateMovie(&Movie{Title: "Space Jam", IMDBID: tc.imdbID})
+		if tc.category == "" {
+			if err != nil {
+				t.Errorf("IMDB ID %q: expected to be accepted, got %v", tc.imdbID, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("IMDB ID %q: expected to be rejected", tc.imdbID)
+			continue
+		}
+		if got := failureCategory(err); got != tc.category {
+			t.Errorf("IMDB ID %q: expected category %s, got %s", tc.imdbID, tc.category, got)
+		}
+	}
+}
+
+func TestFailureCategory(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected string
+	}{
+		{fmt.Errorf("%w for 'Dune'", errNoResults), failureNoResults},
+		{&tmdbStatusError{StatusCode: 500, Target: "'Dune'"}, failureHTTPError},
+		{errors.New("connection refused"), failureHTTPError},
+	}
+
+	for _, tc := range testCases {
+		if got := failureCategory(tc.err); got != tc.expected {
+			t.Errorf("failureCategory(%v) = %s, want %s", tc.err, got, tc.expected)
+		}
+	}
+}
+
+func TestSearchMovieSkipsEmptyTitle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected TMDB request for %s", r.URL)
+	}))
+	defer server.Close()
+
+	scraper := NewScraper("dummy_key")
+	scraper.tmdbBaseURL = server.URL
+
+	// A bare footnote marker cleans to nothing
+	title := scraper.cleanTitle(" [1] ")
+	if title != "" {
+		t.Fatalf("Expected the title to clean to empty, got %q", title)
+	}
+
+	_, err := scraper.searchMovie(title)
+	if err == nil {
+		t.Fatal("Expected an empty title to fail")
+	}
+	if got := failureCategory(err); got != failureEmptyTitle {
+		t.Errorf("Expected category %s, got %s (%v)", failureEmptyTitle, got, err)
+	}
+	if scraper.tmdbRequests != 0 {
+		t.Errorf("Expected no TMDB requests, got %d", scraper.tmdbRequests)
+	}
+}
+
+func TestCheckOutput(t *testing.T) {
+	movies := []Movie{
+		{Title: "Space Jam", IMDBID: "tt0117705", Genres: []string{"animation", "comedy"}},
+		{Title: "", IMDBID: "tt0099653"},
+		{Title: "Ghost"},
+		{Title: "Dune", IMDBID: "tt0087182", Genres: []string{""}},
+		newPlaceholder("Unknown Movie"),
+	}
+
+	scraper := NewScraper("dummy_key")
+	valid, err := scraper.checkOutput(movies)
+	if err != nil {
+		t.Fatalf("Expected warn mode not to fail, got %v", err)
+	}
+	if len(valid) != 2 || valid[0].Title != "Space Jam" || !valid[1].IsPlaceholder() {
+		t.Errorf("Expected Space Jam and the placeholder to pass, got %+v", valid)
+	}
+	if countFailures(scraper.failures, failureInvalidRecord) != 3 {
+		t.Errorf("Expected 3 invalid_record failures, got %+v", scraper.failures)
+	}
+
+	cfg := defaultConfig()
+	cfg.OutputValidation = outputValidationFail
+	scraper = NewScraper("dummy_key", WithConfig(cfg))
+	if _, err := scraper.checkOutput(movies); err == nil {
+		t.Error("Expected fail mode to reject the list")
+	}
+	if _, err := scraper.checkOutput(movies[:1]); err != nil {
+		t.Errorf("Expected a valid list to pass in fail mode, got %v", err)
+	}
+}
+
+func TestAmbiguousMatches(t *testing.T) {
+	mock := newMockTMDB(t, []string{"Space Jam", "Ghost", "The Addams Family"}, mockCatalog)
+	mock.totals["Ghost"] = 50
+	mock.totals["The Addams Family"] = 20
+	scraper := mock.newTestScraper(defaultConfig())
+
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	for _, movie := range movies {
+		if movie.SearchResults == 0 {
+			t.Errorf("Expected the search result count for %s to be recorded", movie.Title)
+		}
+	}
+
+	if len(scraper.ambiguous) != 2 {
+		t.Fatalf("Expected 2 ambiguous matches, got %+v", scraper.ambiguous)
+	}
+	if scraper.ambiguous[0].Title != "Ghost" || scraper.ambiguous[0].SearchResults != 50 {
+		t.Errorf("Expected Ghost to be the most ambiguous match, got %+v", scraper.ambiguous[0])
+	}
+
+	filename := filepath.Join(t.TempDir(), "failures.json")
+	if err := scraper.saveFailures(filename); err != nil {
+		t.Fatalf("Failed to save failures: %v", err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read failures: %v", err)
+	}
+	var report FailureReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Failed to parse failures: %v", err)
+	}
+	if len(report.Ambiguous) != 2 {
+		t.Errorf("Expected the ambiguous matches in the report, got %+v", report)
+	}
+
+	cfg := defaultConfig()
+	cfg.AmbiguityThreshold = 0
+	if ambiguous := NewScraper("dummy_key", WithConfig(cfg)).ambiguousMatches(movies); ambiguous != nil {
+		t.Errorf("Expected a zero threshold to disable the check, got %+v", ambiguous)
+	}
+}
+
+func TestSaveOverridesTemplate(t *testing.T) {
+	scraper := NewScraper("dummy_key")
+	scraper.failures = []Failure{
+		{Title: "Ghost Dad", Category: failureNoResults},
+		{Title: "Space Jam", Category: failureNoIMDBID},
+		{Title: "Dune", Category: failureQuotaExceeded},
+		{Title: "Ghost Dad", Category: failureNoResults},
+	}
+
+	filename := filepath.Join(t.TempDir(), "overrides.json")
+	if err := scraper.saveOverridesTemplate(filename); err != nil {
+		t.Fatalf("Failed to save template: %v", err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read template: %v", err)
+	}
+	expected := "{\n  \"Ghost Dad\": \"\",\n  \"Space Jam\": \"\"\n}\n"
+	if string(data) != expected {
+		t.Errorf("Expected template:\n%s\ngot:\n%s", expected, data)
+	}
+
+	// The template loads as an overrides file, and filled-in entries apply
+	if overrides, err := loadOverrides(filename); err != nil || len(overrides) != 0 {
+		t.Errorf("Expected an unfilled template to load with no overrides, got %v (%v)", overrides, err)
+	}
+	filled := strings.Replace(string(data), `"Space Jam": ""`, `"Space Jam": "tt0117705"`, 1)
+	if err := os.WriteFile(filename, []byte(filled), 0o644); err != nil {
+		t.Fatalf("Failed to fill in template: %v", err)
+	}
+	overrides, err := loadOverrides(filename)
+	if err != nil || overrides[normalizeTitle("Space Jam")] != "tt0117705" {
+		t.Errorf("Expected the filled-in override to load, got %v (%v)", overrides, err)
+	}
+}
+
+func TestSearchMovieSkipsSuspiciouslyLongTitle(t *testing.T) {
+	mock := newMockTMDB(t, nil, mockCatalog)
+	scraper := mock.newTestScraper(defaultConfig())
+
+	title := "Scott talks about how he has never seen this film and why everyone keeps telling him he really should watch it"
+	_, err := scraper.searchMovie(title)
+	if got := failureCategory(err); got != failureSuspiciousLen {
+		t.Errorf("Expected category %s, got %s (%v)", failureSuspiciousLen, got, err)
+	}
+	if scraper.tmdbRequests != 0 {
+		t.Errorf("Expected no TMDB requests, got %d", scraper.tmdbRequests)
+	}
+
+	// Titles within the limit, and any title with the guard off, are searched
+	if _, err := scraper.searchMovie("Space Jam"); err != nil {
+		t.Errorf("Expected a normal title to resolve, got %v", err)
+	}
+	scraper.config.MaxQueryLength = 0
+	if _, err := scraper.searchMovie(title); failureCategory(err) == failureSuspiciousLen {
+		t.Errorf("Expected -max-query-length 0 to disable the guard, got %v", err)
+	}
+}
+
+func TestGroupFailures(t *testing.T) {
+	failures := []Failure{
+		{Title: "Dune", Category: failureHTTPError},
+		{Title: "Ghost Dad", Category: failureNoResults},
+		{Title: "Space Jam", Category: failureNoIMDBID},
+		{Title: "Unknown Movie", Category: failureNoResults},
+		{Title: "The Addams Family", Category: failureHTTPError},
+		{Title: "Ghost", Category: failureHTTPError},
+		{Title: "Hook", Category: failureHTTPError},
+	}
+
+	groups := groupFailures(failures)
+	expected := []FailureGroup{
+		{Category: failureHTTPError, Count: 4, Examples: []string{"Dune", "The Addams Family", "Ghost"}},
+		{Category: failureNoResults, Count: 2, Examples: []string{"Ghost Dad", "Unknown Movie"}},
+		{Category: failureNoIMDBID, Count: 1, Examples: []string{"Space Jam"}},
+	}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("Expected groups %+v, got %+v", expected, groups)
+	}
+
+	var output strings.Builder
+	printFailureGroups(&output, groups)
+	if !strings.Contains(output.String(), "http_error         4 (Dune, The Addams Family, Ghost, ...)") {
+		t.Errorf("Expected the http_error group with examples, got:\n%s", output.String())
+	}
+	if groupFailures(nil) != nil {
+		t.Error("Expected no groups without failures")
+	}
+}
+
+func TestCheckStrict(t *testing.T) {
+	mock := newMockTMDB(t, nil, mockCatalog)
+	cfg, err := parseFlags([]string{"-strict"})
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if cfg.FailuresFile != strictFailuresFile {
+		t.Errorf("Expected -strict to default -failures to %s, got %q", strictFailuresFile, cfg.FailuresFile)
+	}
+
+	scraper := mock.newTestScraper(cfg)
+	if _, err := scraper.resolveTitles(context.Background(), []string{"Space Jam", "Ghost"}); err != nil {
+		t.Fatalf("Failed to resolve titles: %v", err)
+	}
+	if err := scraper.checkStrict(); err != nil {
+		t.Errorf("Expected a clean run to pass, got %v", err)
+	}
+
+	if _, err := scraper.resolveTitles(context.Background(), []string{"Space Jam", "Unknown Movie"}); err != nil {
+		t.Fatalf("Failed to resolve titles: %v", err)
+	}
+	if err := scraper.checkStrict(); err == nil || !strings.Contains(err.Error(), `"Unknown Movie"`) {
+		t.Errorf("Expected the unresolved title to fail the run, got %v", err)
+	}
+
+	// Best-effort remains the default
+	scraper.config.Strict = false
+	if err := scraper.checkStrict(); err != nil {
+		t.Errorf("Expected failures to be tolerated without -strict, got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Fields accepted by -require-fields
+const (
+	fieldIMDBID = "imdb_id"
+	fieldTMDBID = "tmdb_id"
+	fieldYear   = "year"
+	fieldPoster = "poster"
+)
+
+// requiredFields lists the checks behind -require-fields in the order they
+// are applied. A movie missing a required field fails with the field's
+// failure category.
+var requiredFields = []struct {
+	name     string
+	category string
+	present  func(movie *Movie) bool
+}{
+	{fieldIMDBID, failureNoIMDBID, func(m *Movie) bool { return m.IMDBID != "" }},
+	{fieldTMDBID, failureNoTMDBID, func(m *Movie) bool { return m.TMDBID != 0 }},
+	{fieldYear, failureNoYear, func(m *Movie) bool { return m.Year > 0 }},
+	{fieldPoster, failureNoPoster, func(m *Movie) bool { return m.PosterURL != "" }},
+}
+
+// requiresField reports whether -require-fields includes field
+func (c Config) requiresField(field string) bool {
+	for _, required := range c.RequireFields {
+		if required == field {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRequiredFields returns an error for the first required field the movie
+// is missing. With -keep-tmdb-only a TMDB ID stands in for the IMDB ID.
+func (s *Scraper) checkRequiredFields(movie *Movie) error {
+	for _, field := range requiredFields {
+		if !s.config.requiresField(field.name) || field.present(movie) {
+			continue
+		}
+		if field.name == fieldIMDBID && s.config.KeepTMDBOnly && movie.TMDBID != 0 {
+			continue
+		}
+		return &categorizedError{Category: field.category, Err: fmt.Errorf("missing required field %s", field.name)}
+	}
+	return nil
+}
+
+// printMissingFields prints how many movies each required field dropped
+func (s *Scraper) printMissingFields(failures []Failure) {
+	for _, field := range requiredFields {
+		if s.config.requiresField(field.name) {
+			fmt.Fprintf(s.logWriter(), "  Missing %s: %d\n", field.name, countFailures(failures, field.category))
+		}
+	}
+}
+
+// fieldListValue parses the comma-separated -require-fields list. The first
+// use replaces the default list; an empty value requires no fields.
+type fieldListValue struct {
+	fields *[]string
+	set    bool
+}
+
+func (f *fieldListValue) String() string {
+	if f.fields == nil {
+		return ""
+	}
+	return strings.Join(*f.fields, ",")
+}
+
+func (f *fieldListValue) Set(value string) error {
+	if !f.set {
+		*f.fields = nil
+		f.set = true
+	}
+	for _, field := range strings.Split(value, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" {
+			continue
+		}
+		if !knownField(field) {
+			return fmt.Errorf("unknown field %q (expected %s, %s, %s or %s)", field, fieldIMDBID, fieldTMDBID, fieldYear, fieldPoster)
+		}
+		*f.fields = append(*f.fields, field)
+	}
+	return nil
+}
+
+// knownField reports whether field can be used with -require-fields
+func knownField(field string) bool {
+	for _, known := range requiredFields {
+		if known.name == field {
+			return true
+		}
+	}
+	return false
+}
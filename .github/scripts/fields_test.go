@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseFlagsRequireFields(t *testing.T) {
+	cfg, err := parseFlags(nil)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.RequireFields, []string{fieldIMDBID}) {
+		t.Errorf("Expected imdb_id to be required by default, got %v", cfg.RequireFields)
+	}
+
+	cfg, err = parseFlags([]string{"-require-fields", "tmdb_id, Year,poster"})
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.RequireFields, []string{fieldTMDBID, fieldYear, fieldPoster}) {
+		t.Errorf("Expected the flag to replace the default, got %v", cfg.RequireFields)
+	}
+
+	cfg, err = parseFlags([]string{"-require-fields", ""})
+	if err != nil || len(cfg.RequireFields) != 0 {
+		t.Errorf("Expected an empty list to require nothing, got %v (%v)", cfg.RequireFields, err)
+	}
+
+	if _, err := parseFlags([]string{"-require-fields", "imdb_id,rating"}); err == nil {
+		t.Errorf("Expected an unknown field to be rejected")
+	}
+
+	// The default list is shared by every parse and must not be modified
+	if !reflect.DeepEqual(defaultConfig().RequireFields, []string{fieldIMDBID}) {
+		t.Errorf("Default config was modified: %v", defaultConfig().RequireFields)
+	}
+}
+
+func TestRequireFields(t *testing.T) {
+	catalog := append([]mockMovie{}, mockCatalog...)
+	catalog[0].NoPoster = true  // Space Jam
+	catalog[1].ReleaseDate = "" // The Addams Family
+	mock := newMockTMDB(t, []string{"Space Jam", "The Addams Family", "Ghost"}, catalog)
+	mock.idStatus[251] = 404 // Ghost has no IMDB ID
+
+	cfg := defaultConfig()
+	cfg.RequireFields = []string{fieldIMDBID, fieldYear, fieldPoster}
+	scraper := mock.newTestScraper(cfg)
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if len(movies) != 0 {
+		t.Errorf("Expected every movie to be missing a field, got %+v", movies)
+	}
+	categories := make(map[string]string)
+	for _, failure := range scraper.failures {
+		categories[failure.Title] = failure.Category
+	}
+	expected := map[string]string{"Space Jam": failureNoPoster, "The Addams Family": failureNoYear, "Ghost": failureNoIMDBID}
+	if !reflect.DeepEqual(categories, expected) {
+		t.Errorf("Expected failures %v, got %v", expected, categories)
+	}
+
+	// Without imdb_id required, a movie without one is kept
+	cfg.RequireFields = []string{fieldTMDBID}
+	movies, err = mock.newTestScraper(cfg).generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if len(movies) != 3 {
+		t.Errorf("Expected all movies with only tmdb_id required, got %+v", movies)
+	}
+}
+
+func TestDatelessCandidates(t *testing.T) {
+	catalog := append([]mockMovie{}, mockCatalog...)
+	catalog[1].ReleaseDate = "" // The Addams Family
+	mock := newMockTMDB(t, []string{"The Addams Family"}, catalog)
+
+	// Without a required year, a dateless movie is kept with an unknown year
+	movies, err := mock.newTestScraper(defaultConfig()).generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if len(movies) != 1 || movies[0].Year != 0 || movies[0].IMDBID != "tt0101272" {
+		t.Errorf("Expected the dateless movie to be kept without a year, got %+v", movies)
+	}
+
+	cfg, err := parseFlags([]string{"-require-year"})
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.RequireFields, []string{fieldIMDBID, fieldYear}) {
+		t.Errorf("Expected -require-year to add year to the required fields, got %v", cfg.RequireFields)
+	}
+	scraper := mock.newTestScraper(cfg)
+	if movies, err := scraper.generateRadarrList(context.Background()); err != nil || len(movies) != 0 {
+		t.Errorf("Expected -require-year to drop the dateless movie, got %+v (%v)", movies, err)
+	}
+	if countFailures(scraper.failures, failureNoYear) != 1 {
+		t.Errorf("Expected a no_year failure, got %+v", scraper.failures)
+	}
+
+	// A year hint neither matches nor rules out a dateless candidate
+	candidates := []TMDBMovie{
+		{ID: 1, Title: "Dune", Popularity: 90},
+		{ID: 841, Title: "Dune", ReleaseDate: time.Date(1984, 12, 14, 0, 0, 0, 0, time.UTC), Popularity: 30},
+	}
+	testCases := []struct {
+		strategy string
+		year     int
+		expected int
+	}{
+		{matchStrategyFirst, 1984, 841},
+		{matchStrategyFirst, 2021, 1},
+		{matchStrategyExactYearThenPopular, 1984, 841},
+		{matchStrategyExactYearThenPopular, 2021, 1},
+	}
+	for _, tc := range testCases {
+		strategy := matchStrategies[tc.strategy]
+		if got := strategy(matchQuery{Title: "Dune", Year: tc.year}, candidates); got.ID != tc.expected {
+			t.Errorf("%s for %d: expected TMDB ID %d, got %d", tc.strategy, tc.year, tc.expected, got.ID)
+		}
+	}
+	if year := candidateYear(candidates[0]); year != "?" {
+		t.Errorf("Expected a dateless candidate's year to be unknown, got %q", year)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// TMDB genre names removed by the -no-docs and -no-tv-movies shortcuts
+const (
+	genreDocumentary = "documentary"
+	genreTVMovie     = "tv_movie"
+)
+
+// loadGenreAliases reads a JSON object mapping TMDB genre names (as in
+// genreMap, e.g. "science_fiction") to the labels written to the output.
+// Entries with an empty label are skipped, keeping the TMDB name.
+func loadGenreAliases(filename string) (map[string]string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read genre aliases file: %w", err)
+	}
+
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse genre aliases file: %w", err)
+	}
+
+	aliases := make(map[string]string, len(raw))
+	for genre, label := range raw {
+		if label == "" {
+			continue
+		}
+		aliases[genre] = label
+	}
+
+	if err := validateGenreAliases(aliases); err != nil {
+		return nil, err
+	}
+	return aliases, nil
+}
+
+// validateGenreAliases checks that every alias names a TMDB genre and that no
+// two genres end up with the same label, including genres left unaliased, so
+// the output genres can still be told apart
+func validateGenreAliases(aliases map[string]string) error {
+	known := knownGenres()
+
+	aliased := make([]string, 0, len(aliases))
+	for genre := range aliases {
+		if !known[genre] {
+			return fmt.Errorf("genre alias for unknown TMDB genre %q", genre)
+		}
+		aliased = append(aliased, genre)
+	}
+
+	genres := make([]string, 0, len(known))
+	for genre := range known {
+		genres = append(genres, genre)
+	}
+	sort.Strings(genres)
+
+	labels := make(map[string]string, len(genres))
+	for _, genre := range genres {
+		label := genreLabel(aliases, genre)
+		if other, ok := labels[label]; ok {
+			return fmt.Errorf("genres %q and %q would both be written as %q", other, genre, label)
+		}
+		labels[label] = genre
+	}
+	return nil
+}
+
+// knownGenres returns the set of TMDB genre names in genreMap
+func knownGenres() map[string]bool {
+	known := make(map[string]bool, len(genreMap))
+	for _, genre := range genreMap {
+		known[genre] = true
+	}
+	return known
+}
+
+// WithGenreAliases sets the labels used in place of TMDB genre names
+func WithGenreAliases(aliases map[string]string) Option {
+	return func(s *Scraper) {
+		s.genreAliases = aliases
+	}
+}
+
+// genreLabel returns the output label for a TMDB genre name
+func genreLabel(aliases map[string]string, genre string) string {
+	if label, ok := aliases[genre]; ok {
+		return label
+	}
+	return genre
+}
+
+// excludedGenres returns the TMDB genres removed by -exclude-genres and the
+// -no-docs and -no-tv-movies shortcuts
+func (c Config) excludedGenres() []string {
+	excluded := append([]string{}, c.ExcludeGenres...)
+	if c.NoDocs {
+		excluded = append(excluded, genreDocumentary)
+	}
+	if c.NoTVMovies {
+		excluded = append(excluded, genreTVMovie)
+	}
+	return excluded
+}
+
+// withoutGenres returns the movies tagged with none of the excluded TMDB
+// genres, along with the number of movies dropped. Genres are compared by
+// their output label, so exclusions still apply with -genre-aliases.
+func (s *Scraper) withoutGenres(movies []Movie, excluded []string) ([]Movie, int) {
+	labels := make(map[string]bool, len(excluded))
+	for _, genre := range excluded {
+		labels[genreLabel(s.genreAliases, genre)] = true
+	}
+
+	kept := make([]Movie, 0, len(movies))
+	for _, movie := range movies {
+		if !hasAnyGenre(movie, labels) {
+			kept = append(kept, movie)
+		}
+	}
+	return kept, len(movies) - len(kept)
+}
+
+// hasAnyGenre reports whether a movie is tagged with one of the genres
+func hasAnyGenre(movie Movie, genres map[string]bool) bool {
+	for _, genre := range movie.Genres {
+		if genres[genre] {
+			return true
+		}
+	}
+	return false
+}
+
+// genreListValue parses a comma-separated list of TMDB genre names from a
+// flag. The flag may be repeated to add more genres.
+type genreListValue []string
+
+func (g *genreListValue) String() string {
+	return strings.Join(*g, ",")
+}
+
+func (g *genreListValue) Set(value string) error {
+	known := knownGenres()
+	for _, genre := range strings.Split(value, ",") {
+		genre = strings.ToLower(strings.TrimSpace(genre))
+		if genre == "" {
+			continue
+		}
+		if !known[genre] {
+			return fmt.Errorf("unknown TMDB genre %q", genre)
+		}
+		*g = append(*g, genre)
+	}
+	return nil
+}
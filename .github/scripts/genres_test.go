@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadGenreAliases(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "genres.json")
+	content := `{"science_fiction": "sci-fi", "tv_movie": "tv", "horror": ""}`
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write aliases file: %v", err)
+	}
+
+	aliases, err := loadGenreAliases(filename)
+	if err != nil {
+		t.Fatalf("Failed to load genre aliases: %v", err)
+	}
+	expected := map[string]string{"science_fiction": "sci-fi", "tv_movie": "tv"}
+	if !reflect.DeepEqual(aliases, expected) {
+		t.Errorf("Expected %v, got %v", expected, aliases)
+	}
+
+	scraper := NewScraper("dummy_key", WithGenreAliases(aliases))
+	genres := scraper.getGenres([]int{878, 28, 10770})
+	if !reflect.DeepEqual(genres, []string{"sci-fi", "action", "tv"}) {
+		t.Errorf("Unexpected genres %v", genres)
+	}
+}
+
+func TestValidateGenreAliases(t *testing.T) {
+	testCases := []struct {
+		name    string
+		aliases map[string]string
+		errText string
+	}{
+		{"no aliases", nil, ""},
+		{"swap", map[string]string{"fantasy": "science_fiction", "science_fiction": "fantasy"}, ""},
+		{"unknown genre", map[string]string{"sci-fi": "scifi"}, "unknown TMDB genre"},
+		{"two genres to one label", map[string]string{"thriller": "suspense", "mystery": "suspense"}, `"mystery" and "thriller"`},
+		{"onto an unaliased genre", map[string]string{"tv_movie": "drama"}, `"drama" and "tv_movie"`},
+	}
+
+	for _, tc := range testCases {
+		err := validateGenreAliases(tc.aliases)
+		if tc.errText == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tc.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.errText) {
+			t.Errorf("%s: expected error containing %q, got %v", tc.name, tc.errText, err)
+		}
+	}
+}
+
+func TestExcludeGenres(t *testing.T) {
+	cfg, err := parseFlags([]string{"-exclude-genres", "Horror, war", "-no-docs", "-no-tv-movies"})
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	excluded := cfg.excludedGenres()
+	if !reflect.DeepEqual(excluded, []string{"horror", "war", "documentary", "tv_movie"}) {
+		t.Errorf("Unexpected excluded genres %v", excluded)
+	}
+	if _, err := parseFlags([]string{"-exclude-genres", "docs"}); err == nil {
+		t.Errorf("Expected an unknown genre to be rejected")
+	}
+
+	movies := []Movie{
+		{Title: "Space Jam", IMDBID: "tt0117705", Genres: []string{"animation", "comedy", "family"}},
+		{Title: "Jiro Dreams of Sushi", IMDBID: "tt1772925", Genres: []string{"documentary"}},
+		{Title: "High School Musical", IMDBID: "tt0475293", Genres: []string{"tv_movie", "comedy"}},
+		newPlaceholder("Some Obscure Film"),
+	}
+	kept, dropped := NewScraper("dummy_key").withoutGenres(movies, []string{genreDocumentary, genreTVMovie})
+	if dropped != 2 || len(kept) != 2 || kept[0].Title != "Space Jam" || !kept[1].IsPlaceholder() {
+		t.Errorf("Expected Space Jam and the placeholder with 2 dropped, got %+v (%d)", kept, dropped)
+	}
+
+	// Exclusions name TMDB genres even when they are written under an alias
+	movies[1].Genres = []string{"docs"}
+	scraper := NewScraper("dummy_key", WithGenreAliases(map[string]string{genreDocumentary: "docs"}))
+	if _, dropped := scraper.withoutGenres(movies, []string{genreDocumentary}); dropped != 1 {
+		t.Errorf("Expected the aliased documentary to be dropped, got %d", dropped)
+	}
+}
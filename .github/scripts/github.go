@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// githubListPath is the path of the committed list in the repository
+const githubListPath = "scott_hasnt_seen.json"
+
+// githubBase locates the committed list on the branch a run is compared
+// against
+type githubBase struct {
+	apiURL     string
+	repository string // owner/name
+	ref        string
+	token      string // optional; public repositories can be read without one
+}
+
+// githubBaseFromEnv returns the branch to compare against inside GitHub
+// Actions: a pull request's base branch, otherwise the branch being built.
+// Outside Actions it returns false.
+func githubBaseFromEnv() (githubBase, bool) {
+	if os.Getenv("GITHUB_ACTIONS") != "true" {
+		return githubBase{}, false
+	}
+
+	base := githubBase{
+		apiURL:     strings.TrimRight(os.Getenv("GITHUB_API_URL"), "/"),
+		repository: os.Getenv("GITHUB_REPOSITORY"),
+		ref:        os.Getenv("GITHUB_BASE_REF"),
+		token:      os.Getenv("GITHUB_TOKEN"),
+	}
+	if base.apiURL == "" {
+		base.apiURL = "https://api.github.com"
+	}
+	if base.ref == "" {
+		base.ref = os.Getenv("GITHUB_REF_NAME")
+	}
+	if base.repository == "" || base.ref == "" {
+		return githubBase{}, false
+	}
+	return base, true
+}
+
+// String describes the compared list for reports, e.g. scott_hasnt_seen.json@main
+func (g githubBase) String() string {
+	return fmt.Sprintf("%s@%s", githubListPath, g.ref)
+}
+
+// fetchList downloads the committed list with a single contents API request.
+// A list that doesn't exist on the branch yet counts as empty.
+func (g githubBase) fetchList(client *http.Client) ([]Movie, error) {
+	apiURL := fmt.Sprintf("%s/repos/%s/contents/%s?ref=%s", g.apiURL, g.repository, githubListPath, url.QueryEscape(g.ref))
+	req, err := http.NewRequest("GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github.raw+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if g.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", g, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("failed to fetch %s: GitHub API returned status %d", g, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", g, err)
+	}
+	return parseMovies(data, g.String())
+}
+
+// diffAgainstBase compares movies against the committed list on the base
+// branch when running in GitHub Actions, falling back to the local list file
+// outside Actions, offline, or if GitHub can't be reached. It also returns
+// a description of what was compared against.
+func (s *Scraper) diffAgainstBase(movies []Movie, filename string) (MovieDiff, string, error) {
+	if base, ok := githubBaseFromEnv(); ok && s.config.Offline == "" {
+		previous, err := base.fetchList(s.client)
+		if err == nil {
+			return diffMovies(previous, movies), base.String(), nil
+		}
+		log.Printf("Comparing against the local list instead: %v", err)
+	}
+
+	diff, err := diffAgainstFile(movies, filename)
+	return diff, filename, err
+}
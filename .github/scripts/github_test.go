@@ -0,0 +1,92 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffAgainstBase(t *testing.T) {
+	status := http.StatusOK
+	body := `[{"title":"Dune","imdb_id":"tt0087182","year":1984}]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/repo/contents/scott_hasnt_seen.json" || r.URL.Query().Get("ref") != "main" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("Expected the token to be sent, got %q", r.Header.Get("Authorization"))
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_API_URL", server.URL)
+	t.Setenv("GITHUB_REPOSITORY", "owner/repo")
+	t.Setenv("GITHUB_BASE_REF", "main")
+	t.Setenv("GITHUB_TOKEN", "secret")
+
+	local := filepath.Join(t.TempDir(), "scott_hasnt_seen.json")
+	movies := []Movie{{Title: "Ghost", IMDBID: "tt0099653", Year: 1990}}
+	scraper := NewScraper("dummy_key")
+
+	diff, against, err := scraper.diffAgainstBase(movies, local)
+	if err != nil {
+		t.Fatalf("Failed to diff: %v", err)
+	}
+	if against != "scott_hasnt_seen.json@main" || len(diff.Added) != 1 || len(diff.Removed) != 1 {
+		t.Errorf("Expected a diff against the base branch, got %+v against %s", diff, against)
+	}
+
+	// A list committed in the wrapped format is read the same way
+	body = `{"version":1,"movies":[{"title":"Dune","imdb_id":"tt0087182","year":1984}]}`
+	diff, against, err = scraper.diffAgainstBase(movies, local)
+	if err != nil {
+		t.Fatalf("Failed to diff against a wrapped list: %v", err)
+	}
+	if against != "scott_hasnt_seen.json@main" || len(diff.Added) != 1 || len(diff.Removed) != 1 || diff.Removed[0].IMDBID != "tt0087182" {
+		t.Errorf("Expected a diff against the wrapped base list, got %+v against %s", diff, against)
+	}
+
+	// GitHub errors fall back to the local file, which is missing here
+	status = http.StatusInternalServerError
+	diff, against, err = scraper.diffAgainstBase(movies, local)
+	if err != nil || against != local || len(diff.Removed) != 0 {
+		t.Errorf("Expected a diff against the local file, got %+v against %s (%v)", diff, against, err)
+	}
+
+	// A list that isn't on the branch yet counts as empty
+	status = http.StatusNotFound
+	diff, against, err = scraper.diffAgainstBase(movies, local)
+	if err != nil || against != "scott_hasnt_seen.json@main" || len(diff.Added) != 1 || len(diff.Removed) != 0 {
+		t.Errorf("Expected everything to be added, got %+v against %s (%v)", diff, against, err)
+	}
+
+	t.Setenv("GITHUB_ACTIONS", "")
+	if _, against, _ := scraper.diffAgainstBase(movies, local); against != local {
+		t.Errorf("Expected the local file outside Actions, got %s", against)
+	}
+}
+
+func TestDiffMarkdown(t *testing.T) {
+	diff := MovieDiff{
+		Added:   []Movie{{Title: "Ghost", IMDBID: "tt0099653", Year: 1990}},
+		Changed: []Movie{{Title: "Dune", IMDBID: "tt0087182", Year: 1984}},
+	}
+	markdown := diffMarkdown(diff, "scott_hasnt_seen.json@main")
+	for _, want := range []string{"`scott_hasnt_seen.json@main`", "**Added (1)**\n\n- Ghost (1990) [tt0099653]\n", "**Changed (1)**\n\n- Dune (1984) [tt0087182]\n"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Expected the comment to contain %q, got:\n%s", want, markdown)
+		}
+	}
+	if strings.Contains(markdown, "Removed") {
+		t.Errorf("Expected empty sections to be left out, got:\n%s", markdown)
+	}
+
+	if markdown := diffMarkdown(MovieDiff{}, "scott_hasnt_seen.json"); !strings.Contains(markdown, "No changes.") {
+		t.Errorf("Expected an empty diff to say so, got:\n%s", markdown)
+	}
+}
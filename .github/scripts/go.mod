@@ -5,9 +5,7 @@ go 1.21
 require (
 	github.com/PuerkitoBio/goquery v1.8.1
 	github.com/joho/godotenv v1.5.1
+	golang.org/x/net v0.17.0
 )
 
-require (
-	github.com/andybalholm/cascadia v1.3.1 // indirect
-	golang.org/x/net v0.17.0 // indirect
-)
+require github.com/andybalholm/cascadia v1.3.1 // indirect
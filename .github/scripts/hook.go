@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// runPostHook runs the -post-hook command after a successful run. The
+// command is split on whitespace and run without a shell, with the main
+// list's absolute path appended as its last argument and the final run
+// summary as JSON on its stdin. Its output is logged line by line. A
+// failing hook is reported but leaves the written files alone.
+func (s *Scraper) runPostHook(ctx context.Context, summary RunSummary) error {
+	args := strings.Fields(s.config.PostHook)
+	if len(args) == 0 {
+		return nil
+	}
+
+	path, err := filepath.Abs(mainOutputBase + s.formatSuffix(s.config.Format))
+	if err != nil {
+		return fmt.Errorf("failed to resolve the output path: %w", err)
+	}
+	input, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("failed to encode run summary: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], append(args[1:], path)...)
+	cmd.Stdin = bytes.NewReader(append(input, '\n'))
+	fmt.Fprintf(s.logWriter(), "Running post-hook: %s %s\n", strings.Join(args, " "), path)
+	output, err := cmd.CombinedOutput()
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		log.Printf("post-hook: %s", scanner.Text())
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("post-hook exited with status %d", exitErr.ExitCode())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run post-hook: %w", err)
+	}
+	fmt.Fprintln(s.logWriter(), "Post-hook exited with status 0")
+	return nil
+}
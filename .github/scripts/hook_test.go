@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRunPostHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test hook is a shell script")
+	}
+
+	dir := t.TempDir()
+	hook := filepath.Join(dir, "hook.sh")
+	script := "#!/bin/sh\necho \"uploading $2\"\nprintf '%s\\n' \"$@\" > \"" + dir + "/args\"\ncat > \"" + dir + "/stdin\"\nexit ${HOOK_STATUS:-0}\n"
+	if err := os.WriteFile(hook, []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to write hook: %v", err)
+	}
+
+	cfg := defaultConfig()
+	cfg.PostHook = hook + " upload"
+	scraper := NewScraper("dummy_key", WithConfig(cfg))
+	summary := RunSummary{RunID: scraper.runID, Successful: 3, Failed: 1, Final: true}
+
+	output := captureStdout(t, func() {
+		if err := scraper.runPostHook(context.Background(), summary); err != nil {
+			t.Errorf("Expected the hook to succeed, got %v", err)
+		}
+	})
+	if !strings.Contains(output, "Post-hook exited with status 0") {
+		t.Errorf("Expected the exit status to be reported, got %q", output)
+	}
+
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	if err != nil {
+		t.Fatalf("Expected the hook to run: %v", err)
+	}
+	path, _ := filepath.Abs(mainOutputBase + ".json")
+	if string(args) != "upload\n"+path+"\n" {
+		t.Errorf("Expected the configured arguments and then the list path, got %q", args)
+	}
+
+	stdin, err := os.ReadFile(filepath.Join(dir, "stdin"))
+	if err != nil {
+		t.Fatalf("Failed to read the hook's stdin: %v", err)
+	}
+	var got RunSummary
+	if err := json.Unmarshal(stdin, &got); err != nil || !reflect.DeepEqual(got, summary) {
+		t.Errorf("Expected the run summary on stdin, got %s (%v)", stdin, err)
+	}
+
+	t.Setenv("HOOK_STATUS", "3")
+	err = scraper.runPostHook(context.Background(), summary)
+	if err == nil || !strings.Contains(err.Error(), "status 3") {
+		t.Errorf("Expected the hook's exit status in the error, got %v", err)
+	}
+
+	if _, err := parseFlags([]string{"-post-hook", hook, "-stdout"}); err == nil {
+		t.Error("Expected -post-hook with -stdout to be rejected")
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hostLimit caps the requests in flight to one host and spaces out when they
+// start
+type hostLimit struct {
+	Concurrency int
+	Interval    time.Duration // minimum gap between request starts, or 0
+}
+
+// defaultHostLimits returns the per-host limits used unless -host-limit
+// overrides them. The wiki is a shared fandom host that only needs a handful
+// of pages, so it gets one request at a time, as does IMDB's unofficial
+// suggestion endpoint used by -imdb-fallback; the TMDB API and its image host
+// are built for parallel clients.
+func defaultHostLimits() map[string]hostLimit {
+	return map[string]hostLimit{
+		"comedybangbang.fandom.com": {Concurrency: 1, Interval: 500 * time.Millisecond},
+		"api.themoviedb.org":        {Concurrency: 10},
+		"www.themoviedb.org":        {Concurrency: 10},
+		"image.tmdb.org":            {Concurrency: 10},
+		"v3.sg.media-imdb.com":      {Concurrency: 1, Interval: time.Second},
+	}
+}
+
+// hostGate enforces one host's limit
+type hostGate struct {
+	limit hostLimit
+	slots chan struct{}
+
+	mu   sync.Mutex
+	next time.Time // earliest start for the next request
+}
+
+// wait blocks until the request may start, returning early with the
+// context's error if it is cancelled first. The caller must call release once
+// the request is done.
+func (g *hostGate) wait(ctx context.Context) error {
+	if err := acquire(ctx, g.slots); err != nil {
+		return err
+	}
+	if g.limit.Interval <= 0 {
+		return nil
+	}
+
+	g.mu.Lock()
+	now := time.Now()
+	start := g.next
+	if start.Before(now) {
+		start = now
+	}
+	g.next = start.Add(g.limit.Interval)
+	g.mu.Unlock()
+
+	delay := start.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		g.release()
+		return ctx.Err()
+	}
+}
+
+func (g *hostGate) release() {
+	<-g.slots
+}
+
+// hostLimiter is a RoundTripper that applies a separate limit to each host
+// named in its map. Requests to other hosts pass straight through.
+type hostLimiter struct {
+	next  http.RoundTripper
+	gates map[string]*hostGate
+}
+
+// newHostLimiter wraps next with the given per-host limits. A nil next uses
+// http.DefaultTransport.
+func newHostLimiter(next http.RoundTripper, limits map[string]hostLimit) *hostLimiter {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	gates := make(map[string]*hostGate, len(limits))
+	for host, limit := range limits {
+		gates[strings.ToLower(host)] = &hostGate{limit: limit, slots: make(chan struct{}, limit.Concurrency)}
+	}
+	return &hostLimiter{next: next, gates: gates}
+}
+
+// RoundTrip waits for the host's limit, then sends the request. The slot is
+// held until the response body has been read to the end or closed, so a slow
+// download still counts as in flight.
+func (h *hostLimiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	gate, ok := h.gates[strings.ToLower(req.URL.Hostname())]
+	if !ok {
+		return h.next.RoundTrip(req)
+	}
+
+	if err := gate.wait(req.Context()); err != nil {
+		return nil, err
+	}
+	resp, err := h.next.RoundTrip(req)
+	if err != nil {
+		gate.release()
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: gate.release}
+	return resp, nil
+}
+
+// releasingBody frees its host slot when the body has been read to the end
+// or is closed, whichever comes first, so a caller that finishes reading a
+// page doesn't hold up the next request to its host until it closes it
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.once.Do(b.release)
+	}
+	return n, err
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// hostLimitsValue parses -host-limit values of the form
+// host=concurrency[/interval], e.g. comedybangbang.fandom.com=1/2s. Several
+// hosts may be given comma-separated or by repeating the flag; each replaces
+// that host's default.
+type hostLimitsValue map[string]hostLimit
+
+func (v *hostLimitsValue) String() string {
+	if v == nil {
+		return ""
+	}
+	hosts := make([]string, 0, len(*v))
+	for host := range *v {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	parts := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		limit := (*v)[host]
+		part := fmt.Sprintf("%s=%d", host, limit.Concurrency)
+		if limit.Interval > 0 {
+			part += "/" + limit.Interval.String()
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (v *hostLimitsValue) Set(value string) error {
+	if *v == nil {
+		*v = make(hostLimitsValue)
+	}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, spec, ok := strings.Cut(entry, "=")
+		host = strings.ToLower(strings.TrimSpace(host))
+		if !ok || host == "" {
+			return fmt.Errorf("invalid host limit %q (expected host=concurrency[/interval])", entry)
+		}
+
+		var limit hostLimit
+		concurrency, interval, hasInterval := strings.Cut(spec, "/")
+		n, err := strconv.Atoi(strings.TrimSpace(concurrency))
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid concurrency in host limit %q (expected a whole number of at least 1)", entry)
+		}
+		limit.Concurrency = n
+		if hasInterval {
+			d, err := time.ParseDuration(strings.TrimSpace(interval))
+			if err != nil || d < 0 {
+				return fmt.Errorf("invalid interval in host limit %q (expected a duration such as 500ms)", entry)
+			}
+			limit.Interval = d
+		}
+		(*v)[host] = limit
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHostLimiterConcurrency(t *testing.T) {
+	var inFlight, peak int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&inFlight, 1)
+		defer atomic.AddInt64(&inFlight, -1)
+		for {
+			p := atomic.LoadInt64(&peak)
+			if n <= p || atomic.CompareAndSwapInt64(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newHostLimiter(nil, map[string]hostLimit{"127.0.0.1": {Concurrency: 2}})}
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Errorf("Request failed: %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Errorf("Expected at most 2 requests in flight, got %d", peak)
+	}
+}
+
+func TestHostLimiterInterval(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	limits := map[string]hostLimit{"127.0.0.1": {Concurrency: 5, Interval: 50 * time.Millisecond}}
+	client := &http.Client{Transport: newHostLimiter(nil, limits)}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("Expected requests to be spaced 50ms apart, took %s", elapsed)
+	}
+
+	// Hosts without a limit aren't delayed
+	client = &http.Client{Transport: newHostLimiter(nil, map[string]hostLimit{"example.com": {Concurrency: 1, Interval: time.Hour}})}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestParseFlagsHostLimit(t *testing.T) {
+	cfg, err := parseFlags([]string{"-host-limit", "comedybangbang.fandom.com=2/1s,Mirror.example.com=3", "-host-limit", "api.themoviedb.org=4"})
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	expected := map[string]hostLimit{
+		"comedybangbang.fandom.com": {Concurrency: 2, Interval: time.Second},
+		"mirror.example.com":        {Concurrency: 3},
+		"api.themoviedb.org":        {Concurrency: 4},
+		"www.themoviedb.org":        defaultHostLimits()["www.themoviedb.org"],
+	}
+	for host, limit := range expected {
+		if cfg.HostLimits[host] != limit {
+			t.Errorf("Expected %s to be limited to %+v, got %+v", host, limit, cfg.HostLimits[host])
+		}
+	}
+
+	for _, value := range []string{"example.com", "=2", "example.com=0", "example.com=two", "example.com=1/soon", "example.com=1/-1s"} {
+		if _, err := parseFlags([]string{"-host-limit", value}); err == nil {
+			t.Errorf("Expected -host-limit %q to be rejected", value)
+		}
+	}
+}
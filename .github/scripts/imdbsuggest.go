@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultIMDBSuggestURL is IMDB's public search-as-you-type endpoint
+const defaultIMDBSuggestURL = "https://v3.sg.media-imdb.com/suggestion"
+
+// imdbSuggestion is one entry of an IMDB suggestion response
+type imdbSuggestion struct {
+	ID    string `json:"id"`
+	Title string `json:"l"`
+	Kind  string `json:"qid"` // movie, tvSeries, tvMovie, video, ...
+	Year  int    `json:"y"`
+}
+
+// imdbSuggestionResponse is the body of an IMDB suggestion response
+type imdbSuggestionResponse struct {
+	Suggestions []imdbSuggestion `json:"d"`
+}
+
+// imdbSuggestKinds are the suggestion types that can be Radarr movies
+var imdbSuggestKinds = map[string]bool{"movie": true, "tvMovie": true, "video": true}
+
+// minSuggestionSimilarity is how closely a suggestion's title must match the
+// wiki's title to be used without an exact match. IMDB suggests whatever is
+// popular for a prefix, so its first movie alone is no evidence of a match.
+const minSuggestionSimilarity = 0.8
+
+// imdbSuggestPath returns the suggestion path for a query. IMDB shards the
+// endpoint by the query's first character, with "x" for anything other than
+// an ASCII letter or digit.
+func imdbSuggestPath(query string) string {
+	query = strings.ToLower(strings.TrimSpace(query))
+	shard := "x"
+	if r, _ := utf8.DecodeRuneInString(query); r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+		shard = string(r)
+	}
+	return fmt.Sprintf("/%s/%s.json", shard, url.PathEscape(query))
+}
+
+// pickSuggestion chooses the movie suggestion for a title: an exact title
+// match in the wiki's year, then any exact title match, then the most similar
+// title at or above minSuggestionSimilarity. It returns false if IMDB
+// suggested no movie close enough to the title.
+func pickSuggestion(query matchQuery, suggestions []imdbSuggestion) (imdbSuggestion, bool) {
+	var movies []imdbSuggestion
+	for _, suggestion := range suggestions {
+		if strings.HasPrefix(suggestion.ID, "tt") && imdbSuggestKinds[suggestion.Kind] {
+			movies = append(movies, suggestion)
+		}
+	}
+	if len(movies) == 0 {
+		return imdbSuggestion{}, false
+	}
+
+	title := normalizeTitle(query.Title)
+	var exact []imdbSuggestion
+	for _, movie := range movies {
+		if normalizeTitle(movie.Title) == title {
+			exact = append(exact, movie)
+		}
+	}
+	for _, movie := range exact {
+		if query.Year > 0 && movie.Year == query.Year {
+			return movie, true
+		}
+	}
+	if len(exact) > 0 {
+		return exact[0], true
+	}
+
+	var best imdbSuggestion
+	bestSimilarity := 0.0
+	for _, movie := range movies {
+		if similarity := titleSimilarity(query.Title, movie.Title); similarity > bestSimilarity {
+			best, bestSimilarity = movie, similarity
+		}
+	}
+	if bestSimilarity < minSuggestionSimilarity {
+		return imdbSuggestion{}, false
+	}
+	return best, true
+}
+
+// searchIMDBSuggestions is the -imdb-fallback last resort: it asks IMDB's
+// suggestion endpoint for a candidate IMDB ID and confirms it with TMDB find,
+// so a title TMDB search can't find still resolves to a movie TMDB knows
+func (s *Scraper) searchIMDBSuggestions(title string) (*Movie, error) {
+	req, err := http.NewRequest("GET", s.imdbSuggestURL+imdbSuggestPath(title), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.doRequest(req, endpointIMDBSuggest, title)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query IMDB suggestions for '%s': %w", title, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("IMDB suggestions for '%s' returned status %d", title, resp.StatusCode)
+	}
+
+	var suggestions imdbSuggestionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&suggestions); err != nil {
+		return nil, fmt.Errorf("failed to decode IMDB suggestions: %w", err)
+	}
+
+	suggestion, ok := pickSuggestion(matchQuery{Title: title, Year: s.yearHint(title)}, suggestions.Suggestions)
+	if !ok {
+		return nil, fmt.Errorf("%w for '%s' (IMDB suggested no movie with a similar title)", errNoResults, title)
+	}
+	s.explainf("IMDB suggested %s (%d) %s; confirming with TMDB find", suggestion.Title, suggestion.Year, suggestion.ID)
+
+	movie, err := s.findByIMDBID(suggestion.ID)
+	if err != nil {
+		return nil, err
+	}
+	movie.MatchMethod = matchMethodIMDBSuggestion
+	movie.MatchConfidence = titleSimilarity(title, movie.Title)
+	return movie, nil
+}
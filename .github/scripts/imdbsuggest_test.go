@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestImdbSuggestPath(t *testing.T) {
+	testCases := map[string]string{
+		"Space Jam":      "/s/space%20jam.json",
+		"  'Burbs":       "/x/%27burbs.json",
+		"2001":           "/2/2001.json",
+		"Élite Squadron": "/x/%C3%A9lite%20squadron.json",
+	}
+	for query, expected := range testCases {
+		if got := imdbSuggestPath(query); got != expected {
+			t.Errorf("imdbSuggestPath(%q) = %s, expected %s", query, got, expected)
+		}
+	}
+}
+
+func TestPickSuggestion(t *testing.T) {
+	suggestions := []imdbSuggestion{
+		{ID: "nm0000001", Title: "Ghost", Kind: ""},
+		{ID: "tt0000001", Title: "Ghost Story", Kind: "movie", Year: 1981},
+		{ID: "tt0000002", Title: "Ghost", Kind: "tvSeries", Year: 2019},
+		{ID: "tt0000003", Title: "Ghost", Kind: "movie", Year: 2020},
+		{ID: "tt0099653", Title: "Ghost", Kind: "movie", Year: 1990},
+	}
+
+	if got, ok := pickSuggestion(matchQuery{Title: "Ghost", Year: 1990}, suggestions); !ok || got.ID != "tt0099653" {
+		t.Errorf("Expected the exact title in the wiki's year, got %+v", got)
+	}
+	if got, ok := pickSuggestion(matchQuery{Title: "Ghost"}, suggestions); !ok || got.ID != "tt0000003" {
+		t.Errorf("Expected the first exact title, got %+v", got)
+	}
+	if got, ok := pickSuggestion(matchQuery{Title: "Ghosts"}, suggestions); !ok || got.ID != "tt0000003" {
+		t.Errorf("Expected the most similar title, got %+v", got)
+	}
+	if got, ok := pickSuggestion(matchQuery{Title: "Ghostbusters"}, suggestions); ok {
+		t.Errorf("Expected no pick without a similar title, got %+v", got)
+	}
+	if _, ok := pickSuggestion(matchQuery{Title: "Ghost"}, suggestions[:1]); ok {
+		t.Error("Expected no pick without movie suggestions")
+	}
+}
+
+func TestIMDBFallback(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/f/funky ghost.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"d": [{"id": "tt0099653", "l": "Funky Ghost", "qid": "movie", "y": 1990}], "q": "funky ghost", "v": 1}`))
+	})
+	mux.HandleFunc("/search/movie", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"page": 1, "results": [], "total_pages": 1, "total_results": 0}`))
+	})
+	mux.HandleFunc("/find/tt0099653", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"movie_results": [{"id": 251, "title": "Ghost", "release_date": "1990-07-13"}]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cfg := defaultConfig()
+	cfg.IMDBFallback = true
+	cfg.TMDBBaseURL = server.URL
+	scraper := NewScraper("dummy_key", WithConfig(cfg))
+	scraper.imdbSuggestURL = server.URL
+
+	movie, err := scraper.searchMovie("Funky Ghost")
+	if err != nil {
+		t.Fatalf("Expected the IMDB fallback to resolve the title, got %v", err)
+	}
+	if movie.IMDBID != "tt0099653" || movie.TMDBID != 251 || movie.MatchMethod != matchMethodIMDBSuggestion {
+		t.Errorf("Unexpected movie %+v", movie)
+	}
+	if n := scraper.tmdbRequests; n != 2 {
+		t.Errorf("Expected the search and find to count as TMDB requests, but not the IMDB lookup; got %d", n)
+	}
+
+	// A dissimilar suggestion isn't confirmed with TMDB
+	mux.HandleFunc("/g/ghostbusters.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"d": [{"id": "tt0099653", "l": "Ghost", "qid": "movie", "y": 1990}], "q": "ghostbusters", "v": 1}`))
+	})
+	if _, err := scraper.searchMovie("Ghostbusters"); !errors.Is(err, errNoResults) {
+		t.Errorf("Expected a dissimilar suggestion to be rejected, got %v", err)
+	}
+
+	// Without a suggestion the original error is reported
+	if _, err := scraper.searchMovie("Nothing Like It"); !errors.Is(err, errNoResults) {
+		t.Errorf("Expected no results, got %v", err)
+	}
+
+	cfg.IMDBFallback = false
+	scraper = NewScraper("dummy_key", WithConfig(cfg))
+	scraper.imdbSuggestURL = server.URL
+	if _, err := scraper.searchMovie("Funky Ghost"); !errors.Is(err, errNoResults) {
+		t.Errorf("Expected the fallback to be off by default, got %v", err)
+	}
+}
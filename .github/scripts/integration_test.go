@@ -0,0 +1,417 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mockMovie is a canned TMDB movie served by the mock API
+type mockMovie struct {
+	ID            int
+	Title         string
+	ReleaseDate   string
+	IMDBID        string
+	NoPoster      bool
+	Certification string // US certification served from release_dates
+	Popularity    float64
+	Overview      string
+	Keywords      []string // served from keywords
+}
+
+// mockTMDB serves a wiki page and canned TMDB search and external ID responses
+type mockTMDB struct {
+	*httptest.Server
+	wikiTitles  []string
+	wikiPage    string               // served instead of wikiTitles when set
+	movies      map[string]mockMovie // keyed by search query
+	rateLimited map[string]bool      // queries answered with 429
+	searchHook  func(query string)   // called before each search is answered
+	idStatus    map[int]int          // TMDB IDs whose external IDs answer with an error status
+	totals      map[string]int       // total_results per query, defaulting to the result count
+
+	mu          sync.Mutex
+	flakySearch map[string]int // queries answered with 429 this many more times, then normally
+
+	// release_dates or keywords requests answered with 500 this many more
+	// times, then normally
+	detailFailures map[string]int
+}
+
+// newMockTMDB starts a mock server; it is closed when the test finishes
+func newMockTMDB(t *testing.T, wikiTitles []string, movies []mockMovie) *mockTMDB {
+	t.Helper()
+
+	m := &mockTMDB{
+		wikiTitles:  wikiTitles,
+		movies:      make(map[string]mockMovie),
+		rateLimited: make(map[string]bool),
+		idStatus:    make(map[int]int),
+		totals:      make(map[string]int),
+		flakySearch: make(map[string]int),
+
+		detailFailures: make(map[string]int),
+	}
+	for _, movie := range movies {
+		m.movies[movie.Title] = movie
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wiki", m.serveWiki)
+	mux.HandleFunc("/search/movie", m.serveSearch)
+	mux.HandleFunc("/movie/", m.serveMovie)
+	mux.HandleFunc("/authentication", m.serveAuthentication)
+
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+	return m
+}
+
+func (m *mockTMDB) serveWiki(w http.ResponseWriter, r *http.Request) {
+	if m.wikiPage != "" {
+		w.Write([]byte(m.wikiPage))
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("<html><body><table>")
+	for _, title := range m.wikiTitles {
+		fmt.Fprintf(&b, "<tr><td><i>%s</i></td></tr>", title)
+	}
+	b.WriteString("</table></body></html>")
+	w.Write([]byte(b.String()))
+}
+
+func (m *mockTMDB) serveSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("query")
+	if m.searchHook != nil {
+		m.searchHook(query)
+	}
+	if m.rateLimited[query] || m.takeFlaky(query) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+
+	results := []map[string]interface{}{}
+	if movie, ok := m.movies[query]; ok {
+		posterPath := fmt.Sprintf("/%d.jpg", movie.ID)
+		if movie.NoPoster {
+			posterPath = ""
+		}
+		results = append(results, map[string]interface{}{
+			"id":           movie.ID,
+			"title":        movie.Title,
+			"release_date": movie.ReleaseDate,
+			"poster_path":  posterPath,
+			"popularity":   movie.Popularity,
+			"overview":     movie.Overview,
+		})
+	}
+
+	total, ok := m.totals[query]
+	if !ok {
+		total = len(results)
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"page":          1,
+		"results":       results,
+		"total_pages":   1,
+		"total_results": total,
+	})
+}
+
+// takeFlaky reports whether a search should be rate limited by flakySearch
+func (m *mockTMDB) takeFlaky(query string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.flakySearch[query] == 0 {
+		return false
+	}
+	m.flakySearch[query]--
+	return true
+}
+
+func (m *mockTMDB) serveMovie(w http.ResponseWriter, r *http.Request) {
+	// Path is /movie/{id}/external_ids, /movie/{id}/release_dates or /movie/{id}/keywords
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 3 || (parts[2] != "external_ids" && parts[2] != "release_dates" && parts[2] != "keywords") {
+		http.NotFound(w, r)
+		return
+	}
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if status, ok := m.idStatus[id]; ok {
+		w.WriteHeader(status)
+		return
+	}
+	if m.takeDetailFailure(parts[2]) {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	for _, movie := range m.movies {
+		if movie.ID != id {
+			continue
+		}
+		if parts[2] == "release_dates" {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id": movie.ID,
+				"results": []map[string]interface{}{{
+					"iso_3166_1":    "US",
+					"release_dates": []map[string]interface{}{{"certification": movie.Certification, "type": 3}},
+				}},
+			})
+			return
+		}
+		if parts[2] == "keywords" {
+			keywords := []map[string]interface{}{}
+			for i, name := range movie.Keywords {
+				keywords = append(keywords, map[string]interface{}{"id": i + 1, "name": name})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": movie.ID, "keywords": keywords})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"imdb_id": movie.IMDBID})
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// takeDetailFailure reports whether a details request should fail by
+// detailFailures
+func (m *mockTMDB) takeDetailFailure(detail string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.detailFailures[detail] == 0 {
+		return false
+	}
+	m.detailFailures[detail]--
+	return true
+}
+
+func (m *mockTMDB) serveAuthentication(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("api_key") != "dummy_key" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	w.Write([]byte(`{"success":true}`))
+}
+
+// newTestScraper creates a scraper pointed at the mock server
+func (m *mockTMDB) newTestScraper(cfg Config) *Scraper {
+	cfg.StateFile = ""
+	cfg.TMDBBaseURL = m.URL
+	scraper := NewScraper("dummy_key", WithConfig(cfg))
+	scraper.wikiURL = m.URL + "/wiki"
+	return scraper
+}
+
+var mockCatalog = []mockMovie{
+	{ID: 2300, Title: "Space Jam", ReleaseDate: "1996-11-15", IMDBID: "tt0117705"},
+	{ID: 2907, Title: "The Addams Family", ReleaseDate: "1991-11-22", IMDBID: "tt0101272"},
+	{ID: 251, Title: "Ghost", ReleaseDate: "1990-07-13", IMDBID: "tt0099653"},
+}
+
+func TestGenerateRadarrListHappyPath(t *testing.T) {
+	mock := newMockTMDB(t, []string{"Space Jam", "The Addams Family", "Ghost"}, mockCatalog)
+	scraper := mock.newTestScraper(defaultConfig())
+
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+
+	expected := []Movie{
+		{Title: "Ghost", IMDBID: "tt0099653", TMDBID: 251, Year: 1990},
+		{Title: "Space Jam", IMDBID: "tt0117705", TMDBID: 2300, Year: 1996},
+		{Title: "The Addams Family", IMDBID: "tt0101272", TMDBID: 2907, Year: 1991},
+	}
+	if len(movies) != len(expected) {
+		t.Fatalf("Expected %d movies, got %d: %+v", len(expected), len(movies), movies)
+	}
+	for i, want := range expected {
+		got := movies[i]
+		if got.Title != want.Title || got.IMDBID != want.IMDBID || got.TMDBID != want.TMDBID || got.Year != want.Year {
+			t.Errorf("Position %d: expected %+v, got %+v", i, want, got)
+		}
+		if got.PosterURL == "" {
+			t.Errorf("Position %d: expected a poster URL", i)
+		}
+	}
+	if len(scraper.failures) != 0 {
+		t.Errorf("Expected no failures, got %+v", scraper.failures)
+	}
+}
+
+func TestGenerateRadarrListNotFound(t *testing.T) {
+	mock := newMockTMDB(t, []string{"Space Jam", "A Film TMDB Has Never Heard Of"}, mockCatalog)
+	scraper := mock.newTestScraper(defaultConfig())
+
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+
+	if len(movies) != 1 || movies[0].Title != "Space Jam" {
+		t.Errorf("Expected only Space Jam, got %+v", movies)
+	}
+	if len(scraper.failures) != 1 {
+		t.Fatalf("Expected 1 failure, got %+v", scraper.failures)
+	}
+	failure := scraper.failures[0]
+	if failure.Title != "A Film TMDB Has Never Heard Of" || failure.Category != failureNoResults {
+		t.Errorf("Unexpected failure: %+v", failure)
+	}
+}
+
+func TestGenerateRadarrListRateLimited(t *testing.T) {
+	mock := newMockTMDB(t, []string{"Space Jam", "Ghost"}, mockCatalog)
+	mock.rateLimited["Ghost"] = true
+	scraper := mock.newTestScraper(defaultConfig())
+
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+
+	if len(movies) != 1 || movies[0].Title != "Space Jam" {
+		t.Errorf("Expected only Space Jam, got %+v", movies)
+	}
+	if len(scraper.failures) != 1 {
+		t.Fatalf("Expected 1 failure, got %+v", scraper.failures)
+	}
+	failure := scraper.failures[0]
+	if failure.Title != "Ghost" || failure.Category != failureHTTPError || !strings.Contains(failure.Error, "429") {
+		t.Errorf("Unexpected failure: %+v", failure)
+	}
+}
+
+func TestGenerateRadarrListExternalIDsNotFound(t *testing.T) {
+	mock := newMockTMDB(t, []string{"Space Jam", "Ghost"}, mockCatalog)
+	mock.idStatus[251] = http.StatusNotFound
+
+	// Without -keep-tmdb-only the movie fails as having no IMDB ID, not as an HTTP error
+	scraper := mock.newTestScraper(defaultConfig())
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if len(movies) != 1 || movies[0].Title != "Space Jam" {
+		t.Errorf("Expected only Space Jam, got %+v", movies)
+	}
+	if len(scraper.failures) != 1 || scraper.failures[0].Category != failureNoIMDBID {
+		t.Errorf("Expected a no_imdb_id failure for Ghost, got %+v", scraper.failures)
+	}
+
+	cfg := defaultConfig()
+	cfg.KeepTMDBOnly = true
+	scraper = mock.newTestScraper(cfg)
+	movies, err = scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if len(movies) != 2 || len(scraper.failures) != 0 {
+		t.Fatalf("Expected both movies and no failures, got %+v and %+v", movies, scraper.failures)
+	}
+	ghost := movies[0]
+	if ghost.Title != "Ghost" || ghost.TMDBID != 251 || ghost.IMDBID != "" {
+		t.Errorf("Expected Ghost kept by its TMDB ID, got %+v", ghost)
+	}
+
+	// Other HTTP errors from external_ids still fail the title
+	mock.idStatus[251] = http.StatusInternalServerError
+	scraper = mock.newTestScraper(cfg)
+	if _, err := scraper.generateRadarrList(context.Background()); err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if len(scraper.failures) != 1 || scraper.failures[0].Category != failureHTTPError {
+		t.Errorf("Expected an http_error failure, got %+v", scraper.failures)
+	} else if !strings.Contains(scraper.failures[0].Error, "500") {
+		t.Errorf("Expected the status in the failure, got %s", scraper.failures[0].Error)
+	}
+}
+
+func TestGenerateRadarrListCancelWhileQueued(t *testing.T) {
+	var titles []string
+	for i := 1; i <= 20; i++ {
+		titles = append(titles, fmt.Sprintf("Queued Film %02d", i))
+	}
+	mock := newMockTMDB(t, titles, nil)
+
+	var searches int32
+	started := make(chan struct{}, len(titles))
+	release := make(chan struct{})
+	mock.searchHook = func(query string) {
+		atomic.AddInt32(&searches, 1)
+		started <- struct{}{}
+		<-release
+	}
+
+	scraper := mock.newTestScraper(defaultConfig())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := scraper.generateRadarrList(ctx)
+		done <- err
+	}()
+
+	// Wait for every worker slot to be busy, then cancel with the rest still queued
+	for i := 0; i < 5; i++ {
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for searches to start")
+		}
+	}
+	cancel()
+	close(release)
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the run to stop after cancellation")
+	}
+
+	if got := atomic.LoadInt32(&searches); got != 5 {
+		t.Errorf("Expected only the 5 in-flight searches, got %d", got)
+	}
+}
+
+func TestGenerateRadarrListNoSort(t *testing.T) {
+	mock := newMockTMDB(t, []string{"The Addams Family", "Unknown Movie", "Space Jam", "Ghost"}, mockCatalog)
+
+	cfg := defaultConfig()
+	cfg.NoSort = true
+	cfg.KeepUnmatched = true
+	movies, err := mock.newTestScraper(cfg).generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+
+	var titles []string
+	for _, movie := range movies {
+		titles = append(titles, movie.Title)
+	}
+	expected := []string{"The Addams Family", "Unknown Movie", "Space Jam", "Ghost"}
+	if strings.Join(titles, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected wiki page order %v, got %v", expected, titles)
+	}
+}
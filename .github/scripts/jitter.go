@@ -0,0 +1,46 @@
+package main
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// rateLimitDelay is the pause between requests made by each worker
+const rateLimitDelay = 250 * time.Millisecond
+
+// jitterSource draws random jitter for rate-limit pauses. It is seeded once
+// per run and safe for concurrent use.
+type jitterSource struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func newJitterSource(seed int64) *jitterSource {
+	return &jitterSource{rng: rand.New(rand.NewSource(seed))}
+}
+
+// offset returns a random duration in [-max, +max]
+func (j *jitterSource) offset(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return time.Duration(j.rng.Int63n(int64(2*max)+1)) - max
+}
+
+// WithRandSeed seeds the rate-limit jitter so tests get the same pauses on
+// every run. By default the seed is taken from the current time.
+func WithRandSeed(seed int64) Option {
+	return func(s *Scraper) {
+		s.jitter = newJitterSource(seed)
+	}
+}
+
+// rateLimitPause returns the pause before a worker's next request: the fixed
+// delay plus up to ±-rate-jitter, so workers don't fire in lockstep
+func (s *Scraper) rateLimitPause() time.Duration {
+	return rateLimitDelay + s.jitter.offset(s.config.RateJitter)
+}
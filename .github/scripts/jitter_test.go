@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimitPause(t *testing.T) {
+	if pause := NewScraper("dummy_key").rateLimitPause(); pause != rateLimitDelay {
+		t.Errorf("Expected a fixed %s pause without jitter, got %s", rateLimitDelay, pause)
+	}
+
+	cfg := defaultConfig()
+	cfg.RateJitter = 50 * time.Millisecond
+	scraper := NewScraper("dummy_key", WithConfig(cfg))
+
+	distinct := make(map[time.Duration]bool)
+	for i := 0; i < 200; i++ {
+		pause := scraper.rateLimitPause()
+		if pause < 200*time.Millisecond || pause > 300*time.Millisecond {
+			t.Fatalf("Pause %s is outside 250ms±50ms", pause)
+		}
+		distinct[pause] = true
+	}
+	if len(distinct) < 2 {
+		t.Error("Expected jittered pauses to vary")
+	}
+
+	for _, invalid := range []string{"-1ms", "300ms"} {
+		if _, err := parseFlags([]string{"-rate-jitter", invalid}); err == nil {
+			t.Errorf("Expected -rate-jitter %s to be rejected", invalid)
+		}
+	}
+}
+
+func TestJitterSourceIsDeterministicPerSeed(t *testing.T) {
+	a, b := newJitterSource(42), newJitterSource(42)
+	for i := 0; i < 10; i++ {
+		if a.offset(time.Second) != b.offset(time.Second) {
+			t.Fatal("Expected sources with the same seed to produce the same jitter")
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// TMDBKeywords represents the response from the TMDB movie keywords endpoint
+type TMDBKeywords struct {
+	Keywords []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"keywords"`
+}
+
+// wantsKeywords reports whether movies need their keywords looked up
+func (c Config) wantsKeywords() bool {
+	return c.FetchKeywords || len(c.Keywords) > 0 || len(c.ExcludeKeywords) > 0
+}
+
+// getKeywords fetches a movie's TMDB keywords, e.g. "based on true story".
+// This is one extra request per movie, so it is only made with
+// -fetch-keywords, -keyword or -exclude-keyword.
+func (s *Scraper) getKeywords(tmdbID int) ([]string, error) {
+	apiURL := fmt.Sprintf("%s/movie/%d/keywords", s.tmdbBaseURL, tmdbID)
+
+	params := url.Values{}
+	params.Add("api_key", s.tmdbAPIKey)
+
+	req, err := http.NewRequest("GET", apiURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.doRequest(req, endpointKeywords, strconv.Itoa(tmdbID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get keywords: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &tmdbStatusError{StatusCode: resp.StatusCode, Target: "keywords"}
+	}
+
+	var keywords TMDBKeywords
+	if err := json.NewDecoder(resp.Body).Decode(&keywords); err != nil {
+		return nil, fmt.Errorf("failed to decode keywords response: %w", err)
+	}
+
+	names := make([]string, 0, len(keywords.Keywords))
+	for _, keyword := range keywords.Keywords {
+		names = append(names, normalizeKeyword(keyword.Name))
+	}
+	return names, nil
+}
+
+// withKeywords returns the movies passing -keyword and -exclude-keyword,
+// keeping unmatched placeholders and movies carried over from the previous
+// list without saved keywords, along with the number of movies dropped. A
+// movie is kept if it has any of the wanted keywords, when there are any, and
+// none of the excluded ones.
+func withKeywords(movies []Movie, wanted, excluded []string) ([]Movie, int) {
+	kept := make([]Movie, 0, len(movies))
+	for _, movie := range movies {
+		if movie.IsPlaceholder() || (movie.carried && len(movie.Keywords) == 0) || ((len(wanted) == 0 || hasAnyKeyword(movie, wanted)) && !hasAnyKeyword(movie, excluded)) {
+			kept = append(kept, movie)
+		}
+	}
+	return kept, len(movies) - len(kept)
+}
+
+// hasAnyKeyword reports whether a movie is tagged with one of the keywords
+func hasAnyKeyword(movie Movie, keywords []string) bool {
+	for _, keyword := range movie.Keywords {
+		for _, want := range keywords {
+			if keyword == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normalizeKeyword lower-cases a keyword so filters match TMDB's names
+// regardless of how they were typed
+func normalizeKeyword(keyword string) string {
+	return strings.ToLower(strings.TrimSpace(keyword))
+}
+
+// keywordListValue is a flag.Value collecting comma-separated keywords, which
+// may also be given by repeating the flag
+type keywordListValue []string
+
+func (k *keywordListValue) String() string {
+	return strings.Join(*k, ",")
+}
+
+func (k *keywordListValue) Set(value string) error {
+	for _, keyword := range strings.Split(value, ",") {
+		if keyword = normalizeKeyword(keyword); keyword != "" {
+			*k = append(*k, keyword)
+		}
+	}
+	return nil
+}
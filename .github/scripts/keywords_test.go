@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWithKeywords(t *testing.T) {
+	movies := []Movie{
+		{Title: "Space Jam", Keywords: []string{"basketball", "live action and animation"}},
+		{Title: "The Addams Family", Keywords: []string{"based on comic", "family"}},
+		{Title: "Ghost", Keywords: []string{"ghost", "afterlife"}},
+		{Title: "Dune"},
+		newPlaceholder("Unknown Movie"),
+	}
+
+	kept, dropped := withKeywords(movies, []string{"family", "ghost"}, nil)
+	if dropped != 2 || len(kept) != 3 || kept[0].Title != "The Addams Family" || kept[1].Title != "Ghost" || !kept[2].IsPlaceholder() {
+		t.Errorf("Expected The Addams Family, Ghost and the placeholder, got %+v (%d)", kept, dropped)
+	}
+
+	kept, dropped = withKeywords(movies, nil, []string{"afterlife"})
+	if dropped != 1 || len(kept) != 4 {
+		t.Errorf("Expected only Ghost to be dropped, got %+v (%d)", kept, dropped)
+	}
+
+	// An exclusion wins over a wanted keyword
+	kept, _ = withKeywords(movies, []string{"ghost"}, []string{"afterlife"})
+	if len(kept) != 1 || !kept[0].IsPlaceholder() {
+		t.Errorf("Expected only the placeholder, got %+v", kept)
+	}
+}
+
+func TestFetchKeywords(t *testing.T) {
+	catalog := append([]mockMovie{}, mockCatalog...)
+	catalog[0].Keywords = []string{"Basketball", "sports"} // Space Jam
+	catalog[2].Keywords = []string{"afterlife"}            // Ghost
+	mock := newMockTMDB(t, []string{"Space Jam", "The Addams Family", "Ghost"}, catalog)
+
+	cfg := defaultConfig()
+	scraper := mock.newTestScraper(cfg)
+	if _, err := scraper.generateRadarrList(context.Background()); err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	for _, summary := range scraper.latency.summaries() {
+		if summary.Endpoint == endpointKeywords {
+			t.Errorf("Expected no keywords requests by default, got %d", summary.Count)
+		}
+	}
+
+	cfg, err := parseFlags([]string{"-exclude-keyword", "Afterlife", "-keyword", "basketball,afterlife"})
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	scraper = mock.newTestScraper(cfg)
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if len(movies) != 1 || !reflect.DeepEqual(movies[0].Keywords, []string{"basketball", "sports"}) {
+		t.Fatalf("Expected only Space Jam with its keywords, got %+v", movies)
+	}
+
+	// The keywords are only written out with -fetch-keywords
+	if prepared := scraper.prepareForOutput(movies); prepared[0].Keywords != nil {
+		t.Errorf("Expected keywords to be left out without -fetch-keywords, got %+v", prepared[0])
+	}
+	scraper.config.FetchKeywords = true
+	if prepared := scraper.prepareForOutput(movies); len(prepared[0].Keywords) != 2 {
+		t.Errorf("Expected keywords in the output with -fetch-keywords, got %+v", prepared[0])
+	}
+}
+
+func TestFetchKeywordsFailure(t *testing.T) {
+	mock := newMockTMDB(t, []string{"Space Jam", "Ghost"}, mockCatalog)
+	mock.detailFailures["keywords"] = 1
+
+	cfg, err := parseFlags([]string{"-exclude-keyword", "afterlife"})
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	scraper := mock.newTestScraper(cfg)
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+
+	// The movie whose keywords failed to load is reported, not kept unfiltered
+	if len(movies) != 1 {
+		t.Errorf("Expected one movie to survive, got %+v", movies)
+	}
+	if len(scraper.failures) != 1 || scraper.failures[0].Category != failureMetadata {
+		t.Errorf("Expected one metadata_error failure, got %+v", scraper.failures)
+	}
+
+	// A transient failure is retried like a search
+	mock = newMockTMDB(t, []string{"Space Jam"}, mockCatalog)
+	mock.detailFailures["keywords"] = 1
+	cfg.Retries, cfg.RetryBackoff = 1, time.Millisecond
+	scraper = mock.newTestScraper(cfg)
+	movies, err = scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if len(movies) != 1 || len(scraper.failures) != 0 {
+		t.Errorf("Expected the retry to recover, got %+v and failures %+v", movies, scraper.failures)
+	}
+}
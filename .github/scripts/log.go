@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Log levels accepted by -log-level
+const (
+	logLevelDebug = "debug"
+	logLevelInfo  = "info"
+)
+
+// WithLogOutput sends the scraper's progress and summary messages to w.
+// Titles resolved in parallel log at the same time, so w is guarded by a
+// lock and needn't be safe for concurrent use itself.
+func WithLogOutput(w io.Writer) Option {
+	return func(s *Scraper) {
+		s.logOut = &lockedWriter{w: w}
+	}
+}
+
+// lockedWriter serializes writes to a writer
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// logWriter returns where progress and summary messages go: the writer given
+// with WithLogOutput, or else the standard stream chosen by the config
+func (s *Scraper) logWriter() io.Writer {
+	if s.logOut != nil {
+		return s.logOut
+	}
+	return s.config.logWriter()
+}
+
+// logWriter returns the standard stream for progress and summary messages:
+// standard error with -stdout, so the list is the only thing on standard
+// output, and standard output otherwise
+func (c Config) logWriter() io.Writer {
+	if c.Stdout {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// debugf prints a message only when -log-level is debug
+func (s *Scraper) debugf(format string, args ...interface{}) {
+	if s.config.LogLevel != logLevelDebug {
+		return
+	}
+	fmt.Fprintf(s.logWriter(), "[debug] "+format+"\n", args...)
+}
+
+// titlef prints a per-title progress line, unless -progress is drawing a
+// progress bar in their place
+func (s *Scraper) titlef(format string, args ...interface{}) {
+	if s.config.Progress {
+		return
+	}
+	fmt.Fprintf(s.logWriter(), format, args...)
+}
+
+// logCandidates records a title's chosen candidate and the highest-ranked
+// rejected ones at debug level, capped by -log-candidates. It is printed as
+// one message so titles resolved in parallel don't interleave.
+func (s *Scraper) logCandidates(query matchQuery, match TMDBMovie, candidates []TMDBMovie) {
+	if s.config.LogCandidates <= 0 {
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "candidates for %q: chose %s", query.Title, describeCandidate(query.Title, match))
+	logged := 0
+	for _, candidate := range candidates {
+		if candidate.ID == match.ID {
+			continue
+		}
+		if logged == s.config.LogCandidates {
+			fmt.Fprintf(&b, "\n  ... %d more rejected", len(candidates)-1-logged)
+			break
+		}
+		fmt.Fprintf(&b, "\n  rejected %s", describeCandidate(query.Title, candidate))
+		logged++
+	}
+	s.debugf("%s", b.String())
+}
+
+// describeCandidate formats a search result with the scores the match
+// strategies use
+func describeCandidate(title string, candidate TMDBMovie) string {
+	return fmt.Sprintf("%s (%s) tmdb=%d similarity=%.2f popularity=%.1f votes=%d",
+		candidate.Title, candidateYear(candidate), candidate.ID,
+		titleSimilarity(title, candidate.Title), candidate.Popularity, candidate.VoteCount)
+}
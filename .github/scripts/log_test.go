@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLogOutput(t *testing.T) {
+	mock := newMockTMDB(t, []string{"Space Jam", "Ghost"}, mockCatalog)
+	scraper := mock.newTestScraper(defaultConfig())
+	// A plain builder: the workers' concurrent writes are serialized for it
+	var log strings.Builder
+	WithLogOutput(&log)(scraper)
+
+	stdout := captureStdout(t, func() {
+		if _, err := scraper.generateRadarrList(context.Background()); err != nil {
+			t.Errorf("Failed to generate list: %v", err)
+		}
+	})
+
+	if stdout != "" {
+		t.Errorf("Expected nothing on standard output, got:\n%s", stdout)
+	}
+	if !strings.Contains(log.String(), "Summary:") {
+		t.Errorf("Expected the summary in the log output, got:\n%s", log.String())
+	}
+
+	if w := (Config{Stdout: true}).logWriter(); w != os.Stderr {
+		t.Error("Expected messages on standard error with -stdout")
+	}
+	if w := defaultConfig().logWriter(); w != os.Stdout {
+		t.Error("Expected messages on standard output by default")
+	}
+}
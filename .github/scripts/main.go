@@ -1,17 +1,25 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/joho/godotenv"
@@ -19,24 +27,97 @@ import (
 
 // Movie represents a movie with its metadata
 type Movie struct {
-	Title     string `json:"title"`
-	IMDBID    string `json:"imdb_id"`
-	PosterURL string `json:"poster_url"`
-	Year      int    `json:"year"`
+	Title         string   `json:"title"`
+	OriginalTitle string   `json:"original_title,omitempty"`
+	IMDBID        string   `json:"imdb_id"`
+	TMDBID        int      `json:"tmdb_id,omitempty"`
+	PosterURL     string   `json:"poster_url,omitempty"`
+	Year          int      `json:"year,omitempty"`
+	Genres        []string `json:"genres,omitempty"`
+	Matched       *bool    `json:"matched,omitempty"`
+
+	// US certification (e.g. PG-13), emitted only with -fetch-certification
+	Certification string `json:"certification,omitempty"`
+
+	// Guest who picked the movie, emitted only with -include-guest
+	Guest string `json:"guest,omitempty"`
+
+	// TMDB keywords, emitted only with -fetch-keywords
+	Keywords []string `json:"keywords,omitempty"`
+
+	// Title text as scraped from the wiki, before cleanup, emitted only
+	// with -include-raw-title
+	RawTitle string `json:"raw_title,omitempty"`
+
+	// Air dates of every episode the movie was picked on, YYYY-MM-DD,
+	// emitted only with -merge-episodes
+	Episodes []string `json:"episodes,omitempty"`
+
+	// Manual marks an entry added by hand; -merge keeps it across runs
+	Manual bool `json:"manual,omitempty"`
+
+	// Match quality, emitted only with -include-match-info
+	MatchConfidence float64 `json:"match_confidence,omitempty"`
+	MatchMethod     string  `json:"match_method,omitempty"`
+	SearchResults   int     `json:"search_results,omitempty"`
+
+	// TMDB popularity score, emitted only with -include-popularity
+	Popularity float64 `json:"popularity,omitempty"`
+
+	// TMDB synopsis, emitted only with -include-overview
+	Overview string `json:"overview,omitempty"`
+
+	// Hash of the fields above, used to spot in-place metadata changes
+	// between runs
+	Hash string `json:"hash,omitempty"`
+
+	// position is the title's place in wiki page order, used by -no-sort
+	position int
+
+	// lastAired is the newest episode the movie was picked on, used by
+	// -sort episode-desc
+	lastAired time.Time
+
+	// episodeTitles are the titles of the episodes the movie was picked on,
+	// used by -exclude-episode-pattern
+	episodeTitles []string
+
+	// carried marks a movie kept from the previous list. Its saved entry only
+	// has popularity, certification and keywords if that run emitted them, so
+	// the filters on those let it through rather than judge missing data.
+	carried bool
+}
+
+// IsPlaceholder reports whether the movie stands in for an unmatched title
+func (m Movie) IsPlaceholder() bool {
+	return m.Matched != nil && !*m.Matched
+}
+
+// newPlaceholder creates an entry for a title that could not be matched
+func newPlaceholder(title string) Movie {
+	matched := false
+	return Movie{Title: title, Matched: &matched}
 }
 
 // TMDBResponse represents the response from TMDB API
 type TMDBResponse struct {
-	Results []TMDBMovie `json:"results"`
+	Page         int         `json:"page"`
+	Results      []TMDBMovie `json:"results"`
+	TotalPages   int         `json:"total_pages"`
+	TotalResults int         `json:"total_results"`
 }
 
 // TMDBMovie represents a movie from TMDB API
 type TMDBMovie struct {
-	ID          int       `json:"id"`
-	Title       string    `json:"title"`
-	PosterPath  string    `json:"poster_path"`
-	ReleaseDate time.Time `json:"release_date"`
-	GenreIDs    []int     `json:"genre_ids"`
+	ID            int       `json:"id"`
+	Title         string    `json:"title"`
+	OriginalTitle string    `json:"original_title"`
+	PosterPath    string    `json:"poster_path"`
+	ReleaseDate   time.Time `json:"release_date"`
+	GenreIDs      []int     `json:"genre_ids"`
+	Popularity    float64   `json:"popularity"`
+	VoteCount     int       `json:"vote_count"`
+	Overview      string    `json:"overview"`
 }
 
 // UnmarshalJSON custom unmarshaler for TMDBMovie to handle release date string
@@ -64,6 +145,11 @@ func (m *TMDBMovie) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// TMDBFindResponse represents the response from the TMDB find endpoint
+type TMDBFindResponse struct {
+	MovieResults []TMDBMovie `json:"movie_results"`
+}
+
 // TMDBExternalIDs represents external IDs from TMDB API
 type TMDBExternalIDs struct {
 	IMDBID string `json:"imdb_id"`
@@ -97,27 +183,126 @@ type Scraper struct {
 	tmdbAPIKey string
 	client     *http.Client
 	wikiURL    string
+	wikiCookie string // Cookie header for wiki requests, from -cookie
+	resolved   map[string]resolvedEntry // titles reused from -resolved-set
+	checkpoint *checkpoint
 	tmdbBaseURL string
+	imdbSuggestURL string
+	config     Config
+	wikiState  *wikiState
+	failures   []Failure
+	ambiguous  []AmbiguousMatch
+	overrides  map[string]string
+	skipTitles map[string]bool
+	genreAliases map[string]string
+	latency    *latencyStats
+	cleanup    []cleanupStep
+	dropCounts map[string]int
+
+	// metaMu guards the per-title wiki metadata below, which -stream-titles
+	// records while the TMDB workers are already running
+	metaMu     sync.Mutex
+	airDates   map[string]time.Time
+	episodeDates map[string][]string
+	episodeTitles map[string][]string
+	guests     map[string]string
+	rawTitles  map[string]string
+	yearHints  map[string]int
+	jitter     *jitterSource
+	now        func() time.Time
+	sinks      []Sink
+	requestLog *requestLog
+	candidateDump *candidateDump
+	cache      *resolveCache
+	tvMatches  *tvMatches
+	previous   []Movie
+	pruned     []Movie
+	carried    int
+	runID      string
+	summary    RunSummary // the final summary, once the titles have resolved
+	logOut     io.Writer  // progress and summary messages, from WithLogOutput
+
+	tmdbRequests int64 // accessed atomically
+
+	breakersMu sync.Mutex
+	breakers   map[string]*circuitBreaker // by host
 }
 
 // NewScraper creates a new scraper instance
-func NewScraper(apiKey string) *Scraper {
-	return &Scraper{
+func NewScraper(apiKey string, opts ...Option) *Scraper {
+	s := &Scraper{
 		tmdbAPIKey:  apiKey,
 		client:      &http.Client{Timeout: 30 * time.Second},
 		wikiURL:     "https://comedybangbang.fandom.com/wiki/Scott_Hasn%27t_Seen",
-		tmdbBaseURL: "https://api.themoviedb.org/3",
+		tmdbBaseURL: defaultTMDBBaseURL,
+		imdbSuggestURL: defaultIMDBSuggestURL,
+		config:      defaultConfig(),
+		latency:     newLatencyStats(),
+		cleanup:     defaultCleanupPipeline,
+		dropCounts:  make(map[string]int),
+		airDates:    make(map[string]time.Time),
+		episodeDates: make(map[string][]string),
+		episodeTitles: make(map[string][]string),
+		guests:      make(map[string]string),
+		rawTitles:   make(map[string]string),
+		yearHints:   make(map[string]int),
+		jitter:      newJitterSource(time.Now().UnixNano()),
+		now:         time.Now,
+		cache:       newResolveCache(),
+		tvMatches:   newTVMatches(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.runID == "" {
+		s.runID = newRunID()
+	}
+	s.sinks = append(s.defaultSinks(), s.sinks...)
+	if s.config.NoFollowRedirects {
+		s.client.CheckRedirect = s.stopWikiRedirects
+	}
+	if len(s.config.HostLimits) > 0 {
+		s.client.Transport = newHostLimiter(s.client.Transport, s.config.HostLimits)
+	}
+	return s
+}
+
+// Close releases resources held by the scraper, such as idle HTTP connections.
+// It is safe to call more than once and on a scraper that holds no resources.
+func (s *Scraper) Close() error {
+	if s.client != nil {
+		s.client.CloseIdleConnections()
 	}
+	return nil
 }
 
-// scrapeWikiPage fetches the Scott Hasn't Seen wiki page
+// scrapeWikiPage fetches the Scott Hasn't Seen wiki page.
+// It sends the validators from the previous run and returns errWikiNotModified
+// on a 304 response unless -force is set.
 func (s *Scraper) scrapeWikiPage() (string, error) {
-	resp, err := s.client.Get(s.wikiURL)
+	req, err := http.NewRequest("GET", s.wikiURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if !s.config.Force {
+		state, err := loadWikiState(s.config.StateFile)
+		if err != nil {
+			fmt.Fprintf(s.logWriter(), "Ignoring wiki state: %v\n", err)
+		}
+		state.applyTo(req)
+	}
+
+	resp, err := s.doRequest(req, endpointWiki, s.wikiURL)
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch wiki page: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified {
+		return "", errWikiNotModified
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return "", fmt.Errorf("wiki page returned status: %d", resp.StatusCode)
 	}
@@ -127,70 +312,218 @@ func (s *Scraper) scrapeWikiPage() (string, error) {
 		return "", fmt.Errorf("failed to parse HTML: %w", err)
 	}
 
+	state := wikiStateFrom(resp)
+	s.wikiState = &state
+
+	// Closing the first page frees its -host-limit slot for the next page
+	resp.Body.Close()
+	if err := s.followPagination(doc); err != nil {
+		return "", err
+	}
+
 	return doc.Html()
 }
 
 // extractMovieTitles extracts movie titles from the HTML content
 func (s *Scraper) extractMovieTitles(htmlContent string) ([]string, error) {
+	var movies []string
+	err := s.eachMovieTitle(htmlContent, func(title string) {
+		movies = append(movies, title)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return movies, nil
+}
+
+// eachMovieTitle calls emit with each movie title in the HTML content, in page
+// order, as soon as it has been cleaned and filtered
+func (s *Scraper) eachMovieTitle(htmlContent string, emit func(title string)) error {
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+		return fmt.Errorf("failed to parse HTML: %w", err)
 	}
 
-	var movies []string
-	seen := make(map[string]bool)
+	seen := make(titleYears)
+	headerEpisodes := s.headerEpisodes(doc)
 
 	// Find all italicized text (movie titles)
-	doc.Find("i").Each(func(i int, s *goquery.Selection) {
-		title := strings.TrimSpace(s.Text())
+	doc.Find("i").Each(func(i int, sel *goquery.Selection) {
+		cleaned := s.cleanTitle(sel.Text())
+		year := releaseYearHint(sel.Text())
+
+		// Same-title entries with different years are different films
+		title, isNew := seen.key(cleaned, year)
+		s.recordYearHint(title, year)
+		if airDate, ok := rowAirDate(sel); ok {
+			s.recordAirDate(title, airDate)
+		}
+		if guest, ok := rowGuest(sel); ok {
+			s.recordGuest(title, guest)
+		}
+		if episode, ok := s.titleEpisode(sel, headerEpisodes); ok {
+			s.recordEpisodeTitle(title, episode)
+		}
+		s.recordRawTitle(title, sel.Text())
 		
 		// Skip if already seen
-		if seen[title] {
+		if !isNew {
+			s.recordDrop(title, dropRuleDuplicate, "")
 			return
 		}
-		seen[title] = true
 
-		// Skip very short titles
-		if len(title) < 3 {
+		if rule, detail := dropReason(cleaned); rule != "" {
+			s.recordDrop(title, rule, detail)
 			return
 		}
-
-		// Skip non-movie entries
-		skipKeywords := []string{
-			"cobra kai", "season", "episodes", "pilot", "watchalong",
-			"awards", "the scott hasn't seenies", "march of the penguins",
-			"september 5", "twin peaks", "martin", "sprague hasn't seen",
-			"did", "next", "the scott hasn't seenies awards",
-			"scott hasn't seen", // Add the podcast name itself
+		if s.skipTitles[normalizeTitle(cleaned)] {
+			s.recordDrop(title, dropRuleSkipList, "")
+			return
 		}
 
-		titleLower := strings.ToLower(title)
-		for _, keyword := range skipKeywords {
-			if strings.Contains(titleLower, keyword) {
-				return
-			}
-		}
+		emit(title)
+	})
 
-		// Skip if contains episode/season patterns
-		episodePattern := regexp.MustCompile(`(?i)episode|season|part \d+`)
-		if episodePattern.MatchString(title) {
-			return
-		}
+	return nil
+}
 
-		// Skip single words that are too short
-		words := strings.Fields(title)
-		if len(words) <= 1 && len(title) < 4 {
-			return
+// Rules that drop scraped entries before TMDB lookup
+const (
+	dropRuleDuplicate       = "duplicate"
+	dropRuleTooShort        = "too-short"
+	dropRuleSkipKeyword     = "skip-keyword"
+	dropRuleEpisodePattern  = "episode-pattern"
+	dropRuleShortSingleWord = "short-single-word"
+	dropRuleSkipList        = "skip-list"
+)
+
+// skipKeywords mark non-movie entries
+var skipKeywords = []string{
+	"cobra kai", "season", "episodes", "pilot", "watchalong",
+	"awards", "the scott hasn't seenies", "march of the penguins",
+	"september 5", "twin peaks", "martin", "sprague hasn't seen",
+	"did", "next", "the scott hasn't seenies awards",
+	"scott hasn't seen", // Add the podcast name itself
+}
+
+// episodePattern matches episode/season references
+var episodePattern = regexp.MustCompile(`(?i)episode|season|part \d+`)
+
+// dropReason returns the rule that excludes a title from the movie list, with
+// a detail such as the matching keyword, or an empty rule if the title is kept
+func dropReason(title string) (rule, detail string) {
+	// Skip very short titles
+	if len(title) < 3 {
+		return dropRuleTooShort, ""
+	}
+
+	// Skip non-movie entries
+	titleLower := strings.ToLower(title)
+	for _, keyword := range skipKeywords {
+		if strings.Contains(titleLower, keyword) {
+			return dropRuleSkipKeyword, keyword
 		}
+	}
 
-		movies = append(movies, title)
-	})
+	// Skip if contains episode/season patterns
+	if match := episodePattern.FindString(title); match != "" {
+		return dropRuleEpisodePattern, match
+	}
 
-	return movies, nil
+	// Skip single words that are too short
+	words := strings.Fields(title)
+	if len(words) <= 1 && len(title) < 4 {
+		return dropRuleShortSingleWord, ""
+	}
+
+	return "", ""
+}
+
+// recordDrop logs a dropped title at debug level and counts it by rule
+func (s *Scraper) recordDrop(title, rule, detail string) {
+	if detail != "" {
+		s.debugf("dropped %q (%s: %q)", title, rule, detail)
+	} else {
+		s.debugf("dropped %q (%s)", title, rule)
+	}
+	s.dropCounts[rule]++
+}
+
+// printDropCounts prints how many scraped entries each filter rule dropped
+func (s *Scraper) printDropCounts() {
+	if len(s.dropCounts) == 0 {
+		return
+	}
+
+	rules := make([]string, 0, len(s.dropCounts))
+	for rule := range s.dropCounts {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+
+	fmt.Fprintf(s.logWriter(), "\nDropped by filter:\n")
+	for _, rule := range rules {
+		fmt.Fprintf(s.logWriter(), "  %-18s %d\n", rule, s.dropCounts[rule])
+	}
 }
 
 // searchMovie searches for a movie on TMDB
 func (s *Scraper) searchMovie(title string) (*Movie, error) {
+	// A title that cleans to nothing would search TMDB for an empty query
+	if strings.TrimSpace(title) == "" {
+		return nil, errEmptyTitle
+	}
+
+	// An overridden IMDB ID resolves the movie directly, skipping title search
+	if imdbID, ok := s.overrideFor(title); ok {
+		s.explainf("Override: resolving %s with TMDB find instead of searching", imdbID)
+		movie, err := s.findByIMDBID(imdbID)
+		if err != nil {
+			return nil, err
+		}
+		movie.MatchMethod = matchMethodOverride
+		movie.MatchConfidence = 1
+		return movie, nil
+	}
+
+	// A title longer than any film's is an extraction bug, not worth a search
+	if length := utf8.RuneCountInString(title); s.config.MaxQueryLength > 0 && length > s.config.MaxQueryLength {
+		return nil, fmt.Errorf("%w (%d characters, limit %d)", errSuspiciousLength, length, s.config.MaxQueryLength)
+	}
+
+	if cached, ok := s.cache.title(title); ok {
+		s.debugf("cache hit for %q", title)
+		s.explainf("Cache hit: %s", describeMovie(cached))
+		return &cached, nil
+	}
+
+	movie, err := s.searchMovieUncached(title)
+	if errors.Is(err, errNoResults) {
+		if variant, variantErr := s.searchVariants(title); variantErr == nil {
+			movie, err = variant, nil
+		} else if !errors.Is(variantErr, errNoResults) {
+			err = variantErr
+		}
+	}
+	if errors.Is(err, errNoResults) && s.config.IMDBFallback {
+		s.explainf("No results; asking IMDB for suggestions")
+		if suggested, suggestErr := s.searchIMDBSuggestions(title); suggestErr == nil {
+			movie, err = suggested, nil
+		} else {
+			s.debugf("IMDB fallback for %q failed: %v", title, suggestErr)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	movie.MatchConfidence = penalizedConfidence(movie.MatchMethod, movie.MatchConfidence)
+	s.cache.storeTitle(title, *movie)
+	return movie, nil
+}
+
+// searchMovieUncached resolves a title with TMDB title searches
+func (s *Scraper) searchMovieUncached(title string) (*Movie, error) {
 	// Handle special cases with "/" in titles
 	if strings.Contains(title, "/") {
 		// Try the full title first
@@ -204,44 +537,126 @@ func (s *Scraper) searchMovie(title string) (*Movie, error) {
 		if len(parts) > 0 {
 			firstPart := strings.TrimSpace(parts[0])
 			if firstPart != "" {
+				s.explainf("Full title failed; trying the part before \"/\": %q", firstPart)
 				movie, err := s.searchMovieExact(firstPart)
 				if err == nil {
+					movie.MatchMethod = matchMethodSlashSplit
 					return movie, nil
 				}
 			}
 		}
 		
 		// If splitting fails, return the original error
-		return nil, fmt.Errorf("no results found for '%s' (tried full title and first part)", title)
+		return nil, fmt.Errorf("%w for '%s' (tried full title and first part)", errNoResults, title)
 	}
 	
 	return s.searchMovieExact(title)
 }
 
-// searchMovieExact searches for a movie on TMDB with exact title
+// searchMovieExact searches for a movie on TMDB with exact title. A year
+// added to tell same-title films apart is left out of the query and used as
+// the year hint instead; a match it decided has the year-constrained match
+// method.
 func (s *Scraper) searchMovieExact(title string) (*Movie, error) {
+	query := stripYear(title)
+	candidates, totalResults, err := s.searchCandidates(query)
+	if err != nil {
+		return nil, err
+	}
+	s.explainCandidates(query, candidates, totalResults)
+	match := matchQuery{Title: query, Year: s.yearHint(title)}
+
+	if len(candidates) == 0 {
+		s.dumpCandidates(title, match, totalResults, 0, nil)
+		return nil, fmt.Errorf("%w for '%s'", errNoResults, title)
+	}
+
+	movie, confirmed := s.selectMatch(match, candidates)
+	s.dumpCandidates(title, match, totalResults, movie.ID, candidates)
+	
+	// Get IMDB ID
+	imdbID, err := s.getIMDBID(movie.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get IMDB ID for '%s': %w", title, err)
+	}
+
+	result := s.newMovie(movie, imdbID)
+	result.MatchConfidence = titleSimilarity(query, movie.Title)
+	if confirmed {
+		result.MatchConfidence = 1
+	}
+	result.SearchResults = totalResults
+	result.MatchMethod = matchMethodExact
+	if yearConstrained(match, candidates, movie) {
+		result.MatchMethod = matchMethodYearConstrained
+	}
+	return result, nil
+}
+
+// searchCandidates collects search results from up to -search-pages pages,
+// along with the total number of results TMDB reported for the title. With
+// -multi-search only the movie results are candidates; close TV results are
+// surfaced for review.
+func (s *Scraper) searchCandidates(title string) ([]TMDBMovie, int, error) {
+	var candidates []TMDBMovie
+	var shows []TMDBTVShow
+	totalResults := 0
+
+	for page := 1; page <= s.config.SearchPages; page++ {
+		if page > 1 {
+			// Rate limiting for the extra page requests
+			time.Sleep(s.rateLimitPause())
+		}
+
+		var tmdbResp *TMDBResponse
+		var err error
+		if s.config.MultiSearch {
+			var pageShows []TMDBTVShow
+			tmdbResp, pageShows, err = s.searchMultiPage(title, page)
+			shows = append(shows, pageShows...)
+		} else {
+			tmdbResp, err = s.searchPage(title, page)
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+
+		candidates = append(candidates, tmdbResp.Results...)
+		totalResults = tmdbResp.TotalResults
+
+		if page >= tmdbResp.TotalPages {
+			break
+		}
+	}
+
+	s.reviewTVResults(title, shows, len(candidates))
+	return candidates, totalResults, nil
+}
+
+// searchPage fetches a single page of TMDB search results
+func (s *Scraper) searchPage(title string, page int) (*TMDBResponse, error) {
 	searchURL := fmt.Sprintf("%s/search/movie", s.tmdbBaseURL)
 	
 	params := url.Values{}
 	params.Add("api_key", s.tmdbAPIKey)
 	params.Add("query", title)
-	params.Add("language", "en-US")
-	params.Add("page", "1")
-	params.Add("include_adult", "false")
+	params.Add("language", s.config.Language)
+	params.Add("page", strconv.Itoa(page))
+	params.Add("include_adult", strconv.FormatBool(s.config.IncludeAdult))
 
 	req, err := http.NewRequest("GET", searchURL+"?"+params.Encode(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := s.client.Do(req)
+	resp, err := s.doRequest(req, endpointSearch, title)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search movie '%s': %w", title, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TMDB API returned status %d for '%s'", resp.StatusCode, title)
+		return nil, &tmdbStatusError{StatusCode: resp.StatusCode, Target: fmt.Sprintf("'%s'", title)}
 	}
 
 	var tmdbResp TMDBResponse
@@ -249,37 +664,82 @@ func (s *Scraper) searchMovieExact(title string) (*Movie, error) {
 		return nil, fmt.Errorf("failed to decode TMDB response: %w", err)
 	}
 
-	if len(tmdbResp.Results) == 0 {
-		return nil, fmt.Errorf("no results found for '%s'", title)
+	return &tmdbResp, nil
+}
+
+// findByIMDBID resolves a movie from a known IMDB ID using the TMDB find endpoint
+func (s *Scraper) findByIMDBID(imdbID string) (*Movie, error) {
+	apiURL := fmt.Sprintf("%s/find/%s", s.tmdbBaseURL, url.PathEscape(imdbID))
+
+	params := url.Values{}
+	params.Add("api_key", s.tmdbAPIKey)
+	params.Add("external_source", "imdb_id")
+	params.Add("language", s.config.Language)
+
+	req, err := http.NewRequest("GET", apiURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	movie := tmdbResp.Results[0]
-	
-	// Get IMDB ID
-	imdbID, err := s.getIMDBID(movie.ID)
+	resp, err := s.doRequest(req, endpointFind, imdbID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get IMDB ID for '%s': %w", title, err)
+		return nil, fmt.Errorf("failed to find movie '%s': %w", imdbID, err)
 	}
+	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, &tmdbStatusError{StatusCode: resp.StatusCode, Target: fmt.Sprintf("'%s'", imdbID)}
+	}
+
+	var findResp TMDBFindResponse
+	if err := json.NewDecoder(resp.Body).Decode(&findResp); err != nil {
+		return nil, fmt.Errorf("failed to decode TMDB find response: %w", err)
+	}
+
+	if len(findResp.MovieResults) == 0 {
+		return nil, fmt.Errorf("%w for IMDB ID '%s'", errNoResults, imdbID)
+	}
+
+	return s.newMovie(findResp.MovieResults[0], imdbID), nil
+}
+
+// newMovie builds the output entry for a TMDB movie
+func (s *Scraper) newMovie(movie TMDBMovie, imdbID string) *Movie {
 	posterURL := ""
 	if movie.PosterPath != "" {
 		posterURL = fmt.Sprintf("https://www.themoviedb.org/t/p/w300_and_h450_bestv2%s", movie.PosterPath)
 	}
 
+	title := movie.Title
+	if s.config.PreferOriginalTitle && movie.OriginalTitle != "" {
+		title = movie.OriginalTitle
+	}
+
+	// TMDB sends an empty release date for unreleased and obscure movies
+	year := 0
+	if !movie.ReleaseDate.IsZero() {
+		year = movie.ReleaseDate.Year()
+	}
+
 	return &Movie{
-		Title:     movie.Title,
-		IMDBID:    imdbID,
-		PosterURL: posterURL,
-		Year:      movie.ReleaseDate.Year(),
-	}, nil
+		Title:         title,
+		OriginalTitle: movie.OriginalTitle,
+		IMDBID:        imdbID,
+		TMDBID:        movie.ID,
+		PosterURL:     posterURL,
+		Year:          year,
+		Genres:        s.getGenres(movie.GenreIDs),
+		Popularity:    movie.Popularity,
+		Overview:      movie.Overview,
+	}
 }
 
-// getGenres converts genre IDs to genre names
+// getGenres converts genre IDs to genre names, using any -genre-aliases labels
 func (s *Scraper) getGenres(genreIDs []int) []string {
 	var genres []string
 	for _, id := range genreIDs {
 		if genreName, exists := genreMap[id]; exists {
-			genres = append(genres, genreName)
+			genres = append(genres, genreLabel(s.genreAliases, genreName))
 		}
 	}
 	return genres
@@ -287,6 +747,11 @@ func (s *Scraper) getGenres(genreIDs []int) []string {
 
 // getIMDBID gets the IMDB ID for a TMDB movie ID
 func (s *Scraper) getIMDBID(tmdbID int) (string, error) {
+	if imdbID, ok := s.cache.imdbID(tmdbID); ok {
+		s.explainf("External IDs for TMDB %d: IMDB %q (cached)", tmdbID, imdbID)
+		return imdbID, nil
+	}
+
 	apiURL := fmt.Sprintf("%s/movie/%d/external_ids", s.tmdbBaseURL, tmdbID)
 	
 	params := url.Values{}
@@ -297,14 +762,21 @@ func (s *Scraper) getIMDBID(tmdbID int) (string, error) {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := s.client.Do(req)
+	resp, err := s.doRequest(req, endpointExternalIDs, strconv.Itoa(tmdbID))
 	if err != nil {
 		return "", fmt.Errorf("failed to get external IDs: %w", err)
 	}
 	defer resp.Body.Close()
 
+	// A 404 means the movie's details were removed; it can still be listed
+	// by its TMDB ID, so report it as having no IMDB ID rather than failing
+	if resp.StatusCode == http.StatusNotFound {
+		s.debugf("no external IDs for TMDB movie %d", tmdbID)
+		s.explainf("External IDs for TMDB %d: not found (404)", tmdbID)
+		return "", nil
+	}
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("TMDB API returned status %d for external IDs", resp.StatusCode)
+		return "", &tmdbStatusError{StatusCode: resp.StatusCode, Target: "external IDs"}
 	}
 
 	var externalIDs TMDBExternalIDs
@@ -312,112 +784,474 @@ func (s *Scraper) getIMDBID(tmdbID int) (string, error) {
 		return "", fmt.Errorf("failed to decode external IDs response: %w", err)
 	}
 
+	s.explainf("External IDs for TMDB %d: IMDB %q", tmdbID, externalIDs.IMDBID)
+	s.cache.storeIMDBID(tmdbID, externalIDs.IMDBID)
 	return externalIDs.IMDBID, nil
 }
 
-// generateRadarrList generates the complete Radarr-compatible list
-func (s *Scraper) generateRadarrList() ([]Movie, error) {
-	fmt.Println("Scraping Scott Hasn't Seen wiki page...")
-	htmlContent, err := s.scrapeWikiPage()
+// generateRadarrList generates the complete Radarr-compatible list by
+// scraping the wiki and resolving its titles
+func (s *Scraper) generateRadarrList(ctx context.Context) ([]Movie, error) {
+	if s.config.StreamTitles {
+		return s.streamRadarrList(ctx)
+	}
+
+	movieTitles, err := s.scrapeTitles()
 	if err != nil {
-		return nil, fmt.Errorf("failed to scrape wiki page: %w", err)
+		return nil, err
+	}
+
+	return s.resolveTitles(ctx, movieTitles)
+}
+
+// scrapeTitles fetches the wiki page and extracts the movie titles from it,
+// or reads them from -titles-file instead
+func (s *Scraper) scrapeTitles() ([]string, error) {
+	if s.config.TitleListFile != "" {
+		movieTitles, err := s.readTitleList(s.config.TitleListFile)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(s.logWriter(), "Read %d unique movies from %s\n", len(movieTitles), s.config.TitleListFile)
+		return movieTitles, nil
 	}
 
-	fmt.Println("Extracting movie titles...")
+	htmlContent, err := s.fetchWikiPage()
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintln(s.logWriter(), "Extracting movie titles...")
 	movieTitles, err := s.extractMovieTitles(htmlContent)
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract movie titles: %w", err)
 	}
 
-	fmt.Printf("Found %d unique movies\n", len(movieTitles))
+	fmt.Fprintf(s.logWriter(), "Found %d unique movies\n", len(movieTitles))
+	return movieTitles, nil
+}
+
+// fetchWikiPage downloads the wiki page, passing errWikiNotModified through
+// unwrapped so callers can detect an unchanged page
+func (s *Scraper) fetchWikiPage() (string, error) {
+	fmt.Fprintln(s.logWriter(), "Scraping Scott Hasn't Seen wiki page...")
+	htmlContent, err := s.scrapeWikiPage()
+	if err != nil {
+		if errors.Is(err, errWikiNotModified) {
+			return "", err
+		}
+		return "", fmt.Errorf("failed to scrape wiki page: %w", err)
+	}
+	return htmlContent, nil
+}
+
+// resolveTitles looks each title up on TMDB and builds the sorted movie list.
+// Cancelling ctx stops titles that are still waiting for a worker slot.
+func (s *Scraper) resolveTitles(ctx context.Context, movieTitles []string) ([]Movie, error) {
+	titles := make(chan string, len(movieTitles))
+	for _, title := range movieTitles {
+		titles <- title
+	}
+	close(titles)
+
+	return s.resolveStream(ctx, titles)
+}
 
+// resolveStream resolves titles as they arrive on the channel until it is
+// closed, then builds the sorted movie list
+func (s *Scraper) resolveStream(ctx context.Context, titles <-chan string) ([]Movie, error) {
 	var radarrList []Movie
+	var failures []Failure
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 
 	// Use a semaphore to limit concurrent API calls
 	semaphore := make(chan struct{}, 5) // Limit to 5 concurrent requests
+	stopRamp := rampUp(ctx, semaphore, s.config.RampUp)
+	defer stopRamp()
+
+	var counters runCounters
+	placeholders := 0
+	filteredSince := 0
+	repeated := 0
+	aliases := make(map[string][]string) // spellings of each normalized title looked up
+	var launched []string                // normalized titles by position - 1
+	progress := newProgress(s.logWriter(), 0, s.config.Progress, s.config.ProgressInterval)
+	started := s.now()
+	progress.now, progress.started = s.now, started
+	stopSummary := s.startSummaryReporter(func() RunSummary {
+		summary := s.snapshot(&counters, progress, started)
+		mu.Lock()
+		summary.FailuresByCategory = groupFailures(failures)
+		mu.Unlock()
+		return summary
+	})
 
-	successful := 0
-	failed := 0
-
-	for i, title := range movieTitles {
+	position := 0
+	for title := range titles {
+		if !s.airedSince(title) {
+			filteredSince++
+			continue
+		}
+		// A repeated title would repeat the first one's lookup, possibly
+		// while it is still in flight and before the cache can help
+		key := normalizeTitle(title)
+		if _, ok := aliases[key]; ok {
+			aliases[key] = append(aliases[key], title)
+			repeated++
+			continue
+		}
+		aliases[key] = []string{title}
+		launched = append(launched, key)
+		position++
+		progress.add()
 		wg.Add(1)
-		go func(index int, movieTitle string) {
+		go func(movieTitle string, position int) {
 			defer wg.Done()
 			
-			// Acquire semaphore
-			semaphore <- struct{}{}
+			// Acquire semaphore, giving up if the run is cancelled while queued
+			if err := acquire(ctx, semaphore); err != nil {
+				return
+			}
 			defer func() { <-semaphore }()
 
-			fmt.Printf("Processing %d/%d: %s\n", index+1, len(movieTitles), movieTitle)
+			defer progress.done()
 
-			movie, err := s.searchMovie(movieTitle)
+			s.titlef("Processing: %s\n", movieTitle)
+
+			// A title resolved before an interrupted run needs no lookups
+			movie, resumed := s.resumedMovie(movieTitle)
+			var attempts int
+			var err error
+			if !resumed {
+				movie, attempts, err = s.searchWithRetries(ctx, movieTitle)
+				counters.recordAttempts(attempts)
+			}
 			if err != nil {
+				atomic.AddInt64(&counters.failed, 1)
+				failure := newFailure(movieTitle, err)
+				failure.Attempts = attempts
 				mu.Lock()
-				failed++
+				failures = append(failures, failure)
+				s.keepUnmatched(&radarrList, &placeholders, movieTitle, position)
 				mu.Unlock()
-				fmt.Printf("  ✗ Not found: %s (%v)\n", movieTitle, err)
+				s.titlef("  %s Not found: %s (%v)\n", s.failMark(), movieTitle, err)
 				return
 			}
 
-			// Only require IMDB ID (essential for Radarr), poster URL is optional
-			if movie.IMDBID != "" {
+			// Only require a valid IMDB ID (essential for Radarr), poster URL is optional
+			err = s.validateMovie(movie)
+			if err == nil && !resumed {
+				err = s.fetchDetails(ctx, movie)
+			}
+			if err == nil {
+				movie.position = position
+
+				atomic.AddInt64(&counters.successful, 1)
 				mu.Lock()
 				radarrList = append(radarrList, *movie)
-				successful++
 				mu.Unlock()
+
+				// Sinks see the wiki's metadata as far as it is known; with
+				// -stream-titles a later row may still add episodes
+				added := *movie
+				s.applyWikiMetadata(&added, movieTitle)
+				s.addToSinks(added)
+				if !resumed {
+					s.recordCheckpoint(movieTitle, *movie)
+				}
 				
 				// Log whether poster is available or not
-				if movie.PosterURL != "" {
-					fmt.Printf("  ✓ Found: %s (IMDB: %s)\n", movie.Title, movie.IMDBID)
+				if movie.IMDBID == "" {
+					s.titlef("  %s Found: %s (TMDB: %d) - No IMDB ID\n", s.okMark(), movie.Title, movie.TMDBID)
+				} else if movie.PosterURL != "" {
+					s.titlef("  %s Found: %s (IMDB: %s)\n", s.okMark(), movie.Title, movie.IMDBID)
 				} else {
-					fmt.Printf("  ✓ Found: %s (IMDB: %s) - No poster\n", movie.Title, movie.IMDBID)
+					s.titlef("  %s Found: %s (IMDB: %s) - No poster\n", s.okMark(), movie.Title, movie.IMDBID)
 				}
 			} else {
+				atomic.AddInt64(&counters.failed, 1)
+				failure := newFailure(movieTitle, err)
+				failure.Attempts = attempts
 				mu.Lock()
-				failed++
+				failures = append(failures, failure)
+				s.keepUnmatched(&radarrList, &placeholders, movieTitle, position)
 				mu.Unlock()
-				fmt.Printf("  ✗ Missing IMDB ID: %s\n", movieTitle)
+				s.titlef("  %s Rejected: %s (%v)\n", s.failMark(), movieTitle, err)
 			}
 
-			// Rate limiting
-			time.Sleep(250 * time.Millisecond)
-		}(i, title)
+			// Rate limiting; a resumed title made no requests
+			if !resumed {
+				time.Sleep(s.rateLimitPause())
+			}
+		}(title, position)
 	}
 
 	wg.Wait()
+	progress.finish()
+	stopSummary()
 
-	// Sort the movies by title to ensure consistent order
-	sort.Slice(radarrList, func(i, j int) bool {
-		return radarrList[i].Title < radarrList[j].Title
-	})
-	
-	fmt.Println("Movies sorted by title for consistent output order")
+	s.failures = failures
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("run cancelled: %w", err)
+	}
+
+	// The wiki metadata is only complete once the whole page has been
+	// extracted, which with -stream-titles is after the lookups started. A
+	// repeated title adds its episodes to the first one's movie.
+	for i := range radarrList {
+		s.applyWikiMetadata(&radarrList[i], aliases[launched[radarrList[i].position-1]]...)
+	}
+
+	radarrList = s.carryOver(radarrList, position)
+
+	noPoster := 0
+	if s.config.RequirePoster {
+		radarrList, noPoster = withPosters(radarrList)
+	}
+
+	unpopular := 0
+	if s.config.MinPopularity > 0 {
+		radarrList, unpopular = withMinPopularity(radarrList, s.config.MinPopularity)
+	}
+
+	byEpisode := 0
+	if pattern := s.config.excludeEpisodePattern(); pattern != nil {
+		radarrList, byEpisode = withoutEpisodes(radarrList, pattern)
+	}
+
+	overCertification := 0
+	if s.config.MaxCertification != "" {
+		radarrList, overCertification = withinCertification(radarrList, s.config.MaxCertification)
+	}
+
+	byKeyword := 0
+	if len(s.config.Keywords) > 0 || len(s.config.ExcludeKeywords) > 0 {
+		radarrList, byKeyword = withKeywords(radarrList, s.config.Keywords, s.config.ExcludeKeywords)
+	}
+
+	excludedGenres := s.config.excludedGenres()
+	droppedByGenre := 0
+	if len(excludedGenres) > 0 {
+		radarrList, droppedByGenre = s.withoutGenres(radarrList, excludedGenres)
+	}
+
+	// With -no-sort, restore wiki page order before deduplicating so the
+	// first appearance of a movie is the one kept
+	if s.config.NoSort {
+		sortByPosition(radarrList)
+	}
+
+	var duplicates int
+	if s.config.MergeEpisodes {
+		radarrList, duplicates = dedupeMergingEpisodes(radarrList)
+	} else {
+		radarrList, duplicates = dedupeMovies(radarrList)
+	}
+	if duplicates > 0 {
+		fmt.Fprintf(s.logWriter(), "Removed %d duplicate movies\n", duplicates)
+	}
+
+	if s.config.NoSort {
+		fmt.Fprintln(s.logWriter(), "Movies kept in wiki page order (-no-sort)")
+	} else if s.config.SortByPopularity {
+		sortByPopularity(radarrList)
 
-	fmt.Printf("\nSummary:\n")
-	fmt.Printf("  Successful: %d\n", successful)
-	fmt.Printf("  Failed: %d\n", failed)
-	fmt.Printf("  Total: %d\n", len(radarrList))
+		fmt.Fprintln(s.logWriter(), "Movies sorted by TMDB popularity, most popular first")
+	} else if s.config.Sort == sortEpisodeDesc {
+		sortByEpisodeDesc(radarrList)
+
+		fmt.Fprintln(s.logWriter(), "Movies sorted by episode, most recently discussed first")
+	} else {
+		// Sort the movies by title to ensure consistent order
+		sortMovies(radarrList)
+
+		fmt.Fprintln(s.logWriter(), "Movies sorted by title for consistent output order")
+	}
+	if compare := s.sortComparator(); compare != nil && s.config.LogLevel == logLevelDebug {
+		if err := checkTotalOrder(radarrList, compare); err != nil {
+			s.debugf("output order is not deterministic: %v", err)
+		}
+	}
+
+	s.ambiguous = s.ambiguousMatches(radarrList)
+
+	// -warm-cache reports only its cache stats
+	if s.config.WarmCache != "" {
+		return radarrList, nil
+	}
+
+	fmt.Fprintf(s.logWriter(), "\nSummary:\n")
+	fmt.Fprintf(s.logWriter(), "  Successful: %d\n", atomic.LoadInt64(&counters.successful))
+	fmt.Fprintf(s.logWriter(), "  Failed: %d\n", atomic.LoadInt64(&counters.failed))
+	printFailureGroups(s.logWriter(), groupFailures(failures))
+	s.printMissingFields(failures)
+	if s.config.Retries > 0 {
+		fmt.Fprintf(s.logWriter(), "  Retried titles: %d (%d retries)\n", atomic.LoadInt64(&counters.retried), atomic.LoadInt64(&counters.retries))
+	}
+	if s.config.KeepUnmatched {
+		fmt.Fprintf(s.logWriter(), "  Unmatched placeholders: %d\n", placeholders)
+	}
+	if repeated > 0 {
+		fmt.Fprintf(s.logWriter(), "  Repeated titles skipped: %d\n", repeated)
+	}
+	if !s.config.Since.IsZero() {
+		fmt.Fprintf(s.logWriter(), "  Filtered by -since %s: %d\n", s.config.Since.Format(airDateLayout), filteredSince)
+	}
+	if s.config.RequirePoster {
+		fmt.Fprintf(s.logWriter(), "  Dropped without a poster: %d\n", noPoster)
+	}
+	if s.config.MinPopularity > 0 {
+		fmt.Fprintf(s.logWriter(), "  Below -min-popularity %g: %d\n", s.config.MinPopularity, unpopular)
+	}
+	if s.config.ExcludeEpisodes != "" {
+		fmt.Fprintf(s.logWriter(), "  Excluded by episode (%s): %d\n", s.config.ExcludeEpisodes, byEpisode)
+	}
+	if s.config.MaxCertification != "" {
+		fmt.Fprintf(s.logWriter(), "  Above -max-certification %s or unrated: %d\n", s.config.MaxCertification, overCertification)
+	}
+	if len(s.config.Keywords) > 0 || len(s.config.ExcludeKeywords) > 0 {
+		fmt.Fprintf(s.logWriter(), "  Filtered by keyword: %d\n", byKeyword)
+	}
+	if len(excludedGenres) > 0 {
+		fmt.Fprintf(s.logWriter(), "  Excluded by genre (%s): %d\n", strings.Join(excludedGenres, ", "), droppedByGenre)
+	}
+	if s.previous != nil {
+		if s.config.Prune {
+			fmt.Fprintf(s.logWriter(), "  Pruned (not in this run's list): %d\n", len(s.pruned))
+		} else {
+			fmt.Fprintf(s.logWriter(), "  Kept from the previous list: %d\n", s.carried)
+		}
+	}
+	fmt.Fprintf(s.logWriter(), "  Total: %d\n", len(radarrList))
+	if s.config.AmbiguityThreshold > 0 {
+		fmt.Fprintf(s.logWriter(), "  Ambiguous matches (%d+ search results): %d\n", s.config.AmbiguityThreshold, len(s.ambiguous))
+	}
+	if s.config.MultiSearch {
+		fmt.Fprintf(s.logWriter(), "  TV matches for review: %d\n", len(s.tvMatches.sorted()))
+	}
+	fmt.Fprintf(s.logWriter(), "  TMDB requests: %d\n", atomic.LoadInt64(&s.tmdbRequests))
+	fmt.Fprintf(s.logWriter(), "  Cache hits: %d\n", atomic.LoadInt64(&s.cache.hits))
+	if s.config.MaxRequests > 0 {
+		fmt.Fprintf(s.logWriter(), "  Deferred (request quota reached): %d\n", countFailures(failures, failureQuotaExceeded))
+	}
+	if n := countFailures(failures, failureMetadata); n > 0 {
+		fmt.Fprintf(s.logWriter(), "  Failed (certification or keyword lookup): %d\n", n)
+	}
+	if n := countFailures(failures, failureCircuitOpen); n > 0 {
+		fmt.Fprintf(s.logWriter(), "  Skipped (circuit breaker open): %d\n", n)
+	}
+	s.printBreakerTrips()
+
+	s.printDropCounts()
+	s.printPruned()
+	printGenreDistribution(s.logWriter(), genreDistribution(radarrList))
+	s.printLatencySummary()
 
 	return radarrList, nil
 }
 
-// saveToFile saves the Radarr list to a JSON file
+// acquire takes a slot from the semaphore, returning early with the context's
+// error if it is cancelled before a slot frees up
+func acquire(ctx context.Context, semaphore chan struct{}) error {
+	select {
+	case semaphore <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	// Both cases may be ready at once; don't start new work after cancellation
+	if err := ctx.Err(); err != nil {
+		<-semaphore
+		return err
+	}
+	return nil
+}
+
+// sortMovies orders movies by title, breaking ties by year and then TMDB ID
+// so the output is identical between runs
+func sortMovies(movies []Movie) {
+	sortWith(movies, compareMovies)
+}
+
+// sortByPosition orders movies by where their titles appear on the wiki page
+func sortByPosition(movies []Movie) {
+	sort.SliceStable(movies, func(i, j int) bool {
+		return movies[i].position < movies[j].position
+	})
+}
+
+// okMark returns the marker for a successful lookup, ASCII-only with -plain
+func (s *Scraper) okMark() string {
+	if s.config.Plain {
+		return "OK"
+	}
+	return "✓"
+}
+
+// failMark returns the marker for a failed lookup, ASCII-only with -plain
+func (s *Scraper) failMark() string {
+	if s.config.Plain {
+		return "FAIL"
+	}
+	return "✗"
+}
+
+// keepUnmatched appends a placeholder for an unmatched title when -keep-unmatched is set.
+// Callers must hold the lock guarding list and count.
+func (s *Scraper) keepUnmatched(list *[]Movie, count *int, title string, position int) {
+	if !s.config.KeepUnmatched {
+		return
+	}
+	placeholder := newPlaceholder(title)
+	placeholder.position = position
+	*list = append(*list, placeholder)
+	*count++
+}
+
+// fetchDetails looks up the certification and keywords the list filters
+// need, retrying transient failures like searches. A movie whose details
+// can't be fetched fails rather than being silently kept or dropped.
+func (s *Scraper) fetchDetails(ctx context.Context, movie *Movie) error {
+	if s.config.wantsCertification() {
+		err := s.retryLookup(ctx, "certification for "+movie.Title, func() (err error) {
+			movie.Certification, err = s.getCertification(movie.TMDBID)
+			return err
+		})
+		if err != nil {
+			return metadataError("certification", err)
+		}
+	}
+	if s.config.wantsKeywords() {
+		err := s.retryLookup(ctx, "keywords for "+movie.Title, func() (err error) {
+			movie.Keywords, err = s.getKeywords(movie.TMDBID)
+			return err
+		})
+		if err != nil {
+			return metadataError("keywords", err)
+		}
+	}
+	return nil
+}
+
+// saveToFile saves the Radarr list to a file in the configured format
 func (s *Scraper) saveToFile(movies []Movie, filename string) error {
-	data, err := json.Marshal(movies)
+	return s.saveToFileAs(movies, filename, s.config.Format)
+}
+
+// saveToFileAs saves the Radarr list to a file in the given format
+func (s *Scraper) saveToFileAs(movies []Movie, filename, format string) error {
+	data, err := s.encodeListAs(movies, format, s.runID)
 	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %w", err)
+		return fmt.Errorf("failed to encode movies: %w", err)
 	}
 
-	// Add newline at the end of the JSON data
-	data = append(data, '\n')
-
-	if err := os.WriteFile(filename, data, 0644); err != nil {
+	if err := s.writeFile(filename, data); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
-	fmt.Printf("Saved %d movies to %s\n", len(movies), filename)
+	fmt.Fprintf(s.logWriter(), "Saved %d movies to %s\n", len(movies), filename)
 	return nil
 }
 
@@ -430,11 +1264,16 @@ func (s *Scraper) saveToRSS(movies []Movie, filename string) error {
         <title>Scott Hasn't Seen</title>
         <description>Movies Scott hasn't seen yet</description>
         <link>https://github.com/yourusername/radarr-scott-hasnt-seen</link>
-        <lastBuildDate>` + time.Now().Format(time.RFC1123Z) + `</lastBuildDate>
+        <lastBuildDate>` + s.now().Format(time.RFC1123Z) + `</lastBuildDate>
 `
 
 	// Add each movie as an RSS item
-	for _, movie := range movies {
+	for _, movie := range matchedOnly(movies) {
+		// RSS items are keyed by IMDB ID
+		if movie.IMDBID == "" {
+			continue
+		}
+
 		title := movie.Title
 		if movie.Year > 0 {
 			title = fmt.Sprintf("%s (%d)", movie.Title, movie.Year)
@@ -451,66 +1290,370 @@ func (s *Scraper) saveToRSS(movies []Movie, filename string) error {
 </rss>`
 
 	// Write to file
-	err := os.WriteFile(filename, []byte(rssContent), 0644)
+	err := s.writeFile(filename, []byte(rssContent))
 	if err != nil {
 		return fmt.Errorf("failed to write RSS file: %w", err)
 	}
 
-	fmt.Printf("Saved %d movies to RSS file %s\n", len(movies), filename)
+	fmt.Fprintf(s.logWriter(), "Saved %d movies to RSS file %s\n", len(movies), filename)
 	return nil
 }
 
 func main() {
+	os.Exit(run())
+}
+
+// run does the work of main and returns the exit code, so that deferred
+// cleanup such as closing the scraper happens on every path out
+func run() int {
 	// Load environment variables from .env file if it exists
 	godotenv.Load()
 
-	// Get TMDB API key from environment
-	tmdbAPIKey := os.Getenv("TMDB_API_KEY")
+	cfg, err := parseFlags(os.Args[1:])
+	if err != nil {
+		log.Printf("Invalid arguments: %v", err)
+		return 1
+	}
+
+	// With -stdout the list is the only thing written to standard output;
+	// progress and summary messages go to standard error instead
+	listOut, logOut := os.Stdout, cfg.logWriter()
+
+	if cfg.ValidateConfig {
+		if !reportConfigProblems(logOut, validateConfigInputs(cfg)) {
+			return 1
+		}
+		return 0
+	}
+
+	if cfg.DedupeFile != "" {
+		scraper := NewScraper("", WithConfig(cfg))
+		if err := scraper.dedupeFile(cfg.DedupeFile); err != nil {
+			log.Printf("Failed to dedupe %s: %v", cfg.DedupeFile, err)
+			return 1
+		}
+		return 0
+	}
+
+	if cfg.VerifyPosters != "" {
+		scraper := NewScraper("", WithConfig(cfg))
+		if err := scraper.verifyPosterFile(cfg.VerifyPosters); err != nil {
+			log.Printf("Failed to verify posters in %s: %v", cfg.VerifyPosters, err)
+			return 1
+		}
+		return 0
+	}
+
+	var fromConfigDir configDir
+	if cfg.ConfigDir != "" {
+		fromConfigDir, err = loadConfigDir(cfg.ConfigDir)
+		if err != nil {
+			log.Printf("Failed to load config directory: %v", err)
+			return 1
+		}
+		fmt.Fprintf(logOut, "Loaded %d skipped titles, %d title overrides and %d genre aliases from %s\n", len(fromConfigDir.skip), len(fromConfigDir.overrides), len(fromConfigDir.genreAliases), cfg.ConfigDir)
+	}
+
+	wikiCookie, err := cfg.wikiCookie()
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return 1
+	}
+
+	if cfg.Command == commandScrape {
+		scraper := NewScraper("", WithConfig(cfg), WithSkipTitles(fromConfigDir.skip), WithWikiCookie(wikiCookie))
+		err := scraper.scrapeToFile(cfg.TitlesFile)
+		if errors.Is(err, errWikiNotModified) {
+			fmt.Fprintln(logOut, "Wiki page unchanged since last run; nothing to do (use -force to run anyway)")
+			return 0
+		}
+		if err != nil {
+			log.Printf("Failed to scrape titles: %v", err)
+			return 1
+		}
+		return 0
+	}
+
+	// Get TMDB API key from the key file or environment
+	tmdbAPIKey, err := cfg.apiKey()
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return 1
+	}
+	if cfg.Offline != "" {
+		tmdbAPIKey = offlineAPIKey
+	}
 	if tmdbAPIKey == "" {
-		log.Fatal("Error: TMDB_API_KEY environment variable not set\nPlease get your API key from https://www.themoviedb.org/settings/api")
+		log.Print("Error: TMDB_API_KEY environment variable not set (or use -api-key-file)\nPlease get your API key from https://www.themoviedb.org/settings/api")
+		return 1
+	}
+
+	// A drift check must compare a fresh scrape, and warming the cache must
+	// resolve every title, not skip an unchanged page
+	if cfg.StatsOnly || cfg.WarmCache != "" {
+		cfg.Force = true
+	}
+
+	opts := []Option{WithConfig(cfg), WithWikiCookie(wikiCookie)}
+	if cfg.Offline != "" {
+		fmt.Fprintf(logOut, "Running offline from fixtures in %s\n", cfg.Offline)
+		opts = append(opts, WithOffline(cfg.Offline))
+	}
+	if cfg.OverridesFile != "" {
+		overrides, err := loadOverrides(cfg.OverridesFile)
+		if err != nil {
+			log.Printf("Failed to load overrides: %v", err)
+			return 1
+		}
+		fmt.Fprintf(logOut, "Loaded %d title overrides\n", len(overrides))
+		opts = append(opts, WithOverrides(overrides))
+	}
+	if cfg.GenreAliasesFile != "" {
+		aliases, err := loadGenreAliases(cfg.GenreAliasesFile)
+		if err != nil {
+			log.Printf("Failed to load genre aliases: %v", err)
+			return 1
+		}
+		fmt.Fprintf(logOut, "Loaded %d genre aliases\n", len(aliases))
+		opts = append(opts, WithGenreAliases(aliases))
+	}
+	if cfg.ConfigDir != "" {
+		opts = append(opts, WithSkipTitles(fromConfigDir.skip), WithOverrides(fromConfigDir.overrides), WithGenreAliases(fromConfigDir.genreAliases))
+	}
+	if cfg.FailedRequestsLog != "" {
+		logFile, err := os.OpenFile(cfg.FailedRequestsLog, os.O_CREATE|os.O_WRONLY|os.O_APPEND, cfg.FileMode)
+		if err != nil {
+			log.Printf("Failed to open failed requests log: %v", err)
+			return 1
+		}
+		defer logFile.Close()
+		opts = append(opts, WithFailedRequestLog(logFile))
+	}
+	if cfg.Checkpoint != "" {
+		var resumed []checkpointEntry
+		if cfg.Resume {
+			resumed, err = loadCheckpoint(cfg.Checkpoint)
+			if err != nil {
+				log.Printf("Failed to load checkpoint: %v", err)
+				return 1
+			}
+			fmt.Fprintf(logOut, "Resuming with %d titles resolved before the interruption\n", len(resumed))
+		}
+		checkpointFile, err := createCheckpoint(cfg.Checkpoint, resumed, cfg.FileMode)
+		if err != nil {
+			log.Printf("Failed to open checkpoint: %v", err)
+			return 1
+		}
+		defer checkpointFile.Close()
+		opts = append(opts, WithCheckpoint(checkpointFile, resumed))
+	}
+	if cfg.DumpCandidates != "" {
+		dumpFile, err := os.OpenFile(cfg.DumpCandidates, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, cfg.FileMode)
+		if err != nil {
+			log.Printf("Failed to open candidate dump: %v", err)
+			return 1
+		}
+		defer dumpFile.Close()
+		opts = append(opts, WithCandidateDump(dumpFile))
+	}
+	if cfg.carriesOver() {
+		previous, err := loadMovies(mainOutputBase + ".json")
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Failed to load the previous list: %v", err)
+			return 1
+		}
+		opts = append(opts, WithPreviousList(previous))
+	}
+	if cfg.RadarrURL != "" {
+		radarrAPIKey := os.Getenv("RADARR_API_KEY")
+		if radarrAPIKey == "" {
+			log.Print("Error: RADARR_API_KEY environment variable not set (required with -radarr-url)")
+			return 1
+		}
+		opts = append(opts, WithSink(newRadarrSink(cfg.RadarrURL, radarrAPIKey, cfg.RadarrRootFolder, cfg.RadarrProfile, logOut)))
+	}
+
+	if cfg.Command == commandServe {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		fmt.Fprintf(logOut, "Serving on http://%s (GET /stream runs a refresh)\n", cfg.Listen)
+		err := serve(ctx, cfg.Listen, func(extra ...Option) *Scraper {
+			return NewScraper(tmdbAPIKey, append(opts[:len(opts):len(opts)], extra...)...)
+		})
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Server failed: %v", err)
+			return 1
+		}
+		return 0
+	}
+
+	scraper := NewScraper(tmdbAPIKey, opts...)
+	defer scraper.Close()
+	fmt.Fprintf(logOut, "Run ID: %s\n", scraper.runID)
+
+	if cfg.SelfTest {
+		fmt.Fprintln(logOut, "Running self-test...")
+		if !scraper.selfTest() {
+			fmt.Fprintln(logOut, "Self-test failed")
+			return 1
+		}
+		fmt.Fprintln(logOut, "Self-test passed")
+		return 0
+	}
+
+	if cfg.ImportCache != "" {
+		if err := scraper.importCache(cfg.ImportCache); err != nil {
+			log.Printf("Failed to import cache: %v", err)
+			return 1
+		}
 	}
 
-	scraper := NewScraper(tmdbAPIKey)
-	radarrList, err := scraper.generateRadarrList()
+	if cfg.ResolvedSet != "" {
+		if err := scraper.useResolvedSet(cfg.ResolvedSet); err != nil {
+			log.Printf("Failed to load resolved set: %v", err)
+			return 1
+		}
+	}
+
+	if cfg.WarmCache != "" {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := scraper.warmCache(ctx, cfg.WarmCache); err != nil {
+			log.Printf("Failed to warm cache: %v", err)
+			return 1
+		}
+		return 0
+	}
+
+	// -only resolves one title for debugging and leaves the list files alone
+	if cfg.Only != "" {
+		if err := scraper.resolveOnly(cfg.Only, listOut); err != nil {
+			fmt.Fprintf(logOut, "Could not resolve %q: %v\n", cfg.Only, err)
+			return 1
+		}
+		return 0
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var radarrList []Movie
+	if cfg.Command == commandResolve {
+		radarrList, err = scraper.resolveTitlesFile(ctx, cfg.TitlesFile)
+	} else {
+		radarrList, err = scraper.generateRadarrList(ctx)
+	}
+	if errors.Is(err, errWikiNotModified) {
+		fmt.Fprintln(logOut, "Wiki page unchanged since last run; nothing to do (use -force to run anyway)")
+		return 0
+	}
 	if err != nil {
-		log.Fatalf("Failed to generate Radarr list: %v", err)
+		log.Printf("Failed to generate Radarr list: %v", err)
+		return 1
+	}
+	if cfg.ResolvedSet != "" {
+		if err := scraper.saveResolvedSet(cfg.ResolvedSet); err != nil {
+			log.Printf("Failed to save resolved set: %v", err)
+		}
 	}
 
-	if len(radarrList) > 0 {
-		// Debug: Show current working directory
-		if cwd, err := os.Getwd(); err == nil {
-			fmt.Printf("Current working directory: %s\n", cwd)
+	if cfg.Merge {
+		radarrList, err = scraper.mergeWithFile(radarrList, mainOutputBase+".json")
+		if err != nil {
+			log.Printf("Failed to merge manual entries: %v", err)
+			return 1
 		}
-		
-		// Save JSON with timestamp
-		timestamp := time.Now().Format("20060102_150405")
-		jsonFilename := fmt.Sprintf("../../scott_hasnt_seen_%s.json", timestamp)
-		fmt.Printf("Saving timestamped JSON file to: %s\n", jsonFilename)
-		if err := scraper.saveToFile(radarrList, jsonFilename); err != nil {
-			log.Printf("Failed to save timestamped JSON file: %v", err)
+	}
+
+	radarrList, err = scraper.checkOutput(radarrList)
+	if err != nil {
+		log.Printf("Output validation failed: %v", err)
+		return 1
+	}
+
+	if cfg.GenreStatsFile != "" {
+		if err := scraper.saveGenreDistribution(genreDistribution(radarrList), cfg.GenreStatsFile); err != nil {
+			log.Printf("Failed to save genre stats: %v", err)
 		}
+	}
 
-		// Save JSON without timestamp for easy access (in root directory)
-		mainJsonFilename := "../../scott_hasnt_seen.json"
-		fmt.Printf("Saving main JSON file to: %s\n", mainJsonFilename)
-		if err := scraper.saveToFile(radarrList, mainJsonFilename); err != nil {
-			log.Printf("Failed to save main JSON file: %v", err)
+	if cfg.FailuresFile != "" {
+		if err := scraper.saveFailures(cfg.FailuresFile); err != nil {
+			log.Printf("Failed to save failures file: %v", err)
 		}
+	}
+	if cfg.OverridesTemplate != "" {
+		if err := scraper.saveOverridesTemplate(cfg.OverridesTemplate); err != nil {
+			log.Printf("Failed to save overrides template: %v", err)
+		}
+	}
+
+	// Compare against the previous list before it is overwritten
+	diff, against, diffErr := scraper.diffAgainstBase(scraper.prepareForOutput(radarrList), mainOutputBase+".json")
+	if diffErr != nil {
+		log.Printf("Failed to compare against the previous list: %v", diffErr)
+	} else if cfg.DiffComment != "" {
+		if err := scraper.writeFile(cfg.DiffComment, []byte(diffMarkdown(diff, against))); err != nil {
+			log.Printf("Failed to write diff comment: %v", err)
+		}
+	}
+	if err := writeActionsOutputs(runOutputs(diff, diffErr, radarrList, scraper.failures)); err != nil {
+		log.Printf("Failed to write GitHub Actions outputs: %v", err)
+	}
+
+	// -strict wants every title or nothing; the failures report says why
+	if err := scraper.checkStrict(); err != nil {
+		fmt.Fprintf(logOut, "Strict mode: %v; not writing the list (see %s)\n", err, cfg.FailuresFile)
+		return 1
+	}
+
+	if cfg.StatsOnly {
+		diff, err := checkDrift(logOut, scraper.prepareForOutput(radarrList), mainOutputBase+".json")
+		if err != nil {
+			log.Printf("Failed to compare against committed list: %v", err)
+			return 1
+		}
+		if diff.HasChanges() {
+			fmt.Fprintln(logOut, "Committed list is stale")
+			return 1
+		}
+		fmt.Fprintln(logOut, "Committed list is up to date")
+		return 0
+	}
 
-		// Save RSS with timestamp
-		rssFilename := fmt.Sprintf("../../scott_hasnt_seen_%s.xml", timestamp)
-		fmt.Printf("Saving timestamped RSS file to: %s\n", rssFilename)
-		if err := scraper.saveToRSS(radarrList, rssFilename); err != nil {
-			log.Printf("Failed to save timestamped RSS file: %v", err)
+	if cfg.Stdout {
+		if err := scraper.writeList(listOut, radarrList); err != nil {
+			log.Printf("Failed to write list to stdout: %v", err)
+			return 1
+		}
+		if cfg.Checkpoint != "" {
+			scraper.removeCheckpoint(cfg.Checkpoint)
 		}
+		return 0
+	}
 
-		// Save RSS without timestamp for easy access (in root directory)
-		mainRssFilename := "../../scott_hasnt_seen.xml"
-		fmt.Printf("Saving main RSS file to: %s\n", mainRssFilename)
-		if err := scraper.saveToRSS(radarrList, mainRssFilename); err != nil {
-			log.Printf("Failed to save main RSS file: %v", err)
+	if len(radarrList) > 0 {
+		// Debug: Show current working directory
+		if cwd, err := os.Getwd(); err == nil {
+			fmt.Fprintf(logOut, "Current working directory: %s\n", cwd)
+		}
+		
+		if err := scraper.finishSinks(radarrList); err != nil {
+			log.Printf("Failed to write the list: %v", err)
+		} else {
+			if cfg.Checkpoint != "" {
+				scraper.removeCheckpoint(cfg.Checkpoint)
+			}
+			if err := scraper.runPostHook(ctx, scraper.summary); err != nil {
+				log.Printf("Post-hook failed: %v", err)
+			}
+			if err := scraper.saveWikiState(); err != nil {
+				log.Printf("Failed to save wiki state: %v", err)
+			}
 		}
 	} else {
-		fmt.Println("No movies found to save")
+		fmt.Fprintln(logOut, "No movies found to save")
 	}
+
+	return 0
 } 
\ No newline at end of file
@@ -2,7 +2,9 @@ package main
 
 import (
 	"fmt"
+	"reflect"
 	"sort"
+	"strings"
 	"testing"
 )
 
@@ -62,6 +64,38 @@ func TestExtractMovieTitles(t *testing.T) {
 	t.Logf("Successfully extracted %d movies", len(movies))
 }
 
+func TestExtractMovieTitlesKeepsSameTitleDifferentYears(t *testing.T) {
+	scraper := NewScraper("dummy_key")
+
+	htmlContent := `<table>
+		<tr><td><i>The Mummy (1999)</i></td></tr>
+		<tr><td><i>The Mummy (2017)</i></td></tr>
+		<tr><td><i>The Mummy (1999)[1]</i></td></tr>
+		<tr><td><i>Ghost</i></td></tr>
+		<tr><td><i>Ghost (1990)</i></td></tr>
+	</table>`
+
+	movies, err := scraper.extractMovieTitles(htmlContent)
+	if err != nil {
+		t.Fatalf("Failed to extract movie titles: %v", err)
+	}
+
+	// The second Mummy is a different film; the repeated 1999 entry and a
+	// Ghost whose year only one entry gives are duplicates
+	expected := []string{"The Mummy", "The Mummy (2017)", "Ghost"}
+	if !reflect.DeepEqual(movies, expected) {
+		t.Errorf("Expected %q, got %q", expected, movies)
+	}
+	for title, year := range map[string]int{"The Mummy": 1999, "The Mummy (2017)": 2017, "Ghost": 1990} {
+		if got := scraper.yearHints[title]; got != year {
+			t.Errorf("%s: expected year hint %d, got %d", title, year, got)
+		}
+	}
+	if got := scraper.dropCounts[dropRuleDuplicate]; got != 2 {
+		t.Errorf("Expected 2 duplicates dropped, got %d", got)
+	}
+}
+
 func TestScraperCreation(t *testing.T) {
 	apiKey := "test_api_key"
 	scraper := NewScraper(apiKey)
@@ -193,6 +227,120 @@ func TestGenreMapping(t *testing.T) {
 	if len(unknownGenres) != 0 {
 		t.Errorf("Expected 0 genres for unknown ID, got %d", len(unknownGenres))
 	}
-} 
+}
+
+func TestScraperClose(t *testing.T) {
+	scraper := NewScraper("dummy_key")
+
+	if err := scraper.Close(); err != nil {
+		t.Errorf("Expected Close to succeed, got %v", err)
+	}
+
+	// Closing twice must be harmless
+	if err := scraper.Close(); err != nil {
+		t.Errorf("Expected second Close to succeed, got %v", err)
+	}
+}
+
+func TestSortMoviesTieBreaker(t *testing.T) {
+	movies := []Movie{
+		{Title: "The Mummy", IMDBID: "tt0023245", TMDBID: 15849, Year: 1932},
+		{Title: "The Mummy", IMDBID: "tt2345759", TMDBID: 282035, Year: 2017},
+		{Title: "Dune", IMDBID: "tt1160419", TMDBID: 438631, Year: 2021},
+		{Title: "The Mummy", IMDBID: "tt0120616", TMDBID: 564, Year: 1999},
+		{Title: "Dune", IMDBID: "tt0087182", TMDBID: 841, Year: 1984},
+	}
+
+	sortMovies(movies)
+
+	expected := []int{841, 438631, 15849, 564, 282035}
+	for i, movie := range movies {
+		if movie.TMDBID != expected[i] {
+			t.Errorf("Position %d: expected TMDB ID %d, got %d (%s %d)", i, expected[i], movie.TMDBID, movie.Title, movie.Year)
+		}
+	}
+}
+
+func TestNewMoviePreferOriginalTitle(t *testing.T) {
+	tmdbMovie := TMDBMovie{ID: 129, Title: "Spirited Away", OriginalTitle: "千と千尋の神隠し"}
+
+	movie := NewScraper("dummy_key").newMovie(tmdbMovie, "tt0245429")
+	if movie.Title != "Spirited Away" || movie.OriginalTitle != "千と千尋の神隠し" {
+		t.Errorf("Expected localized title with original title kept, got %+v", movie)
+	}
+
+	cfg := defaultConfig()
+	cfg.PreferOriginalTitle = true
+	movie = NewScraper("dummy_key", WithConfig(cfg)).newMovie(tmdbMovie, "tt0245429")
+	if movie.Title != "千と千尋の神隠し" {
+		t.Errorf("Expected original title with -prefer-original-title, got %s", movie.Title)
+	}
+}
+
+func TestDropReasonCounts(t *testing.T) {
+	scraper := NewScraper("dummy_key")
+
+	htmlContent := `<html><body>
+		<i>Space Jam</i>
+		<i>Space Jam</i>
+		<i>Cobra Kai Season 5</i>
+		<i>Scott Hasn't Seen</i>
+		<i>Part 2 of the Saga</i>
+		<i>Ab</i>
+		<i>Abc</i>
+	</body></html>`
+
+	movies, err := scraper.extractMovieTitles(htmlContent)
+	if err != nil {
+		t.Fatalf("Failed to extract movie titles: %v", err)
+	}
+	if len(movies) != 1 || movies[0] != "Space Jam" {
+		t.Errorf("Expected only Space Jam to be kept, got %v", movies)
+	}
+
+	expected := map[string]int{
+		dropRuleDuplicate:       1,
+		dropRuleSkipKeyword:     2,
+		dropRuleEpisodePattern:  1,
+		dropRuleTooShort:        1,
+		dropRuleShortSingleWord: 1,
+	}
+	for rule, count := range expected {
+		if scraper.dropCounts[rule] != count {
+			t.Errorf("Rule %s: expected %d drops, got %d", rule, count, scraper.dropCounts[rule])
+		}
+	}
+
+	if rule, detail := dropReason("Scott Hasn't Seen"); rule != dropRuleSkipKeyword || detail != "scott hasn't seen" {
+		t.Errorf("Expected the podcast name to be dropped by its keyword, got %s (%q)", rule, detail)
+	}
+}
 
- 
+// largeWikiPage builds a wiki page the size of several years of episodes:
+// episode tables with air date and guest columns, footnotes, year hints,
+// repeated titles and non-movie italics for the filters to drop
+func largeWikiPage(episodes int) string {
+	var b strings.Builder
+	b.WriteString("<html><body><p><i>Scott Hasn't Seen</i> is a podcast.</p>")
+	for table := 0; table*100 < episodes; table++ {
+		b.WriteString("<table><tr><th>#</th><th>Film</th><th>Guest</th><th>Air date</th></tr>")
+		for i := table * 100; i < episodes && i < (table+1)*100; i++ {
+			fmt.Fprintf(&b, "<tr><td>%d</td><td><i>Movie Number %d (%d)</i><sup>[%d]</sup></td><td>Guest %d</td><td>January %d, %d</td></tr>",
+				i+1, i%(episodes/2+1), 1950+i%70, i%9+1, i%40, i%28+1, 2015+i/52)
+		}
+		b.WriteString("</table><p>Also mentioned: <i>The Simpsons</i> and <i>TBA</i>.</p>")
+	}
+	b.WriteString("</body></html>")
+	return b.String()
+}
+
+func BenchmarkExtractMovieTitles(b *testing.B) {
+	page := largeWikiPage(1000)
+	b.SetBytes(int64(len(page)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewScraper("dummy_key").extractMovieTitles(page); err != nil {
+			b.Fatalf("Failed to extract movie titles: %v", err)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Match methods describing how a title was resolved
+const (
+	matchMethodExact      = "exact"
+	matchMethodSlashSplit = "slash-split"
+	matchMethodOverride   = "override"
+	matchMethodVariant    = "variant"
+
+	matchMethodArticleStripped = "article-stripped"
+	matchMethodIMDBSuggestion  = "imdb-suggestion"
+	matchMethodYearConstrained = "year-constrained"
+)
+
+// Confidence penalties for the fallback match methods. A fallback searched
+// for something other than the wiki's title, so its match is less certain
+// than a direct hit even when the fallback title matched exactly.
+const (
+	slashSplitPenalty      = 0.05
+	variantPenalty         = 0.05
+	articleStrippedPenalty = 0.1
+	imdbSuggestionPenalty  = 0.1
+)
+
+// matchPenalties maps match methods to the penalty subtracted from their
+// confidence. Methods not listed have none.
+var matchPenalties = map[string]float64{
+	matchMethodSlashSplit:      slashSplitPenalty,
+	matchMethodVariant:         variantPenalty,
+	matchMethodArticleStripped: articleStrippedPenalty,
+	matchMethodIMDBSuggestion:  imdbSuggestionPenalty,
+}
+
+// penalizedConfidence lowers a match's confidence by its method's penalty,
+// never below 0
+func penalizedConfidence(method string, confidence float64) float64 {
+	return max(confidence-matchPenalties[method], 0)
+}
+
+// normalizeTitle lowercases a title and reduces it to letters, digits and single spaces
+func normalizeTitle(title string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			space = false
+		case r == '\'' || r == '’':
+			// Drop apostrophes so "Hasn't" and "Hasnt" compare equal
+		default:
+			if !space && b.Len() > 0 {
+				b.WriteByte(' ')
+				space = true
+			}
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// titleSimilarity returns a score between 0 and 1 for how closely two titles match,
+// based on the edit distance between their normalized forms
+func titleSimilarity(a, b string) float64 {
+	ra := []rune(normalizeTitle(a))
+	rb := []rune(normalizeTitle(b))
+
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein computes the edit distance between two rune slices
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+// selectCandidate picks the search result to use for a title: the first result
+// whose normalized title or original title matches the query, falling back to
+// TMDB's top result
+func selectCandidate(title string, candidates []TMDBMovie) TMDBMovie {
+	query := normalizeTitle(title)
+	for _, candidate := range candidates {
+		if normalizeTitle(candidate.Title) == query || normalizeTitle(candidate.OriginalTitle) == query {
+			return candidate
+		}
+	}
+	return candidates[0]
+}
+
+// Result selection strategies accepted by -match-strategy
+const (
+	matchStrategyFirst                = "first"
+	matchStrategyMostPopular          = "most-popular"
+	matchStrategyHighestVoted         = "highest-voted"
+	matchStrategyExactYearThenPopular = "exact-year-then-popular"
+)
+
+// matchQuery describes the wiki title being resolved
+type matchQuery struct {
+	Title string
+	Year  int // release year written next to the title on the wiki, or 0
+
+	// TieBreak is the -year-tiebreak chain for candidates released in Year,
+	// or nil for the default
+	TieBreak []string
+}
+
+// matchStrategy picks the search result to use from a non-empty list of
+// candidates in TMDB's order
+type matchStrategy func(query matchQuery, candidates []TMDBMovie) TMDBMovie
+
+// matchStrategies maps -match-strategy names to their implementations
+var matchStrategies = map[string]matchStrategy{
+	matchStrategyFirst:                selectFirst,
+	matchStrategyMostPopular:          selectMostPopular,
+	matchStrategyHighestVoted:         selectHighestVoted,
+	matchStrategyExactYearThenPopular: selectExactYearThenPopular,
+}
+
+// selectMatch applies the configured strategy, defaulting to first. With
+// -auto-confirm, the only exact title match from the wiki's year is accepted
+// before any strategy runs, and selectMatch reports that it was.
+func (s *Scraper) selectMatch(query matchQuery, candidates []TMDBMovie) (TMDBMovie, bool) {
+	if match, ok := autoConfirm(query, candidates); ok && s.config.AutoConfirm {
+		s.explainf("Auto-confirmed exact title and year: %s (%s) tmdb=%d", match.Title, candidateYear(match), match.ID)
+		s.logCandidates(query, match, candidates)
+		return match, true
+	}
+
+	name := s.config.MatchStrategy
+	strategy, ok := matchStrategies[name]
+	if !ok {
+		name, strategy = matchStrategyFirst, selectFirst
+	}
+	query.TieBreak = s.config.yearTieBreak()
+	match := strategy(query, candidates)
+	s.explainf("Strategy %s chose: %s (%s) tmdb=%d", name, match.Title, candidateYear(match), match.ID)
+	s.logCandidates(query, match, candidates)
+	return match, false
+}
+
+// autoConfirm returns the one candidate whose title or original title
+// matches exactly and that was released in the year given on the wiki.
+// There is nothing for a strategy to weigh against such a match. It reports
+// false without a year, or when several candidates qualify.
+func autoConfirm(query matchQuery, candidates []TMDBMovie) (TMDBMovie, bool) {
+	matches := exactYearMatches(query, candidates)
+	if len(matches) != 1 {
+		return TMDBMovie{}, false
+	}
+	return matches[0], true
+}
+
+// yearConstrained reports whether the wiki's year decided a match: the match
+// has the query's exact title and year, and another candidate with the exact
+// title was released in a different year
+func yearConstrained(query matchQuery, candidates []TMDBMovie, match TMDBMovie) bool {
+	chosen := false
+	for _, candidate := range exactYearMatches(query, candidates) {
+		if candidate.ID == match.ID {
+			chosen = true
+			break
+		}
+	}
+	if !chosen {
+		return false
+	}
+
+	title := normalizeTitle(query.Title)
+	for _, candidate := range candidates {
+		exact := normalizeTitle(candidate.Title) == title || normalizeTitle(candidate.OriginalTitle) == title
+		if exact && (candidate.ReleaseDate.IsZero() || candidate.ReleaseDate.Year() != query.Year) {
+			return true
+		}
+	}
+	return false
+}
+
+// exactYearMatches returns the candidates whose title or original title
+// matches exactly and that were released in the year given on the wiki
+func exactYearMatches(query matchQuery, candidates []TMDBMovie) []TMDBMovie {
+	if query.Year <= 0 {
+		return nil
+	}
+	title := normalizeTitle(query.Title)
+	var matches []TMDBMovie
+	for _, candidate := range candidates {
+		exact := normalizeTitle(candidate.Title) == title || normalizeTitle(candidate.OriginalTitle) == title
+		if exact && !candidate.ReleaseDate.IsZero() && candidate.ReleaseDate.Year() == query.Year {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
+
+// selectFirst prefers an exact title match released in the year given on the
+// wiki, then any exact title match, then TMDB's top result. Several exact
+// matches from that year are told apart by the tie-break chain.
+func selectFirst(query matchQuery, candidates []TMDBMovie) TMDBMovie {
+	if sameYear := exactYearMatches(query, candidates); len(sameYear) > 0 {
+		return breakTie(query.TieBreak, sameYear)
+	}
+	return selectCandidate(query.Title, candidates)
+}
+
+// selectMostPopular picks the candidate with the highest TMDB popularity,
+// keeping TMDB's order on ties
+func selectMostPopular(query matchQuery, candidates []TMDBMovie) TMDBMovie {
+	best := candidates[0]
+	for _, candidate := range candidates[1:] {
+		if candidate.Popularity > best.Popularity {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// selectHighestVoted picks the candidate with the most TMDB votes, keeping
+// TMDB's order on ties
+func selectHighestVoted(query matchQuery, candidates []TMDBMovie) TMDBMovie {
+	best := candidates[0]
+	for _, candidate := range candidates[1:] {
+		if candidate.VoteCount > best.VoteCount {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// selectExactYearThenPopular picks the most popular candidate released in the
+// year given on the wiki, breaking popularity ties with the tie-break chain,
+// and falls back to the most popular candidate overall
+func selectExactYearThenPopular(query matchQuery, candidates []TMDBMovie) TMDBMovie {
+	if query.Year > 0 {
+		var sameYear []TMDBMovie
+		for _, candidate := range candidates {
+			if !candidate.ReleaseDate.IsZero() && candidate.ReleaseDate.Year() == query.Year {
+				sameYear = append(sameYear, candidate)
+			}
+		}
+		if len(sameYear) > 0 {
+			popularity := selectMostPopular(query, sameYear).Popularity
+			var tied []TMDBMovie
+			for _, candidate := range sameYear {
+				if candidate.Popularity == popularity {
+					tied = append(tied, candidate)
+				}
+			}
+			return breakTie(query.TieBreak, tied)
+		}
+	}
+	return selectMostPopular(query, candidates)
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestTitleSimilarity(t *testing.T) {
+	testCases := []struct {
+		a, b string
+		min  float64
+		max  float64
+	}{
+		{"Space Jam", "Space Jam", 1, 1},
+		{"space jam!", "Space Jam", 1, 1},
+		{"Scott Hasn't Seen", "Scott Hasnt Seen", 1, 1},
+		{"The Addams Family", "Addams Family", 0.7, 0.8},
+		{"Dune", "Ghost", 0, 0.3},
+	}
+
+	for _, tc := range testCases {
+		got := titleSimilarity(tc.a, tc.b)
+		if got < tc.min || got > tc.max {
+			t.Errorf("titleSimilarity(%q, %q) = %.2f, want between %.2f and %.2f", tc.a, tc.b, got, tc.min, tc.max)
+		}
+	}
+}
+
+func TestPrepareForOutputHidesMatchInfo(t *testing.T) {
+	movies := []Movie{{Title: "Dune", MatchConfidence: 0.9, MatchMethod: matchMethodExact}}
+
+	hidden := NewScraper("dummy_key").prepareForOutput(movies)
+	if hidden[0].MatchConfidence != 0 || hidden[0].MatchMethod != "" {
+		t.Errorf("Expected match info to be cleared by default, got %+v", hidden[0])
+	}
+
+	cfg := defaultConfig()
+	cfg.IncludeMatchInfo = true
+	shown := NewScraper("dummy_key", WithConfig(cfg)).prepareForOutput(movies)
+	if shown[0].MatchConfidence != 0.9 || shown[0].MatchMethod != matchMethodExact {
+		t.Errorf("Expected match info to be kept with -include-match-info, got %+v", shown[0])
+	}
+}
+
+func TestSelectCandidate(t *testing.T) {
+	candidates := []TMDBMovie{
+		{ID: 1, Title: "Ghost Story"},
+		{ID: 2, Title: "Ghost"},
+		{ID: 3, Title: "Ghost"},
+	}
+
+	if got := selectCandidate("Ghost", candidates); got.ID != 2 {
+		t.Errorf("Expected first exact title match (ID 2), got ID %d", got.ID)
+	}
+
+	if got := selectCandidate("Ghostbusters", candidates); got.ID != 1 {
+		t.Errorf("Expected fallback to top result (ID 1), got ID %d", got.ID)
+	}
+}
+
+func TestMatchStrategies(t *testing.T) {
+	year := func(y int) time.Time { return time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC) }
+	candidates := []TMDBMovie{
+		{ID: 1, Title: "Dune: Part Two", ReleaseDate: year(2024), Popularity: 90, VoteCount: 5000},
+		{ID: 841, Title: "Dune", ReleaseDate: year(1984), Popularity: 30, VoteCount: 4000},
+		{ID: 438631, Title: "Dune", ReleaseDate: year(2021), Popularity: 80, VoteCount: 12000},
+		{ID: 2, Title: "Dune Drifter", ReleaseDate: year(2020), Popularity: 5, VoteCount: 10},
+	}
+
+	testCases := []struct {
+		strategy string
+		query    matchQuery
+		expected int
+	}{
+		{matchStrategyFirst, matchQuery{Title: "Dune"}, 841},
+		{matchStrategyFirst, matchQuery{Title: "Arrakis"}, 1},
+		{matchStrategyFirst, matchQuery{Title: "Dune", Year: 2021}, 438631},
+		{matchStrategyFirst, matchQuery{Title: "Dune", Year: 1999}, 841},
+		{matchStrategyMostPopular, matchQuery{Title: "Dune"}, 1},
+		{matchStrategyHighestVoted, matchQuery{Title: "Dune"}, 438631},
+		{matchStrategyExactYearThenPopular, matchQuery{Title: "Dune", Year: 1984}, 841},
+		{matchStrategyExactYearThenPopular, matchQuery{Title: "Dune", Year: 1999}, 1},
+		{matchStrategyExactYearThenPopular, matchQuery{Title: "Dune"}, 1},
+	}
+
+	for _, tc := range testCases {
+		cfg := defaultConfig()
+		cfg.MatchStrategy = tc.strategy
+		got, _ := NewScraper("dummy_key", WithConfig(cfg)).selectMatch(tc.query, candidates)
+		if got.ID != tc.expected {
+			t.Errorf("%s %+v: expected TMDB ID %d, got %d", tc.strategy, tc.query, tc.expected, got.ID)
+		}
+	}
+
+	if _, err := parseFlags([]string{"-match-strategy", "random"}); err == nil {
+		t.Error("Expected an unknown match strategy to be rejected")
+	}
+}
+
+func TestLogCandidates(t *testing.T) {
+	year := func(y int) time.Time { return time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC) }
+	candidates := []TMDBMovie{
+		{ID: 1, Title: "Dune: Part Two", ReleaseDate: year(2024), Popularity: 90, VoteCount: 5000},
+		{ID: 841, Title: "Dune", ReleaseDate: year(1984), Popularity: 30, VoteCount: 4000},
+		{ID: 438631, Title: "Dune", ReleaseDate: year(2021), Popularity: 80, VoteCount: 12000},
+		{ID: 2, Title: "Dune Drifter", ReleaseDate: year(2020), Popularity: 5, VoteCount: 10},
+	}
+
+	cfg := defaultConfig()
+	cfg.LogLevel = logLevelDebug
+	cfg.LogCandidates = 2
+	scraper := NewScraper("dummy_key", WithConfig(cfg))
+	output := captureStdout(t, func() {
+		scraper.selectMatch(matchQuery{Title: "Dune"}, candidates)
+	})
+
+	expected := `[debug] candidates for "Dune": chose Dune (1984) tmdb=841 similarity=1.00 popularity=30.0 votes=4000
+  rejected Dune: Part Two (2024) tmdb=1 similarity=0.31 popularity=90.0 votes=5000
+  rejected Dune (2021) tmdb=438631 similarity=1.00 popularity=80.0 votes=12000
+  ... 1 more rejected
+`
+	if output != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, output)
+	}
+
+	// Without the flag nothing is logged, even at debug level
+	scraper.config.LogCandidates = 0
+	if output := captureStdout(t, func() { scraper.selectMatch(matchQuery{Title: "Dune"}, candidates) }); output != "" {
+		t.Errorf("Expected no output, got %q", output)
+	}
+
+	if _, err := parseFlags([]string{"-log-candidates", "3"}); err == nil {
+		t.Error("Expected -log-candidates without -log-level debug to be rejected")
+	}
+	if _, err := parseFlags([]string{"-log-candidates", "3", "-log-level", "debug"}); err != nil {
+		t.Errorf("Expected -log-candidates with -log-level debug to be accepted: %v", err)
+	}
+}
+
+func TestReleaseYearHint(t *testing.T) {
+	testCases := map[string]int{
+		"Dune (1984)":           1984,
+		" Dune (2021)[1] ":      2021,
+		"Dune":                  0,
+		"2001: A Space Odyssey": 0,
+	}
+	for raw, expected := range testCases {
+		if got := releaseYearHint(raw); got != expected {
+			t.Errorf("releaseYearHint(%q) = %d, expected %d", raw, got, expected)
+		}
+	}
+}
+
+// benchmarkCandidates returns a search result page far larger than TMDB's, with
+// near-miss titles, a spread of years and popularity, and the exact title last
+func benchmarkCandidates(n int) []TMDBMovie {
+	candidates := make([]TMDBMovie, 0, n)
+	for i := 0; i < n-1; i++ {
+		candidates = append(candidates, TMDBMovie{
+			ID:          i + 1,
+			Title:       fmt.Sprintf("The Addams Family %d: Reunion", i),
+			ReleaseDate: time.Date(1950+i%70, 1, 1, 0, 0, 0, 0, time.UTC),
+			Popularity:  float64(i%97) / 3,
+			VoteCount:   i % 1013,
+		})
+	}
+	candidates = append(candidates, TMDBMovie{ID: n, Title: "The Addams Family", ReleaseDate: time.Date(1991, 11, 22, 0, 0, 0, 0, time.UTC)})
+	return candidates
+}
+
+func BenchmarkSelectMatch(b *testing.B) {
+	candidates := benchmarkCandidates(1000)
+	query := matchQuery{Title: "The Addams Family", Year: 1991}
+
+	names := make([]string, 0, len(matchStrategies))
+	for name := range matchStrategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		strategy := matchStrategies[name]
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				strategy(query, candidates)
+			}
+		})
+	}
+}
+
+func BenchmarkTitleSimilarity(b *testing.B) {
+	candidates := benchmarkCandidates(1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, candidate := range candidates {
+			titleSimilarity("The Addams Family", candidate.Title)
+		}
+	}
+}
+
+func TestAutoConfirm(t *testing.T) {
+	year := func(y int) time.Time { return time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC) }
+	// The remake is far more popular, and its localized title matches only by
+	// original title
+	candidates := []TMDBMovie{
+		{ID: 438631, Title: "Dune", ReleaseDate: year(2021), Popularity: 80},
+		{ID: 841, Title: "Der Wüstenplanet", OriginalTitle: "Dune", ReleaseDate: year(1984), Popularity: 30},
+	}
+
+	cfg := defaultConfig()
+	cfg.MatchStrategy = matchStrategyMostPopular
+	scraper := NewScraper("dummy_key", WithConfig(cfg))
+	if got, confirmed := scraper.selectMatch(matchQuery{Title: "dune", Year: 1984}, candidates); !confirmed || got.ID != 841 {
+		t.Errorf("Expected the 1984 film to be auto-confirmed, got %d (confirmed %v)", got.ID, confirmed)
+	}
+
+	// Without a year, or with the fast path off, the strategy decides
+	if got, confirmed := scraper.selectMatch(matchQuery{Title: "Dune"}, candidates); confirmed || got.ID != 438631 {
+		t.Errorf("Expected most-popular to choose without a year, got %d (confirmed %v)", got.ID, confirmed)
+	}
+	scraper.config.AutoConfirm = false
+	if got, confirmed := scraper.selectMatch(matchQuery{Title: "Dune", Year: 1984}, candidates); confirmed || got.ID != 438631 {
+		t.Errorf("Expected -auto-confirm=false to leave it to the strategy, got %d (confirmed %v)", got.ID, confirmed)
+	}
+
+	// Two exact matches from the same year aren't obviously right
+	twins := append(candidates, TMDBMovie{ID: 900001, Title: "Dune", ReleaseDate: year(2021)})
+	if _, ok := autoConfirm(matchQuery{Title: "Dune", Year: 2021}, twins); ok {
+		t.Error("Expected several same-year exact matches not to be auto-confirmed")
+	}
+}
+
+func TestSearchMovieYearConstrained(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/movie", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("query") == "Space Jam" {
+			w.Write([]byte(`{"page": 1, "results": [{"id": 2300, "title": "Space Jam", "release_date": "1996-11-15"}], "total_pages": 1, "total_results": 1}`))
+			return
+		}
+		w.Write([]byte(`{"page": 1, "results": [
+			{"id": 438631, "title": "Dune", "release_date": "2021-09-15"},
+			{"id": 841, "title": "Dune", "release_date": "1984-12-14"}
+		], "total_pages": 1, "total_results": 2}`))
+	})
+	mux.HandleFunc("/movie/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"imdb_id": "tt0087182"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cfg := defaultConfig()
+	cfg.TMDBBaseURL = server.URL
+	scraper := NewScraper("dummy_key", WithConfig(cfg))
+	scraper.recordYearHint("Dune", 1984)
+	scraper.recordYearHint("Space Jam", 1996)
+
+	// The year told the two Dunes apart; Space Jam's title was enough
+	if movie, err := scraper.searchMovie("Dune"); err != nil || movie.TMDBID != 841 || movie.MatchMethod != matchMethodYearConstrained {
+		t.Errorf("Expected the 1984 Dune as a year-constrained match, got %+v (%v)", movie, err)
+	}
+	if movie, err := scraper.searchMovie("Space Jam"); err != nil || movie.MatchMethod != matchMethodExact {
+		t.Errorf("Expected an exact match, got %+v (%v)", movie, err)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// mergeManual combines freshly resolved movies with the entries marked manual
+// in an existing list. A manual entry replaces a fresh movie with the same ID
+// in its place, so hand edits win; the others are added at the end. The list
+// is then sorted with compare, or left in that order if it is nil, as with
+// -no-sort. Returns the merged list and the number of manual entries.
+func mergeManual(existing, fresh []Movie, compare func(a, b Movie) int) ([]Movie, int) {
+	manual := make(map[string]Movie)
+	var order []string
+	for _, movie := range existing {
+		if !movie.Manual {
+			continue
+		}
+		key := movieKey(movie)
+		if _, ok := manual[key]; !ok {
+			order = append(order, key)
+		}
+		manual[key] = movie
+	}
+
+	merged := make([]Movie, 0, len(fresh)+len(manual))
+	replaced := make(map[string]bool)
+	for _, movie := range fresh {
+		key := movieKey(movie)
+		if entry, ok := manual[key]; ok {
+			if !replaced[key] {
+				merged = append(merged, entry)
+			}
+			replaced[key] = true
+			continue
+		}
+		merged = append(merged, movie)
+	}
+	for _, key := range order {
+		if !replaced[key] {
+			merged = append(merged, manual[key])
+		}
+	}
+
+	if compare != nil {
+		sortWith(merged, compare)
+	}
+	return merged, len(order)
+}
+
+// mergeWithFile merges the manual entries from a previously written list
+// file into movies. A missing file has no manual entries.
+func (s *Scraper) mergeWithFile(movies []Movie, filename string) ([]Movie, error) {
+	existing, err := loadMovies(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return movies, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	merged, manual := mergeManual(existing, movies, s.sortComparator())
+	fmt.Fprintf(s.logWriter(), "Kept %d manual entries from %s\n", manual, filename)
+	return merged, nil
+}
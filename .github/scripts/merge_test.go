@@ -0,0 +1,67 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMergeManual(t *testing.T) {
+	existing := []Movie{
+		{Title: "Space Jam", IMDBID: "tt0117705"},
+		{Title: "Fan Favourite", IMDBID: "tt0000001", Manual: true},
+		{Title: "Ghost (Director's Cut)", IMDBID: "tt0099653", Manual: true},
+	}
+	fresh := []Movie{
+		{Title: "Ghost", IMDBID: "tt0099653", Year: 1990},
+		{Title: "Dune", IMDBID: "tt0087182", Year: 1984},
+	}
+
+	merged, manual := mergeManual(existing, fresh, compareMovies)
+	if manual != 2 {
+		t.Errorf("Expected 2 manual entries, got %d", manual)
+	}
+
+	// Space Jam wasn't manual and is gone; the manual Ghost replaces the fresh one
+	expected := []string{"Dune", "Fan Favourite", "Ghost (Director's Cut)"}
+	if len(merged) != len(expected) {
+		t.Fatalf("Expected %v, got %+v", expected, merged)
+	}
+	for i, title := range expected {
+		if merged[i].Title != title {
+			t.Errorf("Position %d: expected %s, got %s", i, title, merged[i].Title)
+		}
+	}
+
+	// Without a comparator, as with -no-sort, the fresh order is kept
+	merged, _ = mergeManual(existing, fresh, nil)
+	expected = []string{"Ghost (Director's Cut)", "Dune", "Fan Favourite"}
+	for i, title := range expected {
+		if merged[i].Title != title {
+			t.Errorf("-no-sort position %d: expected %s, got %s", i, title, merged[i].Title)
+		}
+	}
+}
+
+func TestMergeWithFile(t *testing.T) {
+	dir := t.TempDir()
+	fresh := []Movie{{Title: "Dune", IMDBID: "tt0087182"}}
+
+	merged, err := NewScraper("dummy_key").mergeWithFile(fresh, filepath.Join(dir, "missing.json"))
+	if err != nil || len(merged) != 1 {
+		t.Errorf("Expected a missing file to leave the list unchanged, got %+v (%v)", merged, err)
+	}
+
+	filename := filepath.Join(dir, "scott_hasnt_seen.json")
+	existing := []Movie{{Title: "Fan Favourite", IMDBID: "tt0000001", Manual: true}}
+	if err := NewScraper("dummy_key").saveToFile(existing, filename); err != nil {
+		t.Fatalf("Failed to save list: %v", err)
+	}
+
+	merged, err = NewScraper("dummy_key").mergeWithFile(fresh, filename)
+	if err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+	if len(merged) != 2 || !merged[1].Manual {
+		t.Errorf("Expected the manual entry to survive the save round trip, got %+v", merged)
+	}
+}
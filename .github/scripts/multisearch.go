@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Media types in TMDB multi-search results
+const (
+	mediaTypeMovie = "movie"
+	mediaTypeTV    = "tv"
+)
+
+// strongTVSimilarity is the title similarity at which a TV result found by
+// -multi-search is surfaced for review
+const strongTVSimilarity = 0.9
+
+// TMDBTVShow is a TV result from TMDB multi-search
+type TMDBTVShow struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	OriginalName string `json:"original_name"`
+	FirstAirDate string `json:"first_air_date"`
+}
+
+// firstAirYear returns the year the show first aired, or 0 if unknown
+func (t TMDBTVShow) firstAirYear() int {
+	date, err := time.Parse("2006-01-02", t.FirstAirDate)
+	if err != nil {
+		return 0
+	}
+	return date.Year()
+}
+
+// TVMatch records a title whose multi-search found a TV result closely
+// matching it. The movie search still wins, but the wiki may mean the show.
+type TVMatch struct {
+	Title        string `json:"title"`
+	TVName       string `json:"tv_name"`
+	TMDBTVID     int    `json:"tmdb_tv_id"`
+	FirstAirYear int    `json:"first_air_year,omitempty"`
+	MovieResults int    `json:"movie_results"`
+}
+
+// tvMatches collects TV matches from the worker pool
+type tvMatches struct {
+	mu      sync.Mutex
+	matches []TVMatch
+	seen    map[string]bool
+}
+
+func newTVMatches() *tvMatches {
+	return &tvMatches{seen: make(map[string]bool)}
+}
+
+// add records a match once per title and show
+func (t *tvMatches) add(match TVMatch) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := match.Title + "\x00" + strconv.Itoa(match.TMDBTVID)
+	if t.seen[key] {
+		return
+	}
+	t.seen[key] = true
+	t.matches = append(t.matches, match)
+}
+
+// sorted returns the matches ordered by title
+func (t *tvMatches) sorted() []TVMatch {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	matches := append([]TVMatch(nil), t.matches...)
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Title < matches[j].Title })
+	return matches
+}
+
+// multiResponse is a page of TMDB multi-search results. Results are decoded
+// one at a time because their shape depends on media_type.
+type multiResponse struct {
+	Results      []json.RawMessage `json:"results"`
+	TotalPages   int               `json:"total_pages"`
+	TotalResults int               `json:"total_results"`
+}
+
+// splitMultiResults separates multi-search results into movies and TV shows,
+// skipping people and any media type TMDB adds later. A result that doesn't
+// decode is skipped rather than failing the whole page.
+func splitMultiResults(results []json.RawMessage) ([]TMDBMovie, []TMDBTVShow) {
+	var movies []TMDBMovie
+	var shows []TMDBTVShow
+	for _, raw := range results {
+		var kind struct {
+			MediaType string `json:"media_type"`
+		}
+		if err := json.Unmarshal(raw, &kind); err != nil {
+			continue
+		}
+
+		switch kind.MediaType {
+		case mediaTypeMovie:
+			var movie TMDBMovie
+			if err := json.Unmarshal(raw, &movie); err == nil {
+				movies = append(movies, movie)
+			}
+		case mediaTypeTV:
+			var show TMDBTVShow
+			if err := json.Unmarshal(raw, &show); err == nil {
+				shows = append(shows, show)
+			}
+		}
+	}
+	return movies, shows
+}
+
+// searchMultiPage fetches a single page of TMDB multi-search results,
+// returning the movies as a movie search page along with the TV shows
+func (s *Scraper) searchMultiPage(title string, page int) (*TMDBResponse, []TMDBTVShow, error) {
+	searchURL := fmt.Sprintf("%s/search/multi", s.tmdbBaseURL)
+
+	params := url.Values{}
+	params.Add("api_key", s.tmdbAPIKey)
+	params.Add("query", title)
+	params.Add("language", s.config.Language)
+	params.Add("page", strconv.Itoa(page))
+	params.Add("include_adult", strconv.FormatBool(s.config.IncludeAdult))
+
+	req, err := http.NewRequest("GET", searchURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.doRequest(req, endpointSearchMulti, title)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to search '%s': %w", title, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, &tmdbStatusError{StatusCode: resp.StatusCode, Target: fmt.Sprintf("'%s'", title)}
+	}
+
+	var multi multiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&multi); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode TMDB response: %w", err)
+	}
+
+	movies, shows := splitMultiResults(multi.Results)
+	return &TMDBResponse{
+		Page:         page,
+		Results:      movies,
+		TotalPages:   multi.TotalPages,
+		TotalResults: multi.TotalResults,
+	}, shows, nil
+}
+
+// reviewTVResults surfaces the TV shows whose name closely matches the title
+func (s *Scraper) reviewTVResults(title string, shows []TMDBTVShow, movieResults int) {
+	for _, show := range shows {
+		similarity := titleSimilarity(title, show.Name)
+		if original := titleSimilarity(title, show.OriginalName); original > similarity {
+			similarity = original
+		}
+		if similarity < strongTVSimilarity {
+			continue
+		}
+
+		s.explainf("Strong TV match for review: %s (%d) tmdb_tv=%d", show.Name, show.firstAirYear(), show.ID)
+		s.tvMatches.add(TVMatch{
+			Title:        title,
+			TVName:       show.Name,
+			TMDBTVID:     show.ID,
+			FirstAirYear: show.firstAirYear(),
+			MovieResults: movieResults,
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSplitMultiResults(t *testing.T) {
+	var results []json.RawMessage
+	json.Unmarshal([]byte(`[
+		{"media_type": "person", "id": 1, "name": "Scott Aukerman"},
+		{"media_type": "tv", "id": 1920, "name": "Twin Peaks", "first_air_date": "1990-04-08"},
+		{"media_type": "movie", "id": 1923, "title": "Twin Peaks: Fire Walk with Me", "release_date": "1992-05-16"},
+		{"media_type": "collection", "id": 5},
+		{"media_type": "tv", "id": "not a number"}
+	]`), &results)
+
+	movies, shows := splitMultiResults(results)
+	if len(movies) != 1 || movies[0].ID != 1923 || movies[0].ReleaseDate.Year() != 1992 {
+		t.Errorf("Expected the one movie with its release date, got %+v", movies)
+	}
+	if len(shows) != 1 || shows[0].Name != "Twin Peaks" || shows[0].firstAirYear() != 1990 {
+		t.Errorf("Expected the one well-formed show, got %+v", shows)
+	}
+}
+
+func TestMultiSearch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/multi", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"page": 1, "total_pages": 1, "total_results": 3, "results": [
+			{"media_type": "tv", "id": 1920, "name": "Twin Peaks", "first_air_date": "1990-04-08"},
+			{"media_type": "tv", "id": 4000, "name": "Twin Peaks Behind the Scenes"},
+			{"media_type": "movie", "id": 1923, "title": "Twin Peaks: Fire Walk with Me", "release_date": "1992-05-16"}
+		]}`))
+	})
+	mux.HandleFunc("/search/movie", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected -multi-search to skip the movie search")
+	})
+	mux.HandleFunc("/movie/1923/external_ids", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"imdb_id": "tt0105665"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cfg := defaultConfig()
+	cfg.MultiSearch = true
+	cfg.TMDBBaseURL = server.URL
+	scraper := NewScraper("dummy_key", WithConfig(cfg))
+
+	movie, err := scraper.searchMovieExact("Twin Peaks")
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if movie.IMDBID != "tt0105665" {
+		t.Errorf("Expected the movie to be preferred over the show, got %+v", movie)
+	}
+
+	matches := scraper.tvMatches.sorted()
+	if len(matches) != 1 || matches[0].TMDBTVID != 1920 || matches[0].FirstAirYear != 1990 || matches[0].MovieResults != 1 {
+		t.Errorf("Expected the exact TV match to be surfaced for review, got %+v", matches)
+	}
+
+	// Searching again doesn't list the show twice
+	scraper.searchMovieExact("Twin Peaks")
+	if len(scraper.tvMatches.sorted()) != 1 {
+		t.Errorf("Expected TV matches to be recorded once per title, got %+v", scraper.tvMatches.sorted())
+	}
+}
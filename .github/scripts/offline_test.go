@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFixture writes a fixture file, creating its directories
+func writeFixture(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create fixture directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+}
+
+func newOfflineScraper(dir string) *Scraper {
+	cfg := defaultConfig()
+	cfg.StateFile = ""
+	return NewScraper(offlineAPIKey, WithConfig(cfg), WithOffline(dir))
+}
+
+func TestOfflineRun(t *testing.T) {
+	dir := t.TempDir()
+	writeFixture(t, dir, "wiki.html", "<html><body><table><tr><td><i>Space Jam</i></td></tr><tr><td><i>Ghost</i></td></tr></table></body></html>")
+	writeFixture(t, dir, "tmdb/search/movie/include_adult=false&language=en-US&page=1&query=Space+Jam.json",
+		`{"page":1,"results":[{"id":2300,"title":"Space Jam","release_date":"1996-11-15","poster_path":"/2300.jpg"}],"total_pages":1,"total_results":1}`)
+	writeFixture(t, dir, "tmdb/movie/2300/external_ids.json", `{"imdb_id":"tt0117705"}`)
+	writeFixture(t, dir, "tmdb/search/movie/include_adult=false&language=en-US&page=1&query=Ghost.json",
+		`{"page":1,"results":[{"id":251,"title":"Ghost","release_date":"1990-07-13"}],"total_pages":1,"total_results":1}`)
+	writeFixture(t, dir, "tmdb/movie/251/external_ids.json", `{"imdb_id":"tt0099653"}`)
+
+	scraper := newOfflineScraper(dir)
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list offline: %v", err)
+	}
+	if len(movies) != 2 || movies[0].IMDBID != "tt0099653" || movies[1].IMDBID != "tt0117705" {
+		t.Errorf("Expected Ghost and Space Jam from the fixtures, got %+v", movies)
+	}
+	if len(scraper.failures) != 0 {
+		t.Errorf("Expected no failures, got %+v", scraper.failures)
+	}
+}
+
+func TestOfflineMissingFixture(t *testing.T) {
+	dir := t.TempDir()
+	writeFixture(t, dir, "wiki.html", "<html><body><i>The Addams Family</i></body></html>")
+
+	scraper := newOfflineScraper(dir)
+	if _, err := scraper.generateRadarrList(context.Background()); err != nil {
+		t.Fatalf("Failed to generate list offline: %v", err)
+	}
+	if len(scraper.failures) != 1 {
+		t.Fatalf("Expected the title without fixtures to fail, got %+v", scraper.failures)
+	}
+	expected := filepath.Join(dir, "tmdb", "search", "movie", "include_adult=false&language=en-US&page=1&query=The+Addams+Family.json")
+	if !strings.Contains(scraper.failures[0].Error, "missing fixture "+expected) {
+		t.Errorf("Expected the error to name %s, got %s", expected, scraper.failures[0].Error)
+	}
+
+	// Without the wiki fixture the run can't start
+	if _, err := newOfflineScraper(t.TempDir()).generateRadarrList(context.Background()); err == nil || !strings.Contains(err.Error(), wikiFixture) {
+		t.Errorf("Expected an error naming %s, got %v", wikiFixture, err)
+	}
+}
+
+func TestParseFlagsOffline(t *testing.T) {
+	cfg, err := parseFlags([]string{"-offline", "fixtures"})
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if !cfg.Force || cfg.StateFile != "" {
+		t.Errorf("Expected offline runs to force and skip the state file, got %+v", cfg)
+	}
+}
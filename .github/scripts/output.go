@@ -0,0 +1,440 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// radarrMovie is the StevenLu custom list entry that Radarr imports
+type radarrMovie struct {
+	Title     string `json:"title"`
+	IMDBID    string `json:"imdb_id"`
+	PosterURL string `json:"poster_url,omitempty"`
+}
+
+// wrappedListVersion is the schema version written in the wrapped format
+const wrappedListVersion = 1
+
+// wrappedList is the wrapped output format: the native entries inside an
+// object that can also describe the list
+type wrappedList struct {
+	Version int     `json:"version"`
+	Name    string  `json:"name,omitempty"`
+	RunID   string  `json:"run_id,omitempty"`
+	Movies  []Movie `json:"movies"`
+}
+
+// newWrappedList wraps movies, writing an empty list as [] rather than null
+func newWrappedList(movies []Movie, name string) wrappedList {
+	if movies == nil {
+		movies = []Movie{}
+	}
+	return wrappedList{Version: wrappedListVersion, Name: name, Movies: movies}
+}
+
+// encodeList prepares movies for output and renders them in the configured
+// format, naming the list with -list-name and recording the run ID in the
+// wrapped format
+func (s *Scraper) encodeList(movies []Movie) ([]byte, error) {
+	return s.encodeListAs(movies, s.config.Format, s.runID)
+}
+
+// encodeListAs is encodeList in the given format, with the given run ID in
+// place of this run's
+func (s *Scraper) encodeListAs(movies []Movie, format, runID string) ([]byte, error) {
+	movies = s.prepareForOutput(movies)
+	if format == formatWrapped {
+		list := newWrappedList(movies, s.config.ListName)
+		list.RunID = runID
+		return encodeJSON(list)
+	}
+	return encodeMovies(movies, format)
+}
+
+// formatSuffix returns what follows the base name in a format's list files:
+// just the extension for the first -format, so a single format keeps the
+// plain filename, and ".<format>" before the extension for the others, e.g.
+// scott_hasnt_seen.radarr.json
+func (s *Scraper) formatSuffix(format string) string {
+	if format == s.config.Format {
+		return formatExtension(format)
+	}
+	return "." + format + formatExtension(format)
+}
+
+// encodeMovies renders the movie list in the requested format
+func encodeMovies(movies []Movie, format string) ([]byte, error) {
+	switch format {
+	case formatNative:
+		return encodeJSON(movies)
+	case formatWrapped:
+		return encodeJSON(newWrappedList(movies, ""))
+	case formatRadarr:
+		matched := matchedOnly(movies)
+		entries := make([]radarrMovie, 0, len(matched))
+		for _, movie := range matched {
+			// Radarr's custom list import needs an IMDB ID
+			if movie.IMDBID == "" {
+				continue
+			}
+			entries = append(entries, radarrMovie{
+				Title:     movie.Title,
+				IMDBID:    movie.IMDBID,
+				PosterURL: movie.PosterURL,
+			})
+		}
+		return encodeJSON(entries)
+	case formatLetterboxd:
+		return encodeLetterboxd(matchedOnly(movies))
+	case formatIMDBIDs:
+		return encodeIMDBIDs(movies), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// formatExtension returns the file extension for an output format
+func formatExtension(format string) string {
+	switch format {
+	case formatLetterboxd:
+		return ".csv"
+	case formatIMDBIDs:
+		return ".txt"
+	default:
+		return ".json"
+	}
+}
+
+// encodeJSON marshals a value as compact JSON followed by a newline
+func encodeJSON(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// encodeCSV writes a header row and records as CSV
+func encodeCSV(header []string, records [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	if err := w.WriteAll(records); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encodeIMDBIDs renders a sorted, deduplicated list of IMDB IDs, one per line.
+// Movies without an IMDB ID are skipped.
+func encodeIMDBIDs(movies []Movie) []byte {
+	seen := make(map[string]bool, len(movies))
+	ids := make([]string, 0, len(movies))
+	for _, movie := range movies {
+		if movie.IMDBID == "" || seen[movie.IMDBID] {
+			continue
+		}
+		seen[movie.IMDBID] = true
+		ids = append(ids, movie.IMDBID)
+	}
+	sort.Strings(ids)
+
+	var buf bytes.Buffer
+	for _, id := range ids {
+		buf.WriteString(id)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// encodeLetterboxd renders the list in Letterboxd's import CSV format
+func encodeLetterboxd(movies []Movie) ([]byte, error) {
+	records := make([][]string, 0, len(movies))
+	for _, movie := range movies {
+		year := ""
+		if movie.Year > 0 {
+			year = strconv.Itoa(movie.Year)
+		}
+		records = append(records, []string{movie.Title, year, movie.IMDBID})
+	}
+	return encodeCSV([]string{"Title", "Year", "imdbID"}, records)
+}
+
+// matchedOnly returns the movies that are not unmatched placeholders
+func matchedOnly(movies []Movie) []Movie {
+	matched := make([]Movie, 0, len(movies))
+	for _, movie := range movies {
+		if !movie.IsPlaceholder() {
+			matched = append(matched, movie)
+		}
+	}
+	return matched
+}
+
+// withPosters returns the movies that have a poster, keeping unmatched
+// placeholders, along with the number of movies dropped
+func withPosters(movies []Movie) ([]Movie, int) {
+	kept := make([]Movie, 0, len(movies))
+	for _, movie := range movies {
+		if movie.PosterURL != "" || movie.IsPlaceholder() {
+			kept = append(kept, movie)
+		}
+	}
+	return kept, len(movies) - len(kept)
+}
+
+// prepareForOutput returns a copy of the movies with fields cleared that
+// were not requested for output
+func (s *Scraper) prepareForOutput(movies []Movie) []Movie {
+	prepared := make([]Movie, len(movies))
+	for i, movie := range movies {
+		if !s.config.IncludeMatchInfo {
+			movie.MatchConfidence = 0
+			movie.MatchMethod = ""
+			movie.SearchResults = 0
+		}
+		if !s.config.IncludeGuest {
+			movie.Guest = ""
+		}
+		if !s.config.IncludeRawTitle {
+			movie.RawTitle = ""
+		}
+		if !s.config.IncludePopularity {
+			movie.Popularity = 0
+		}
+		if !s.config.FetchCertification {
+			movie.Certification = ""
+		}
+		if !s.config.FetchKeywords {
+			movie.Keywords = nil
+		}
+		if s.config.IncludeOverview {
+			movie.Overview = truncateOverview(movie.Overview, s.config.MaxOverviewLength)
+		} else {
+			movie.Overview = ""
+		}
+		movie.Hash = ""
+		if !movie.IsPlaceholder() {
+			movie.Hash = movieHash(movie)
+		}
+		prepared[i] = movie
+	}
+	return prepared
+}
+
+// truncateOverview shortens a synopsis to at most max characters, cutting at
+// the last word boundary and marking the cut with an ellipsis. A max of 0
+// keeps the synopsis whole.
+func truncateOverview(overview string, max int) string {
+	runes := []rune(overview)
+	if max <= 0 || len(runes) <= max {
+		return overview
+	}
+	cut := string(runes[:max-1])
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
+
+// writeFile writes an output file with the permissions set by -file-mode
+func (s *Scraper) writeFile(filename string, data []byte) error {
+	if strings.HasSuffix(filename, gzipExtension) {
+		compressed, err := gzipBytes(data)
+		if err != nil {
+			return fmt.Errorf("failed to compress %s: %w", filename, err)
+		}
+		data = compressed
+	}
+	return writeFileMode(filename, data, s.config.FileMode)
+}
+
+// gzipExtension is appended to archival filenames written with -gzip
+const gzipExtension = ".gz"
+
+// gzipBytes compresses data with gzip. The whole stream is built in memory so
+// the compressed file can still be written atomically.
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// gunzipBytes decompresses gzip data
+func gunzipBytes(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// writeFileMode atomically replaces a file with data and the given permissions.
+// The data is written to a temporary file in the same directory and renamed
+// into place, so readers never see a partially written file.
+func writeFileMode(filename string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+
+	return os.Rename(tmpName, filename)
+}
+
+// mainOutputBase is the path of the committed list files, without extension
+const mainOutputBase = "../../scott_hasnt_seen"
+
+// writeList writes the list in the configured format to w instead of a file
+func (s *Scraper) writeList(w io.Writer, movies []Movie) error {
+	data, err := s.encodeList(movies)
+	if err != nil {
+		return fmt.Errorf("failed to encode movies: %w", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// outputUnchanged reports whether filename already holds exactly the bytes
+// saveToFile would write for movies. A wrapped list's run ID differs on
+// every run, so the existing file's is kept for the comparison.
+func (s *Scraper) outputUnchanged(movies []Movie, filename string) (bool, error) {
+	existing, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	runID := s.runID
+	if s.config.Format == formatWrapped {
+		var previous wrappedList
+		if json.Unmarshal(existing, &previous) == nil {
+			runID = previous.RunID
+		}
+	}
+
+	data, err := s.encodeListAs(movies, s.config.Format, runID)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode movies: %w", err)
+	}
+	return bytes.Equal(data, existing), nil
+}
+
+// archiveFilename returns the timestamped copy of the main list file with the
+// given extension, compressed with -gzip
+func (s *Scraper) archiveFilename(extension string) string {
+	stamp := s.now().Format(s.config.TimestampFormat)
+	if s.config.RunIDInFilename {
+		stamp += "_" + s.runID
+	}
+	filename := fmt.Sprintf("%s_%s%s", mainOutputBase, stamp, extension)
+	if s.config.Gzip {
+		filename += gzipExtension
+	}
+	return filename
+}
+
+// saveOutputs writes the main list and RSS files to the repository root, plus
+// timestamped copies of both unless -no-timestamp is set. Each extra format
+// given to -format gets its own list files from the same movies. With
+// -write-if-changed nothing is written when the main list would not change.
+// A failed write doesn't stop the others; their errors are returned joined.
+func (s *Scraper) saveOutputs(movies []Movie) error {
+	var errs []error
+	formats := append([]string{s.config.Format}, s.config.ExtraFormats...)
+
+	if s.config.WriteIfChanged && !s.config.Force {
+		unchanged, err := s.outputUnchanged(movies, mainOutputBase+formatExtension(s.config.Format))
+		if err != nil {
+			log.Printf("Failed to compare against the existing list: %v", err)
+		} else if unchanged {
+			fmt.Fprintln(s.logWriter(), "No changes to the list; skipping writes (use -force to rewrite anyway)")
+			return nil
+		}
+	}
+
+	if !s.config.NoTimestamp {
+		var archived []string
+
+		// Save JSON with timestamp
+		for _, format := range formats {
+			jsonFilename := s.archiveFilename(s.formatSuffix(format))
+			fmt.Fprintf(s.logWriter(), "Saving timestamped JSON file to: %s\n", jsonFilename)
+			if err := s.saveToFileAs(movies, jsonFilename, format); err != nil {
+				errs = append(errs, fmt.Errorf("timestamped list: %w", err))
+			} else {
+				archived = append(archived, jsonFilename)
+			}
+		}
+
+		// Save RSS with timestamp
+		rssFilename := s.archiveFilename(".xml")
+		fmt.Fprintf(s.logWriter(), "Saving timestamped RSS file to: %s\n", rssFilename)
+		if err := s.saveToRSS(movies, rssFilename); err != nil {
+			errs = append(errs, fmt.Errorf("timestamped RSS file: %w", err))
+		} else {
+			archived = append(archived, rssFilename)
+		}
+
+		if s.config.ArchiveIndex != "" && len(archived) > 0 {
+			if err := s.updateArchiveIndex(s.config.ArchiveIndex, archived, len(movies)); err != nil {
+				errs = append(errs, fmt.Errorf("archive index: %w", err))
+			}
+		}
+	}
+
+	// Save JSON without timestamp for easy access (in root directory)
+	for _, format := range formats {
+		mainJSONFilename := mainOutputBase + s.formatSuffix(format)
+		fmt.Fprintf(s.logWriter(), "Saving main JSON file to: %s\n", mainJSONFilename)
+		if err := s.saveToFileAs(movies, mainJSONFilename, format); err != nil {
+			errs = append(errs, fmt.Errorf("main list: %w", err))
+		}
+	}
+
+	// Save RSS without timestamp for easy access (in root directory)
+	mainRSSFilename := mainOutputBase + ".xml"
+	fmt.Fprintf(s.logWriter(), "Saving main RSS file to: %s\n", mainRSSFilename)
+	if err := s.saveToRSS(movies, mainRSSFilename); err != nil {
+		errs = append(errs, fmt.Errorf("main RSS file: %w", err))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestEncodeMoviesPlaceholders(t *testing.T) {
+	movies := []Movie{
+		{Title: "Space Jam", IMDBID: "tt0117705", Year: 1996},
+		newPlaceholder("Some Obscure Film"),
+	}
+
+	native, err := encodeMovies(movies, formatNative)
+	if err != nil {
+		t.Fatalf("Failed to encode native format: %v", err)
+	}
+
+	var nativeEntries []map[string]interface{}
+	if err := json.Unmarshal(native, &nativeEntries); err != nil {
+		t.Fatalf("Failed to decode native output: %v", err)
+	}
+	if len(nativeEntries) != 2 {
+		t.Fatalf("Expected 2 native entries, got %d", len(nativeEntries))
+	}
+	if _, ok := nativeEntries[0]["matched"]; ok {
+		t.Errorf("Expected matched movie to omit the matched flag")
+	}
+	if matched, ok := nativeEntries[1]["matched"]; !ok || matched != false {
+		t.Errorf("Expected placeholder to carry matched: false, got %v", nativeEntries[1]["matched"])
+	}
+
+	radarr, err := encodeMovies(movies, formatRadarr)
+	if err != nil {
+		t.Fatalf("Failed to encode radarr format: %v", err)
+	}
+
+	var radarrEntries []radarrMovie
+	if err := json.Unmarshal(radarr, &radarrEntries); err != nil {
+		t.Fatalf("Failed to decode radarr output: %v", err)
+	}
+	if len(radarrEntries) != 1 || radarrEntries[0].IMDBID != "tt0117705" {
+		t.Errorf("Expected radarr output to exclude placeholders, got %+v", radarrEntries)
+	}
+}
+
+func TestEncodeLetterboxd(t *testing.T) {
+	movies := []Movie{
+		{Title: "Space Jam", IMDBID: "tt0117705", Year: 1996},
+		{Title: "Crouching Tiger, Hidden Dragon", IMDBID: "tt0190332", Year: 2000},
+		{Title: "Undated Film", IMDBID: "tt1234567"},
+		newPlaceholder("Some Obscure Film"),
+	}
+
+	data, err := encodeMovies(movies, formatLetterboxd)
+	if err != nil {
+		t.Fatalf("Failed to encode letterboxd format: %v", err)
+	}
+
+	expected := "Title,Year,imdbID\n" +
+		"Space Jam,1996,tt0117705\n" +
+		"\"Crouching Tiger, Hidden Dragon\",2000,tt0190332\n" +
+		"Undated Film,,tt1234567\n"
+	if string(data) != expected {
+		t.Errorf("Unexpected letterboxd CSV:\n%s\nwant:\n%s", data, expected)
+	}
+}
+
+func TestEncodeIMDBIDs(t *testing.T) {
+	movies := []Movie{
+		{Title: "Space Jam", IMDBID: "tt0117705"},
+		{Title: "Dune", IMDBID: "tt0087182"},
+		{Title: "Space Jam (duplicate)", IMDBID: "tt0117705"},
+		{Title: "TMDB Only", TMDBID: 12345},
+		newPlaceholder("Some Obscure Film"),
+	}
+
+	data, err := encodeMovies(movies, formatIMDBIDs)
+	if err != nil {
+		t.Fatalf("Failed to encode imdb-ids format: %v", err)
+	}
+
+	expected := "tt0087182\ntt0117705\n"
+	if string(data) != expected {
+		t.Errorf("Expected %q, got %q", expected, string(data))
+	}
+}
+
+func TestEncodeMoviesOmitsEmptyOptionalFields(t *testing.T) {
+	movies := []Movie{{Title: "Obscure Film", IMDBID: "tt1234567"}}
+
+	for _, format := range []string{formatNative, formatRadarr} {
+		data, err := encodeMovies(movies, format)
+		if err != nil {
+			t.Fatalf("Failed to encode %s format: %v", format, err)
+		}
+
+		var entries []map[string]interface{}
+		if err := json.Unmarshal(data, &entries); err != nil {
+			t.Fatalf("Failed to decode %s output: %v", format, err)
+		}
+		for _, field := range []string{"poster_url", "year", "genres"} {
+			if _, ok := entries[0][field]; ok {
+				t.Errorf("%s format: expected empty %s to be omitted", format, field)
+			}
+		}
+		if _, ok := entries[0]["imdb_id"]; !ok {
+			t.Errorf("%s format: expected imdb_id to always be present", format)
+		}
+	}
+}
+
+func TestEncodeRadarrSkipsMoviesWithoutIMDBID(t *testing.T) {
+	movies := []Movie{
+		{Title: "Ghost", TMDBID: 251},
+		{Title: "Space Jam", IMDBID: "tt0117705", TMDBID: 2300},
+	}
+
+	data, err := encodeMovies(movies, formatRadarr)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	if string(data) != `[{"title":"Space Jam","imdb_id":"tt0117705"}]`+"\n" {
+		t.Errorf("Unexpected radarr output %s", data)
+	}
+}
+
+func TestOutputUnchanged(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "scott_hasnt_seen.json")
+	movies := []Movie{{Title: "Space Jam", IMDBID: "tt0117705", Year: 1996}}
+	scraper := NewScraper("dummy_key")
+
+	if unchanged, err := scraper.outputUnchanged(movies, filename); err != nil || unchanged {
+		t.Errorf("Expected a missing file to count as changed, got %v (%v)", unchanged, err)
+	}
+
+	if err := scraper.saveToFile(movies, filename); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	if unchanged, err := scraper.outputUnchanged(movies, filename); err != nil || !unchanged {
+		t.Errorf("Expected the same list to be unchanged, got %v (%v)", unchanged, err)
+	}
+
+	movies = append(movies, Movie{Title: "Ghost", IMDBID: "tt0099653", Year: 1990})
+	if unchanged, err := scraper.outputUnchanged(movies, filename); err != nil || unchanged {
+		t.Errorf("Expected an added movie to count as changed, got %v (%v)", unchanged, err)
+	}
+}
+
+func TestWriteFileGzip(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "scott_hasnt_seen_20240101_000000.json"+gzipExtension)
+	movies := []Movie{{Title: "Space Jam", IMDBID: "tt0117705", Year: 1996}}
+
+	if err := NewScraper("dummy_key").saveToFile(movies, filename); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read archive: %v", err)
+	}
+	if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+		t.Fatalf("Expected gzip data, got %q", data)
+	}
+
+	loaded, err := loadMovies(filename)
+	if err != nil {
+		t.Fatalf("Failed to load archive: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].IMDBID != "tt0117705" {
+		t.Errorf("Unexpected movies from archive: %+v", loaded)
+	}
+
+	// No temporary files are left behind by the atomic write
+	entries, err := os.ReadDir(filepath.Dir(filename))
+	if err != nil {
+		t.Fatalf("Failed to list directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected only the archive, found %d entries", len(entries))
+	}
+}
+
+func TestRequirePoster(t *testing.T) {
+	catalog := append([]mockMovie{}, mockCatalog...)
+	catalog[2].NoPoster = true // Ghost
+	mock := newMockTMDB(t, []string{"Space Jam", "Ghost", "Unknown Movie"}, catalog)
+
+	cfg := defaultConfig()
+	movies, err := mock.newTestScraper(cfg).generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if len(movies) != 2 {
+		t.Errorf("Expected poster-less movies to be kept by default, got %+v", movies)
+	}
+
+	cfg.RequirePoster = true
+	cfg.KeepUnmatched = true
+	movies, err = mock.newTestScraper(cfg).generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if len(movies) != 2 || movies[0].Title != "Space Jam" || !movies[1].IsPlaceholder() {
+		t.Errorf("Expected Space Jam and the placeholder, got %+v", movies)
+	}
+}
+
+func TestWriteList(t *testing.T) {
+	movies := []Movie{{Title: "Space Jam", IMDBID: "tt0117705", Year: 1996, MatchMethod: matchMethodExact}}
+
+	cfg := defaultConfig()
+	cfg.Format = formatIMDBIDs
+	var buf bytes.Buffer
+	if err := NewScraper("dummy_key", WithConfig(cfg)).writeList(&buf, movies); err != nil {
+		t.Fatalf("Failed to write list: %v", err)
+	}
+	if buf.String() != "tt0117705\n" {
+		t.Errorf("Expected the format to apply, got %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := NewScraper("dummy_key").writeList(&buf, movies); err != nil {
+		t.Fatalf("Failed to write list: %v", err)
+	}
+	if buf.String() != `[{"title":"Space Jam","imdb_id":"tt0117705","year":1996,"hash":"5c35e94a74784bab"}]`+"\n" {
+		t.Errorf("Unexpected native output %q", buf.String())
+	}
+}
+
+func TestEncodeWrapped(t *testing.T) {
+	movies := []Movie{{Title: "Space Jam", IMDBID: "tt0117705", Year: 1996}}
+
+	cfg := defaultConfig()
+	cfg.Format = formatWrapped
+	cfg.ListName = "Scott Hasn't Seen (horror)"
+	scraper := NewScraper("dummy_key", WithConfig(cfg))
+
+	var buf bytes.Buffer
+	if err := scraper.writeList(&buf, movies); err != nil {
+		t.Fatalf("Failed to write list: %v", err)
+	}
+	var wrapped map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &wrapped); err != nil {
+		t.Fatalf("Failed to decode wrapped output: %v", err)
+	}
+	if wrapped["name"] != "Scott Hasn't Seen (horror)" || wrapped["version"] != float64(wrappedListVersion) {
+		t.Errorf("Expected the list name and version at the top level, got %v", wrapped)
+	}
+
+	// Wrapped lists can be read back wherever a previous list is loaded
+	filename := filepath.Join(t.TempDir(), "scott_hasnt_seen.json")
+	if err := scraper.saveToFile(movies, filename); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	loaded, err := loadMovies(filename)
+	if err != nil {
+		t.Fatalf("Failed to load wrapped list: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].IMDBID != "tt0117705" {
+		t.Errorf("Unexpected movies from wrapped list: %+v", loaded)
+	}
+
+	// The name is ignored by the bare-array format, and an empty wrapped list
+	// still has a movies array
+	cfg.Format = formatNative
+	buf.Reset()
+	NewScraper("dummy_key", WithConfig(cfg)).writeList(&buf, movies)
+	if bytes.Contains(buf.Bytes(), []byte("horror")) {
+		t.Errorf("Expected -list-name to be ignored by the native format, got %s", buf.String())
+	}
+	if data, _ := encodeMovies(nil, formatWrapped); string(data) != `{"version":1,"movies":[]}`+"\n" {
+		t.Errorf("Unexpected empty wrapped list %s", data)
+	}
+}
+
+func TestMultipleFormats(t *testing.T) {
+	cfg, err := parseFlags([]string{"-format", "native, radarr"})
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if cfg.Format != formatNative || !reflect.DeepEqual(cfg.ExtraFormats, []string{formatRadarr}) {
+		t.Fatalf("Expected native plus radarr, got %q and %q", cfg.Format, cfg.ExtraFormats)
+	}
+
+	// The first format keeps the plain filename; the others add their name
+	scraper := NewScraper("dummy_key", WithConfig(cfg))
+	if got := scraper.formatSuffix(formatNative); got != ".json" {
+		t.Errorf("Unexpected native suffix %q", got)
+	}
+	if got := scraper.formatSuffix(formatRadarr); got != ".radarr.json" {
+		t.Errorf("Unexpected radarr suffix %q", got)
+	}
+
+	// Each file gets the same movies in its own format
+	movies := []Movie{{Title: "Space Jam", IMDBID: "tt0117705", Year: 1996}}
+	base := filepath.Join(t.TempDir(), "scott_hasnt_seen")
+	for _, format := range []string{cfg.Format, cfg.ExtraFormats[0]} {
+		if err := scraper.saveToFileAs(movies, base+scraper.formatSuffix(format), format); err != nil {
+			t.Fatalf("Failed to save %s: %v", format, err)
+		}
+	}
+	radarr, _ := os.ReadFile(base + ".radarr.json")
+	if string(radarr) != `[{"title":"Space Jam","imdb_id":"tt0117705"}]`+"\n" {
+		t.Errorf("Unexpected radarr file %s", radarr)
+	}
+	native, _ := os.ReadFile(base + ".json")
+	if !bytes.Contains(native, []byte(`"year":1996`)) {
+		t.Errorf("Expected native records in the main file, got %s", native)
+	}
+
+	for _, format := range []string{"native,native", "native,mkv"} {
+		if _, err := parseFlags([]string{"-format", format}); err == nil {
+			t.Errorf("Expected -format %s to be rejected", format)
+		}
+	}
+}
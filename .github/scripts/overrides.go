@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// loadOverrides reads a JSON object mapping wiki titles to IMDB IDs.
+// Keys are matched on their normalized form; entries with an empty ID are skipped
+// so a partially filled-in file can be used as-is.
+func loadOverrides(filename string) (map[string]string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overrides file: %w", err)
+	}
+
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse overrides file: %w", err)
+	}
+
+	overrides := make(map[string]string, len(raw))
+	for title, imdbID := range raw {
+		if imdbID == "" {
+			continue
+		}
+		if !imdbIDPattern.MatchString(imdbID) {
+			return nil, fmt.Errorf("override for '%s' has invalid IMDB ID %q", title, imdbID)
+		}
+		overrides[normalizeTitle(title)] = imdbID
+	}
+
+	return overrides, nil
+}
+
+// WithOverrides sets the title to IMDB ID overrides used before title search
+func WithOverrides(overrides map[string]string) Option {
+	return func(s *Scraper) {
+		s.overrides = overrides
+	}
+}
+
+// overrideFor returns the IMDB ID override for a title, if any
+func (s *Scraper) overrideFor(title string) (string, bool) {
+	imdbID, ok := s.overrides[normalizeTitle(title)]
+	return imdbID, ok
+}
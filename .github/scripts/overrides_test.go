@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadOverrides(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "overrides.json")
+	content := `{"The Addams Family": "tt0101272", "Some Unfilled Title": ""}`
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write overrides file: %v", err)
+	}
+
+	overrides, err := loadOverrides(filename)
+	if err != nil {
+		t.Fatalf("Failed to load overrides: %v", err)
+	}
+
+	if len(overrides) != 1 {
+		t.Errorf("Expected empty IDs to be skipped, got %d overrides", len(overrides))
+	}
+	if overrides[normalizeTitle("the addams family")] != "tt0101272" {
+		t.Errorf("Expected override to be keyed by normalized title, got %v", overrides)
+	}
+
+	if err := os.WriteFile(filename, []byte(`{"Dune": "12345"}`), 0644); err != nil {
+		t.Fatalf("Failed to write overrides file: %v", err)
+	}
+	if _, err := loadOverrides(filename); err == nil {
+		t.Errorf("Expected an invalid IMDB ID to be rejected")
+	}
+}
+
+func TestSearchMovieUsesOverride(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/find/tt0101272" {
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("external_source") != "imdb_id" {
+			t.Errorf("Expected external_source=imdb_id, got %q", r.URL.Query().Get("external_source"))
+		}
+		w.Write([]byte(`{"movie_results":[{"id":2907,"title":"The Addams Family","release_date":"1991-11-22","poster_path":"/poster.jpg"}]}`))
+	}))
+	defer server.Close()
+
+	scraper := NewScraper("dummy_key", WithOverrides(map[string]string{
+		normalizeTitle("Addams Family"): "tt0101272",
+	}))
+	scraper.tmdbBaseURL = server.URL
+
+	movie, err := scraper.searchMovie("Addams Family")
+	if err != nil {
+		t.Fatalf("Expected override to resolve, got %v", err)
+	}
+	if movie.IMDBID != "tt0101272" || movie.Title != "The Addams Family" || movie.Year != 1991 {
+		t.Errorf("Unexpected movie from override: %+v", movie)
+	}
+	if movie.MatchMethod != matchMethodOverride {
+		t.Errorf("Expected match method %s, got %s", matchMethodOverride, movie.MatchMethod)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOverview(t *testing.T) {
+	catalog := append([]mockMovie{}, mockCatalog...)
+	catalog[2].Overview = "A murdered banker stays behind as a ghost to protect his girlfriend." // Ghost
+	mock := newMockTMDB(t, []string{"Ghost"}, catalog)
+
+	cfg := defaultConfig()
+	scraper := mock.newTestScraper(cfg)
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if movies[0].Overview != catalog[2].Overview {
+		t.Errorf("Expected the overview from the search result, got %q", movies[0].Overview)
+	}
+	if prepared := scraper.prepareForOutput(movies); prepared[0].Overview != "" {
+		t.Errorf("Expected the overview to be left out of default output, got %q", prepared[0].Overview)
+	}
+
+	scraper.config.IncludeOverview = true
+	if prepared := scraper.prepareForOutput(movies); prepared[0].Overview != catalog[2].Overview {
+		t.Errorf("Expected the whole overview with -include-overview, got %q", prepared[0].Overview)
+	}
+	scraper.config.MaxOverviewLength = 30
+	if prepared := scraper.prepareForOutput(movies); prepared[0].Overview != "A murdered banker stays…" {
+		t.Errorf("Expected the overview truncated at a word, got %q", prepared[0].Overview)
+	}
+}
+
+func TestTruncateOverview(t *testing.T) {
+	testCases := []struct {
+		overview string
+		max      int
+		expected string
+	}{
+		{"Short.", 0, "Short."},
+		{"Short.", 6, "Short."},
+		{"Two words", 5, "Two…"},
+		{"Unbroken", 5, "Unbr…"},
+		{"Café, crème brûlée", 8, "Café…"},
+	}
+	for _, tc := range testCases {
+		if got := truncateOverview(tc.overview, tc.max); got != tc.expected {
+			t.Errorf("truncateOverview(%q, %d) = %q, expected %q", tc.overview, tc.max, got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxWikiPages bounds how many pages of the wiki list are followed, in case
+// the pagination links ever loop or run away
+const maxWikiPages = 10
+
+// nextPageSelector matches the link to the next page of a paginated list:
+// the standard rel="next" markers and Fandom's category pagination button
+const nextPageSelector = `link[rel="next"], a[rel="next"], a.category-page__pagination-next`
+
+// nextPageURL returns the absolute URL of the page after current, if the
+// document links to one
+func nextPageURL(doc *goquery.Document, current *url.URL) (*url.URL, bool) {
+	href, ok := doc.Find(nextPageSelector).First().Attr("href")
+	if !ok || href == "" {
+		return nil, false
+	}
+	next, err := current.Parse(href)
+	if err != nil {
+		return nil, false
+	}
+	return next, true
+}
+
+// followPagination appends the body of every following page of the wiki list
+// to doc, so titles are extracted from all pages in order. Only pages on the
+// wiki's own scheme and host are followed, so an edited link can't send the
+// scraper, or its -cookie, elsewhere. The wiki state validators only cover
+// the first page.
+func (s *Scraper) followPagination(doc *goquery.Document) error {
+	current, err := url.Parse(s.wikiURL)
+	if err != nil {
+		return fmt.Errorf("invalid wiki URL: %w", err)
+	}
+
+	origin := current.Scheme + "://" + current.Host
+	visited := map[string]bool{current.String(): true}
+	pages := 1
+	next, ok := nextPageURL(doc, current)
+	for ok && !visited[next.String()] {
+		if pages == maxWikiPages {
+			fmt.Fprintf(s.logWriter(), "Stopping after %d wiki pages; more pages are linked\n", maxWikiPages)
+			break
+		}
+		if !strings.EqualFold(next.Scheme+"://"+next.Host, origin) {
+			fmt.Fprintf(s.logWriter(), "Not following a wiki page link to another site: %s\n", next.Redacted())
+			break
+		}
+
+		page, err := s.fetchWikiDocument(next.String())
+		if err != nil {
+			return fmt.Errorf("failed to fetch wiki page %d: %w", pages+1, err)
+		}
+		visited[next.String()] = true
+		current = next
+		pages++
+
+		// Find the following link before the page's content is moved into doc
+		next, ok = nextPageURL(page, current)
+		doc.Find("body").AppendSelection(page.Find("body").Children())
+	}
+
+	if pages > 1 {
+		fmt.Fprintf(s.logWriter(), "Scraped %d wiki pages\n", pages)
+	}
+	return nil
+}
+
+// fetchWikiDocument fetches and parses a follow-up page of the wiki list
+func (s *Scraper) fetchWikiDocument(pageURL string) (*goquery.Document, error) {
+	req, err := http.NewRequest("GET", pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.doRequest(req, endpointWiki, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("wiki page returned status: %d", resp.StatusCode)
+	}
+
+	return goquery.NewDocumentFromReader(resp.Body)
+}
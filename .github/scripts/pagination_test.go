@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newPagedWiki serves a wiki list split across pages; pageHTML returns the
+// body of each 1-based page
+func newPagedWiki(t *testing.T, pageHTML func(page int) string) (*Scraper, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		page := 1
+		if p := r.URL.Query().Get("page"); p != "" {
+			page, _ = strconv.Atoi(p)
+		}
+		fmt.Fprintf(w, "<html><body>%s</body></html>", pageHTML(page))
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := defaultConfig()
+	cfg.StateFile = ""
+	scraper := NewScraper("dummy_key", WithConfig(cfg))
+	scraper.wikiURL = server.URL + "/wiki"
+	return scraper, &requests
+}
+
+func TestScrapeWikiPageFollowsPagination(t *testing.T) {
+	pages := map[int]string{
+		1: `<table><tr><td><i>Space Jam</i></td></tr></table><a rel="next" href="/wiki?page=2">Next</a>`,
+		2: `<table><tr><td><i>Ghost</i></td></tr><tr><td><i>Space Jam</i></td></tr></table>
+			<a class="category-page__pagination-next" href="?page=3">Next page</a>`,
+		// Links back to the first page, which must not be fetched again
+		3: `<table><tr><td><i>The Addams Family</i></td></tr></table><a rel="next" href="/wiki">Start over</a>`,
+	}
+	scraper, requests := newPagedWiki(t, func(page int) string { return pages[page] })
+
+	htmlContent, err := scraper.scrapeWikiPage()
+	if err != nil {
+		t.Fatalf("Failed to scrape: %v", err)
+	}
+	titles, err := scraper.extractMovieTitles(htmlContent)
+	if err != nil {
+		t.Fatalf("Failed to extract titles: %v", err)
+	}
+
+	if !reflect.DeepEqual(titles, []string{"Space Jam", "Ghost", "The Addams Family"}) {
+		t.Errorf("Expected the titles from every page in order, got %v", titles)
+	}
+	if got := atomic.LoadInt32(requests); got != 3 {
+		t.Errorf("Expected 3 page requests, got %d", got)
+	}
+}
+
+func TestScrapeWikiPagePaginationWithHostLimit(t *testing.T) {
+	pages := map[int]string{
+		1: `<table><tr><td><i>Space Jam</i></td></tr></table><a rel="next" href="/wiki?page=2">Next</a>`,
+		2: `<table><tr><td><i>Ghost</i></td></tr></table>`,
+	}
+	scraper, requests := newPagedWiki(t, func(page int) string { return pages[page] })
+
+	// The wiki's default limit is one request at a time, so the second page
+	// only goes out once the first has released its slot
+	wiki, err := url.Parse(scraper.wikiURL)
+	if err != nil {
+		t.Fatalf("Failed to parse wiki URL: %v", err)
+	}
+	scraper.client.Timeout = 2 * time.Second
+	scraper.client.Transport = newHostLimiter(nil, map[string]hostLimit{wiki.Hostname(): {Concurrency: 1}})
+
+	htmlContent, err := scraper.scrapeWikiPage()
+	if err != nil {
+		t.Fatalf("Failed to scrape: %v", err)
+	}
+	titles, err := scraper.extractMovieTitles(htmlContent)
+	if err != nil {
+		t.Fatalf("Failed to extract titles: %v", err)
+	}
+	if !reflect.DeepEqual(titles, []string{"Space Jam", "Ghost"}) || atomic.LoadInt32(requests) != 2 {
+		t.Errorf("Expected both pages, got %v from %d requests", titles, atomic.LoadInt32(requests))
+	}
+}
+
+func TestScrapeWikiPagePaginationIsBounded(t *testing.T) {
+	scraper, requests := newPagedWiki(t, func(page int) string {
+		return fmt.Sprintf(`<i>Movie Number %d</i><a rel="next" href="/wiki?page=%d">Next</a>`, page, page+1)
+	})
+
+	htmlContent, err := scraper.scrapeWikiPage()
+	if err != nil {
+		t.Fatalf("Failed to scrape: %v", err)
+	}
+	titles, err := scraper.extractMovieTitles(htmlContent)
+	if err != nil {
+		t.Fatalf("Failed to extract titles: %v", err)
+	}
+
+	if got := atomic.LoadInt32(requests); got != maxWikiPages {
+		t.Errorf("Expected %d page requests, got %d", maxWikiPages, got)
+	}
+	if len(titles) != maxWikiPages {
+		t.Errorf("Expected one title per page, got %v", titles)
+	}
+}
+
+func TestSearchPageIncludeAdult(t *testing.T) {
+	var includeAdult string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		includeAdult = r.URL.Query().Get("include_adult")
+		w.Write([]byte(`{"page":1,"total_pages":1,"results":[]}`))
+	}))
+	defer server.Close()
+
+	for _, include := range []bool{false, true} {
+		cfg := defaultConfig()
+		cfg.IncludeAdult = include
+		scraper := NewScraper("dummy_key", WithConfig(cfg))
+		scraper.tmdbBaseURL = server.URL
+		if _, err := scraper.searchPage("Ghost", 1); err != nil {
+			t.Fatalf("Failed to search: %v", err)
+		}
+		if expected := strconv.FormatBool(include); includeAdult != expected {
+			t.Errorf("-include-adult=%v: expected include_adult=%s, got %q", include, expected, includeAdult)
+		}
+	}
+}
+
+func TestSearchLanguage(t *testing.T) {
+	var language string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		language = r.URL.Query().Get("language")
+		w.Write([]byte(`{"page":1,"total_pages":1,"results":[]}`))
+	}))
+	defer server.Close()
+
+	scraper := NewScraper("dummy_key")
+	scraper.tmdbBaseURL = server.URL
+	if _, err := scraper.searchPage("Ghost", 1); err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if language != "en-US" {
+		t.Errorf("Expected the default language en-US, got %q", language)
+	}
+
+	cfg, err := parseFlags([]string{"-language", "de-DE"})
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	scraper = NewScraper("dummy_key", WithConfig(cfg))
+	scraper.tmdbBaseURL = server.URL
+	if _, err := scraper.searchPage("Ghost", 1); err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if language != "de-DE" {
+		t.Errorf("Expected -language de-DE to be sent, got %q", language)
+	}
+
+	for _, invalid := range []string{"", "german", "de_DE", "DE"} {
+		if _, err := parseFlags([]string{"-language", invalid}); err == nil {
+			t.Errorf("Expected -language %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestScrapeWikiPagePaginationStaysOnHost(t *testing.T) {
+	var offsite int32
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&offsite, 1)
+		fmt.Fprint(w, "<html><body><i>Ghost</i></body></html>")
+	}))
+	t.Cleanup(other.Close)
+
+	scraper, requests := newPagedWiki(t, func(page int) string {
+		return fmt.Sprintf(`<i>Space Jam</i><a rel="next" href="%s/wiki?page=2">Next</a>`, other.URL)
+	})
+	scraper.wikiCookie = "session=secret"
+
+	htmlContent, err := scraper.scrapeWikiPage()
+	if err != nil {
+		t.Fatalf("Failed to scrape: %v", err)
+	}
+	titles, err := scraper.extractMovieTitles(htmlContent)
+	if err != nil {
+		t.Fatalf("Failed to extract titles: %v", err)
+	}
+
+	if !reflect.DeepEqual(titles, []string{"Space Jam"}) {
+		t.Errorf("Expected only the first page's titles, got %v", titles)
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("Expected 1 page request, got %d", got)
+	}
+	if got := atomic.LoadInt32(&offsite); got != 0 {
+		t.Errorf("Expected no requests to the other host, got %d", got)
+	}
+}
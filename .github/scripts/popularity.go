@@ -0,0 +1,30 @@
+package main
+
+import "cmp"
+
+// withMinPopularity drops movies whose TMDB popularity is below min,
+// returning the kept movies and the number dropped. Placeholders are kept, as
+// are movies carried over from the previous list without a saved popularity.
+func withMinPopularity(movies []Movie, min float64) ([]Movie, int) {
+	kept := make([]Movie, 0, len(movies))
+	for _, movie := range movies {
+		if movie.Popularity >= min || movie.IsPlaceholder() || (movie.carried && movie.Popularity == 0) {
+			kept = append(kept, movie)
+		}
+	}
+	return kept, len(movies) - len(kept)
+}
+
+// sortByPopularity orders movies most popular first, breaking ties in the
+// usual title order
+func sortByPopularity(movies []Movie) {
+	sortWith(movies, compareByPopularity)
+}
+
+// compareByPopularity is the -sort popularity order
+func compareByPopularity(a, b Movie) int {
+	if c := cmp.Compare(b.Popularity, a.Popularity); c != 0 {
+		return c
+	}
+	return compareMovies(a, b)
+}
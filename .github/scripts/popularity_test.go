@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPopularity(t *testing.T) {
+	catalog := append([]mockMovie{}, mockCatalog...)
+	catalog[0].Popularity = 42.5 // Space Jam
+	catalog[1].Popularity = 3    // The Addams Family
+	catalog[2].Popularity = 18   // Ghost
+	mock := newMockTMDB(t, []string{"Space Jam", "The Addams Family", "Ghost"}, catalog)
+
+	cfg := defaultConfig()
+	scraper := mock.newTestScraper(cfg)
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	for _, movie := range scraper.prepareForOutput(movies) {
+		if movie.Popularity != 0 {
+			t.Errorf("Expected popularity to be left out of default output, got %+v", movie)
+		}
+	}
+
+	cfg.IncludePopularity = true
+	cfg.MinPopularity = 10
+	cfg.SortByPopularity = true
+	scraper = mock.newTestScraper(cfg)
+	movies, err = scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	movies = scraper.prepareForOutput(movies)
+	if len(movies) != 2 || movies[0].Title != "Space Jam" || movies[1].Title != "Ghost" {
+		t.Fatalf("Expected Space Jam then Ghost, got %+v", movies)
+	}
+	if movies[0].Popularity != 42.5 {
+		t.Errorf("Expected Space Jam's popularity in the output, got %v", movies[0].Popularity)
+	}
+}
+
+func TestParseFlagsPopularity(t *testing.T) {
+	if _, err := parseFlags([]string{"-min-popularity", "-1"}); err == nil {
+		t.Error("Expected a negative -min-popularity to be rejected")
+	}
+	if _, err := parseFlags([]string{"-sort-by-popularity", "-no-sort"}); err == nil {
+		t.Error("Expected -sort-by-popularity and -no-sort to conflict")
+	}
+}
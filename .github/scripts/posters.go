@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// brokenPoster records a poster URL that did not resolve
+type brokenPoster struct {
+	Index      int // position of the movie in the list
+	Title      string
+	URL        string
+	StatusCode int   // zero if the request itself failed
+	Err        error // set if the request itself failed
+}
+
+func (b brokenPoster) String() string {
+	if b.Err != nil {
+		return fmt.Sprintf("%s: %s (%v)", b.Title, b.URL, b.Err)
+	}
+	return fmt.Sprintf("%s: %s (status %d)", b.Title, b.URL, b.StatusCode)
+}
+
+// checkPoster issues a HEAD request for a poster URL and returns its status
+func (s *Scraper) checkPoster(posterURL string) (int, error) {
+	req, err := http.NewRequest(http.MethodHead, posterURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+// verifyPosters checks every poster URL in the list and returns the broken
+// ones in list order. Poster requests share the HTTP client with the API
+// calls but have their own -poster-concurrency limit.
+func (s *Scraper) verifyPosters(movies []Movie) []brokenPoster {
+	results := make([]*brokenPoster, len(movies))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, s.config.PosterConcurrency)
+
+	for i, movie := range movies {
+		if movie.PosterURL == "" {
+			continue
+		}
+
+		wg.Add(1)
+		go func(index int, movie Movie) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			statusCode, err := s.checkPoster(movie.PosterURL)
+			if err != nil || statusCode != http.StatusOK {
+				results[index] = &brokenPoster{Index: index, Title: movie.Title, URL: movie.PosterURL, StatusCode: statusCode, Err: err}
+			}
+
+			// Rate limiting
+			time.Sleep(s.rateLimitPause())
+		}(i, movie)
+	}
+	wg.Wait()
+
+	var broken []brokenPoster
+	for _, result := range results {
+		if result != nil {
+			broken = append(broken, *result)
+		}
+	}
+	return broken
+}
+
+// verifyPosterFile checks the poster URLs in an existing output file and
+// reports the broken ones. With -clear-broken-posters, posters that returned
+// 404 are removed and the file is rewritten.
+func (s *Scraper) verifyPosterFile(filename string) error {
+	movies, err := loadMovies(filename)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(s.logWriter(), "Verifying poster URLs in %s...\n", filename)
+	broken := s.verifyPosters(movies)
+
+	cleared := 0
+	for _, poster := range broken {
+		fmt.Fprintf(s.logWriter(), "  %s Broken poster: %s\n", s.failMark(), poster)
+		if s.config.ClearBrokenPosters && poster.StatusCode == http.StatusNotFound {
+			movies[poster.Index].PosterURL = ""
+			cleared++
+		}
+	}
+	fmt.Fprintf(s.logWriter(), "Found %d broken posters\n", len(broken))
+
+	if cleared == 0 {
+		return nil
+	}
+	if err := s.saveToFile(movies, filename); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.logWriter(), "Cleared %d missing posters from %s\n", cleared, filename)
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestVerifyPosterFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("Expected a HEAD request, got %s", r.Method)
+		}
+		switch r.URL.Path {
+		case "/ok.jpg":
+			w.WriteHeader(http.StatusOK)
+		case "/error.jpg":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	movies := []Movie{
+		{Title: "Space Jam", IMDBID: "tt0117705", PosterURL: server.URL + "/ok.jpg"},
+		{Title: "Ghost", IMDBID: "tt0099653", PosterURL: server.URL + "/gone.jpg"},
+		{Title: "Dune", IMDBID: "tt0087182", PosterURL: server.URL + "/error.jpg"},
+		{Title: "Sister Act", IMDBID: "tt0105417"},
+	}
+
+	scraper := NewScraper("dummy_key")
+	broken := scraper.verifyPosters(movies)
+	if len(broken) != 2 || broken[0].Title != "Ghost" || broken[1].Title != "Dune" {
+		t.Fatalf("Expected Ghost and Dune to be broken, got %+v", broken)
+	}
+	if broken[0].StatusCode != http.StatusNotFound || broken[1].StatusCode != http.StatusInternalServerError {
+		t.Errorf("Unexpected status codes: %+v", broken)
+	}
+
+	filename := filepath.Join(t.TempDir(), "scott_hasnt_seen.json")
+	if err := scraper.saveToFile(movies, filename); err != nil {
+		t.Fatalf("Failed to save list: %v", err)
+	}
+
+	cfg := defaultConfig()
+	cfg.ClearBrokenPosters = true
+	if err := NewScraper("dummy_key", WithConfig(cfg)).verifyPosterFile(filename); err != nil {
+		t.Fatalf("Failed to verify posters: %v", err)
+	}
+
+	rewritten, err := loadMovies(filename)
+	if err != nil {
+		t.Fatalf("Failed to load rewritten list: %v", err)
+	}
+	if rewritten[1].PosterURL != "" {
+		t.Errorf("Expected the 404 poster to be cleared, got %s", rewritten[1].PosterURL)
+	}
+	if rewritten[0].PosterURL == "" || rewritten[2].PosterURL == "" {
+		t.Errorf("Expected other posters to be kept, got %+v", rewritten)
+	}
+}
+
+func TestVerifyPostersConcurrency(t *testing.T) {
+	var inFlight, peak int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt64(&inFlight, 1)
+		defer atomic.AddInt64(&inFlight, -1)
+		for {
+			previous := atomic.LoadInt64(&peak)
+			if current <= previous || atomic.CompareAndSwapInt64(&peak, previous, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	var movies []Movie
+	for i := 0; i < 8; i++ {
+		movies = append(movies, Movie{Title: fmt.Sprintf("Movie %d", i), PosterURL: fmt.Sprintf("%s/%d.jpg", server.URL, i)})
+	}
+
+	cfg := defaultConfig()
+	cfg.PosterConcurrency = 2
+	if broken := NewScraper("dummy_key", WithConfig(cfg)).verifyPosters(movies); len(broken) != 0 {
+		t.Errorf("Expected no broken posters, got %+v", broken)
+	}
+	if peak > 2 {
+		t.Errorf("Expected at most 2 poster requests in flight, got %d", peak)
+	}
+
+	if _, err := parseFlags([]string{"-poster-concurrency", "0"}); err == nil {
+		t.Error("Expected -poster-concurrency 0 to be rejected")
+	}
+}
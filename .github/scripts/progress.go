@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// progressBarWidth is the number of cells in the -progress bar
+const progressBarWidth = 30
+
+// progress reports how many titles have finished resolving. Completions are
+// counted atomically and printed in order, so the count never goes backwards
+// even though titles finish concurrently. The total may grow while titles are
+// still being streamed in from the wiki page.
+type progress struct {
+	out      io.Writer
+	bar      bool
+	interval time.Duration
+
+	total     int64
+	completed int64
+
+	now     func() time.Time
+	started time.Time
+
+	mu          sync.Mutex
+	printed     int64
+	lastPrinted time.Time
+}
+
+// newProgress creates a reporter expecting total titles. With bar set it
+// redraws a single line with the elapsed time and an ETA; otherwise it prints
+// "Completed n/total" lines, at most once per interval when interval is
+// positive.
+func newProgress(out io.Writer, total int, bar bool, interval time.Duration) *progress {
+	return &progress{out: out, total: int64(total), bar: bar, interval: interval, now: time.Now, started: time.Now()}
+}
+
+// add expects one more title
+func (p *progress) add() {
+	atomic.AddInt64(&p.total, 1)
+}
+
+// done records one finished title and prints the progress if it is due
+func (p *progress) done() {
+	atomic.AddInt64(&p.completed, 1)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	completed := atomic.LoadInt64(&p.completed)
+	total := atomic.LoadInt64(&p.total)
+	if completed <= p.printed {
+		return
+	}
+	if completed != total && p.interval > 0 && time.Since(p.lastPrinted) < p.interval {
+		return
+	}
+	p.print(completed, total)
+}
+
+// finish prints the final count if it hasn't been printed yet and ends the
+// progress bar's line
+func (p *progress) finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	completed := atomic.LoadInt64(&p.completed)
+	if completed > p.printed {
+		p.print(completed, atomic.LoadInt64(&p.total))
+	}
+	if p.bar && p.printed > 0 {
+		fmt.Fprintln(p.out)
+	}
+}
+
+// print writes the progress; p.mu must be held
+func (p *progress) print(completed, total int64) {
+	p.printed = completed
+	p.lastPrinted = time.Now()
+
+	if p.bar {
+		// Clear to the end of the line, since the ETA may have got shorter
+		fmt.Fprintf(p.out, "\r%s %s\033[K", renderProgressBar(completed, total), renderETA(p.now(), p.started, completed, total))
+		return
+	}
+	fmt.Fprintf(p.out, "Completed %d/%d\n", completed, total)
+}
+
+// renderProgressBar draws e.g. "[#########.....................] 37/412 (9%)"
+func renderProgressBar(completed, total int64) string {
+	filled := progressBarWidth
+	percent := int64(100)
+	if total > 0 {
+		filled = int(completed * progressBarWidth / total)
+		percent = completed * 100 / total
+	}
+	return fmt.Sprintf("[%s%s] %d/%d (%d%%)", strings.Repeat("#", filled), strings.Repeat(".", progressBarWidth-filled), completed, total, percent)
+}
+
+// renderETA describes the time taken so far and, from the average time per
+// title, the time left and when the run should finish, e.g. "elapsed 1m40s,
+// ETA 3m20s (~14:05:10)". Once every title is done it gives the total time.
+func renderETA(now, started time.Time, completed, total int64) string {
+	elapsed := now.Sub(started)
+	if completed >= total {
+		return fmt.Sprintf("done in %s", elapsed.Round(time.Second))
+	}
+	if completed == 0 {
+		return fmt.Sprintf("elapsed %s", elapsed.Round(time.Second))
+	}
+	remaining := elapsed / time.Duration(completed) * time.Duration(total-completed)
+	return fmt.Sprintf("elapsed %s, ETA %s (~%s)", elapsed.Round(time.Second), remaining.Round(time.Second), now.Add(remaining).Format("15:04:05"))
+}
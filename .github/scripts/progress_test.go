@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProgressIsMonotonic(t *testing.T) {
+	var out bytes.Buffer
+	p := newProgress(&out, 50, false, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.done()
+		}()
+	}
+	wg.Wait()
+	p.finish()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	previous := 0
+	for _, line := range lines {
+		var completed, total int
+		if _, err := fmt.Sscanf(line, "Completed %d/%d", &completed, &total); err != nil {
+			t.Fatalf("Unexpected progress line %q: %v", line, err)
+		}
+		if completed <= previous {
+			t.Errorf("Progress went from %d to %d", previous, completed)
+		}
+		previous = completed
+	}
+	if previous != 50 {
+		t.Errorf("Expected the final line to report 50/50, got %d", previous)
+	}
+}
+
+func TestProgressInterval(t *testing.T) {
+	var out bytes.Buffer
+	p := newProgress(&out, 10, false, time.Hour)
+
+	for i := 0; i < 10; i++ {
+		p.done()
+	}
+	p.finish()
+
+	// Only the first completion and the final one fall outside the interval
+	if got := out.String(); got != "Completed 1/10\nCompleted 10/10\n" {
+		t.Errorf("Unexpected throttled output %q", got)
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	var out bytes.Buffer
+	p := newProgress(&out, 4, true, 0)
+
+	for i := 0; i < 4; i++ {
+		p.done()
+	}
+	p.finish()
+
+	if !strings.HasPrefix(out.String(), "\r[") || !strings.HasSuffix(out.String(), "] 4/4 (100%) done in 0s\033[K\n") {
+		t.Errorf("Unexpected progress bar output %q", out.String())
+	}
+	if strings.Count(out.String(), "\n") != 1 {
+		t.Errorf("Expected the bar to stay on one line until finished, got %q", out.String())
+	}
+
+	if bar := renderProgressBar(15, 30); bar != "[###############...............] 15/30 (50%)" {
+		t.Errorf("Unexpected half-full bar %q", bar)
+	}
+}
+
+func TestProgressGrowingTotal(t *testing.T) {
+	var out bytes.Buffer
+	p := newProgress(&out, 0, false, 0)
+
+	p.add()
+	p.done()
+	p.add()
+	p.add()
+	p.done()
+	p.done()
+	p.finish()
+
+	if got := out.String(); got != "Completed 1/1\nCompleted 2/3\nCompleted 3/3\n" {
+		t.Errorf("Unexpected streamed progress %q", got)
+	}
+}
+
+func TestProgressETA(t *testing.T) {
+	started := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	now := started.Add(100 * time.Second)
+
+	if eta := renderETA(now, started, 25, 100); eta != "elapsed 1m40s, ETA 5m0s (~14:06:40)" {
+		t.Errorf("Unexpected ETA %q", eta)
+	}
+	if eta := renderETA(now, started, 0, 100); eta != "elapsed 1m40s" {
+		t.Errorf("Expected no ETA before the first title finishes, got %q", eta)
+	}
+	if eta := renderETA(now, started, 100, 100); eta != "done in 1m40s" {
+		t.Errorf("Expected the total time once finished, got %q", eta)
+	}
+
+	// The ETA is only drawn with the bar
+	var out bytes.Buffer
+	p := newProgress(&out, 4, false, 0)
+	p.now = fixedClock(now)
+	p.started = started
+	p.done()
+	if got := out.String(); got != "Completed 1/4\n" {
+		t.Errorf("Expected plain progress lines without -progress, got %q", got)
+	}
+	out.Reset()
+	p.bar = true
+	p.done()
+	if got := out.String(); !strings.Contains(got, "] 2/4 (50%) elapsed 1m40s, ETA 1m40s (~14:03:20)") {
+		t.Errorf("Expected the ETA next to the bar, got %q", got)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import "fmt"
+
+// WithPreviousList sets the list written by the previous run. Movies in it
+// that the fresh run didn't produce are carried over, or removed and
+// reported with -prune.
+func WithPreviousList(movies []Movie) Option {
+	return func(s *Scraper) {
+		s.previous = movies
+	}
+}
+
+// carriesOver reports whether the run compares against the previous list.
+// Only a full run over the wiki sees every title: -since, -titles-file and
+// the resolve command see part of the list, and -stats-only must see the
+// movies that went missing.
+func (c Config) carriesOver() bool {
+	return c.Command == commandRun && c.Since.IsZero() && c.TitleListFile == "" && !c.StatsOnly
+}
+
+// carryOver adds the previous list's movies that are missing from the fresh
+// list, so the list only grows. With -prune they are left out instead and
+// recorded for the summary. Carried-over movies sort after the wiki's titles
+// with -no-sort. Placeholders are never carried over, and manual entries are
+// left to -merge when it is set. Partial runs carry nothing over.
+func (s *Scraper) carryOver(fresh []Movie, lastPosition int) []Movie {
+	if !s.config.carriesOver() {
+		return fresh
+	}
+
+	current := make(map[string]bool, len(fresh))
+	for _, movie := range matchedOnly(fresh) {
+		current[movieKey(movie)] = true
+	}
+
+	for _, movie := range matchedOnly(s.previous) {
+		key := movieKey(movie)
+		if current[key] || (movie.Manual && s.config.Merge) {
+			continue
+		}
+		current[key] = true
+
+		if s.config.Prune {
+			s.pruned = append(s.pruned, movie)
+			continue
+		}
+		lastPosition++
+		movie.position = lastPosition
+		movie.carried = true
+		s.carried++
+		fresh = append(fresh, movie)
+	}
+	return fresh
+}
+
+// printPruned lists the movies removed by -prune, since removal is
+// destructive and easy to miss in a diff
+func (s *Scraper) printPruned() {
+	if !s.config.Prune || len(s.pruned) == 0 {
+		return
+	}
+
+	sortMovies(s.pruned)
+	fmt.Fprintf(s.logWriter(), "\nPruned %d movies not in this run's list:\n", len(s.pruned))
+	for _, movie := range s.pruned {
+		fmt.Fprintf(s.logWriter(), "  - %s\n", describeMovie(movie))
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCarryOverAndPrune(t *testing.T) {
+	mock := newMockTMDB(t, []string{"Space Jam", "Ghost"}, mockCatalog)
+	previous := []Movie{
+		{Title: "Space Jam", IMDBID: "tt0117705", Year: 1996},
+		{Title: "Dune", IMDBID: "tt0087182", Year: 1984},
+		newPlaceholder("Unknown Movie"),
+	}
+
+	cfg := defaultConfig()
+	scraper := mock.newTestScraper(cfg)
+	WithPreviousList(previous)(scraper)
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if len(movies) != 3 || movies[0].Title != "Dune" || scraper.carried != 1 {
+		t.Errorf("Expected Dune to be kept from the previous list, got %+v", movies)
+	}
+
+	cfg.Prune = true
+	scraper = mock.newTestScraper(cfg)
+	WithPreviousList(previous)(scraper)
+	movies, err = scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if len(movies) != 2 {
+		t.Errorf("Expected only the wiki's movies with -prune, got %+v", movies)
+	}
+	if len(scraper.pruned) != 1 || scraper.pruned[0].Title != "Dune" {
+		t.Errorf("Expected Dune to be reported as pruned, got %+v", scraper.pruned)
+	}
+}
+
+func TestCarryOverLeavesManualEntriesToMerge(t *testing.T) {
+	scraper := NewScraper("dummy_key")
+	scraper.config.Merge = true
+	scraper.config.Prune = true
+	WithPreviousList([]Movie{{Title: "Home Movie", IMDBID: "tt1234567", Manual: true}})(scraper)
+
+	if movies := scraper.carryOver(nil, 0); len(movies) != 0 || len(scraper.pruned) != 0 {
+		t.Errorf("Expected the manual entry to be left to -merge, got %+v (pruned %+v)", movies, scraper.pruned)
+	}
+}
+
+func TestCarryOverOnlyOnFullRuns(t *testing.T) {
+	mock := newMockTMDB(t, []string{"Space Jam"}, mockCatalog)
+	previous := []Movie{{Title: "Dune", IMDBID: "tt0087182", Year: 1984}}
+
+	// The saved entry has no popularity or keywords, so the filters keep it
+	cfg := defaultConfig()
+	cfg.MinPopularity = 1000
+	cfg.ExcludeKeywords = []string{"basketball"}
+	scraper := mock.newTestScraper(cfg)
+	WithPreviousList(previous)(scraper)
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if len(movies) != 1 || movies[0].Title != "Dune" {
+		t.Errorf("Expected the carried movie to pass the metadata filters, got %+v", movies)
+	}
+
+	partial := []func(*Config){
+		func(c *Config) { c.Since = time.Now() },
+		func(c *Config) { c.TitleListFile = "titles.txt" },
+		func(c *Config) { c.StatsOnly = true },
+		func(c *Config) { c.Command = commandResolve },
+	}
+	for i, set := range partial {
+		cfg := defaultConfig()
+		set(&cfg)
+		scraper := NewScraper("dummy_key")
+		scraper.config = cfg
+		WithPreviousList(previous)(scraper)
+		if movies := scraper.carryOver(nil, 0); len(movies) != 0 || scraper.carried != 0 {
+			t.Errorf("Case %d: expected a partial run to carry nothing over, got %+v", i, movies)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// radarrExistsMessage is part of Radarr's validation error for a movie that is
+// already in the library
+const radarrExistsMessage = "already been added"
+
+// radarrAddRequest is the body of Radarr's add movie API call
+type radarrAddRequest struct {
+	Title               string           `json:"title"`
+	TMDBID              int              `json:"tmdbId"`
+	Year                int              `json:"year,omitempty"`
+	QualityProfileID    int              `json:"qualityProfileId"`
+	RootFolderPath      string           `json:"rootFolderPath"`
+	Monitored           bool             `json:"monitored"`
+	MinimumAvailability string           `json:"minimumAvailability"`
+	AddOptions          radarrAddOptions `json:"addOptions"`
+}
+
+type radarrAddOptions struct {
+	SearchForMovie bool `json:"searchForMovie"`
+}
+
+// radarrSink adds the final list to a Radarr instance through its API.
+// Movies Radarr already has are skipped, so it is safe to run every time.
+type radarrSink struct {
+	client         *http.Client
+	baseURL        string
+	apiKey         string
+	rootFolder     string
+	qualityProfile int
+	out            io.Writer // progress messages
+}
+
+// newRadarrSink creates a sink for the Radarr instance at baseURL
+func newRadarrSink(baseURL, apiKey, rootFolder string, qualityProfile int, out io.Writer) *radarrSink {
+	return &radarrSink{
+		client:         &http.Client{Timeout: 30 * time.Second},
+		baseURL:        strings.TrimRight(baseURL, "/"),
+		apiKey:         apiKey,
+		rootFolder:     rootFolder,
+		qualityProfile: qualityProfile,
+		out:            out,
+	}
+}
+
+// Add does nothing; movies are only sent once the list is final so that
+// filtered and duplicate movies never reach Radarr
+func (r *radarrSink) Add(movie Movie) {}
+
+// Finish adds each matched movie with a TMDB ID to Radarr
+func (r *radarrSink) Finish(movies []Movie) error {
+	added, existing, failed := 0, 0, 0
+	for _, movie := range matchedOnly(movies) {
+		if movie.TMDBID == 0 {
+			continue
+		}
+
+		exists, err := r.addMovie(movie)
+		switch {
+		case err != nil:
+			failed++
+			fmt.Fprintf(r.out, "  Failed to add %s to Radarr: %v\n", movie.Title, err)
+		case exists:
+			existing++
+		default:
+			added++
+		}
+	}
+
+	fmt.Fprintf(r.out, "Radarr: added %d, already present %d, failed %d\n", added, existing, failed)
+	if failed > 0 {
+		return fmt.Errorf("failed to add %d movies to Radarr", failed)
+	}
+	return nil
+}
+
+// addMovie adds one movie to Radarr, reporting whether it was already there
+func (r *radarrSink) addMovie(movie Movie) (bool, error) {
+	body, err := json.Marshal(radarrAddRequest{
+		Title:               movie.Title,
+		TMDBID:              movie.TMDBID,
+		Year:                movie.Year,
+		QualityProfileID:    r.qualityProfile,
+		RootFolderPath:      r.rootFolder,
+		Monitored:           true,
+		MinimumAvailability: "released",
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", r.baseURL+"/api/v3/movie", bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", r.apiKey)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusCreated || resp.StatusCode == http.StatusOK:
+		return false, nil
+	case resp.StatusCode == http.StatusBadRequest:
+		message, _ := io.ReadAll(resp.Body)
+		if strings.Contains(string(message), radarrExistsMessage) {
+			return true, nil
+		}
+		return false, fmt.Errorf("Radarr rejected the movie: %s", strings.TrimSpace(string(message)))
+	default:
+		return false, fmt.Errorf("Radarr API returned status %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRadarrSinkFinish(t *testing.T) {
+	var requests []radarrAddRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v3/movie" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("X-Api-Key") != "radarr_key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req radarrAddRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		requests = append(requests, req)
+
+		switch req.TMDBID {
+		case 2300:
+			w.WriteHeader(http.StatusCreated)
+		case 251:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`[{"propertyName":"TmdbId","errorMessage":"This movie has already been added"}]`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	movies := []Movie{
+		{Title: "Ghost", IMDBID: "tt0099653", TMDBID: 251, Year: 1990},
+		{Title: "Space Jam", IMDBID: "tt0117705", TMDBID: 2300, Year: 1996},
+		{Title: "No TMDB ID", IMDBID: "tt1234567"},
+		newPlaceholder("Some Obscure Film"),
+	}
+
+	sink := newRadarrSink(server.URL+"/", "radarr_key", "/movies", 4, io.Discard)
+	if err := sink.Finish(movies); err != nil {
+		t.Fatalf("Expected existing movies to be skipped without an error, got %v", err)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("Expected only movies with a TMDB ID to be sent, got %+v", requests)
+	}
+	want := radarrAddRequest{Title: "Space Jam", TMDBID: 2300, Year: 1996, QualityProfileID: 4, RootFolderPath: "/movies", Monitored: true, MinimumAvailability: "released"}
+	if requests[1] != want {
+		t.Errorf("Expected %+v, got %+v", want, requests[1])
+	}
+
+	movies = append(movies, Movie{Title: "Dune", IMDBID: "tt0087182", TMDBID: 841})
+	if err := sink.Finish(movies); err == nil || !strings.Contains(err.Error(), "1 movies") {
+		t.Errorf("Expected an error for the failed movie, got %v", err)
+	}
+}
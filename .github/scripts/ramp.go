@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// rampUp holds all but one of the semaphore's slots and frees them one at a
+// time over window, so concurrency grows from 1 to the semaphore's capacity
+// instead of starting at full against a cold connection pool. The slots are
+// reserved before it returns; the returned stop function frees any still
+// held and waits for the ramp to finish. Cancelling ctx frees them too.
+func rampUp(ctx context.Context, semaphore chan struct{}, window time.Duration) (stop func()) {
+	held := cap(semaphore) - 1
+	if window <= 0 || held <= 0 {
+		return func() {}
+	}
+	for i := 0; i < held; i++ {
+		semaphore <- struct{}{}
+	}
+
+	quit := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(window / time.Duration(held))
+		defer ticker.Stop()
+		for released := 0; released < held; released++ {
+			select {
+			case <-ticker.C:
+			case <-quit:
+			case <-ctx.Done():
+			}
+			<-semaphore
+		}
+	}()
+
+	return func() {
+		close(quit)
+		<-exited
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// freeSlots takes every slot it can without blocking, then gives them back
+func freeSlots(semaphore chan struct{}) int {
+	taken := 0
+	for {
+		select {
+		case semaphore <- struct{}{}:
+			taken++
+			continue
+		default:
+		}
+		break
+	}
+	for i := 0; i < taken; i++ {
+		<-semaphore
+	}
+	return taken
+}
+
+func TestRampUp(t *testing.T) {
+	semaphore := make(chan struct{}, 3)
+	stop := rampUp(context.Background(), semaphore, 100*time.Millisecond)
+	if free := freeSlots(semaphore); free != 1 {
+		t.Errorf("Expected 1 free slot at the start, got %d", free)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if free := freeSlots(semaphore); free != 3 {
+		t.Errorf("Expected all 3 slots free after the window, got %d", free)
+	}
+	stop()
+
+	// Stopping early frees the slots still held
+	stop = rampUp(context.Background(), semaphore, time.Hour)
+	stop()
+	if free := freeSlots(semaphore); free != 3 {
+		t.Errorf("Expected all 3 slots free after stopping, got %d", free)
+	}
+
+	// Without a window nothing is held
+	rampUp(context.Background(), semaphore, 0)()
+	if free := freeSlots(semaphore); free != 3 {
+		t.Errorf("Expected all 3 slots free without a ramp, got %d", free)
+	}
+}
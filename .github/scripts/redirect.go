@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// stopWikiRedirects is the client's CheckRedirect with -no-follow-redirects:
+// requests that started on the wiki's host stop at the redirect, so a moved
+// page is reported instead of silently scraped. Other hosts still follow.
+func (s *Scraper) stopWikiRedirects(req *http.Request, via []*http.Request) error {
+	if wiki, err := url.Parse(s.wikiURL); err == nil && via[0].URL.Host == wiki.Host {
+		return http.ErrUseLastResponse
+	}
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	return nil
+}
+
+// isRedirect reports whether a response redirects elsewhere. A 304 Not
+// Modified is a 3xx status but not a redirect.
+func isRedirect(resp *http.Response) bool {
+	return resp.StatusCode >= 300 && resp.StatusCode < 400 &&
+		resp.StatusCode != http.StatusNotModified && resp.Header.Get("Location") != ""
+}
+
+// checkWikiRedirect logs a wiki request that ended up somewhere other than
+// where it was sent, since a moved page may not be the list any more. A
+// redirect that wasn't followed, whether because of -no-follow-redirects or
+// because the client doesn't follow its status, is returned as an error.
+func (s *Scraper) checkWikiRedirect(req *http.Request, resp *http.Response) (*http.Response, error) {
+	if isRedirect(resp) {
+		location, err := resp.Location()
+		target := resp.Header.Get("Location")
+		if err == nil {
+			target = location.String()
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if s.config.NoFollowRedirects {
+			return nil, fmt.Errorf("wiki page %s redirected to %s (status %d) and -no-follow-redirects is set", req.URL, target, resp.StatusCode)
+		}
+		// The client only follows 301, 302, 303, 307 and 308
+		return nil, fmt.Errorf("wiki page %s returned a redirect to %s that can't be followed (status %d)", req.URL, target, resp.StatusCode)
+	}
+
+	if resp.Request != nil && resp.Request.URL.String() != req.URL.String() {
+		fmt.Fprintf(s.logWriter(), "Wiki page %s redirected to %s\n", req.URL, resp.Request.URL)
+	}
+	return resp, nil
+}
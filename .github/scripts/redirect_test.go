@@ -0,0 +1,79 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newRedirectingWiki serves a wiki page at /moved and redirects /wiki to it
+func newRedirectingWiki(t *testing.T, noFollow bool) *Scraper {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.Handle("/wiki", http.RedirectHandler("/moved", http.StatusMovedPermanently))
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><p>moved</p></body></html>`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	cfg := defaultConfig()
+	cfg.StateFile = ""
+	cfg.NoFollowRedirects = noFollow
+	scraper := NewScraper("dummy_key", WithConfig(cfg))
+	scraper.wikiURL = server.URL + "/wiki"
+	return scraper
+}
+
+func TestWikiRedirectIsLogged(t *testing.T) {
+	scraper := newRedirectingWiki(t, false)
+
+	var html string
+	var err error
+	output := captureStdout(t, func() {
+		html, err = scraper.scrapeWikiPage()
+	})
+	if err != nil {
+		t.Fatalf("Expected the redirect to be followed: %v", err)
+	}
+	if !strings.Contains(html, "moved") {
+		t.Errorf("Expected the redirected page, got %s", html)
+	}
+	if want := "Wiki page " + scraper.wikiURL + " redirected to " + strings.TrimSuffix(scraper.wikiURL, "/wiki") + "/moved"; !strings.Contains(output, want) {
+		t.Errorf("Expected %q to be logged, got %q", want, output)
+	}
+}
+
+func TestNoFollowRedirects(t *testing.T) {
+	scraper := newRedirectingWiki(t, true)
+
+	_, err := scraper.scrapeWikiPage()
+	if err == nil {
+		t.Fatal("Expected the redirect to fail the scrape")
+	}
+	if !strings.Contains(err.Error(), "redirected to") || !strings.Contains(err.Error(), "/moved") {
+		t.Errorf("Expected the error to name the destination, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "-no-follow-redirects is set") {
+		t.Errorf("Expected the error to name -no-follow-redirects, got %v", err)
+	}
+
+	// A redirect the client doesn't follow fails without blaming the flag
+	scraper = newRedirectingWiki(t, false)
+	multiple := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/moved")
+		w.WriteHeader(http.StatusMultipleChoices)
+	}))
+	defer multiple.Close()
+	scraper.wikiURL = multiple.URL + "/wiki"
+	_, err = scraper.scrapeWikiPage()
+	if err == nil || strings.Contains(err.Error(), "-no-follow-redirects") || !strings.Contains(err.Error(), "can't be followed") {
+		t.Errorf("Expected an unfollowable redirect error, got %v", err)
+	}
+
+	// A 304 is not a redirect
+	if isRedirect(&http.Response{StatusCode: http.StatusNotModified, Header: http.Header{"Location": {"/moved"}}}) {
+		t.Error("Expected 304 Not Modified not to count as a redirect")
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// FailedRequest is one line of the -failed-requests-log file
+type FailedRequest struct {
+	Time     time.Time `json:"time"`
+	Endpoint string    `json:"endpoint"`
+	Method   string    `json:"method"`
+	URL      string    `json:"url"`
+	Status   int       `json:"status,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// requestLog appends failed requests to a writer as JSON lines. It is safe
+// for concurrent use.
+type requestLog struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// WithFailedRequestLog records every failed request to w as a JSON line, with
+// secrets redacted from the URL and error
+func WithFailedRequestLog(w io.Writer) Option {
+	return func(s *Scraper) {
+		s.requestLog = &requestLog{enc: json.NewEncoder(w)}
+	}
+}
+
+// secretParamPattern matches query parameters that carry credentials
+var secretParamPattern = regexp.MustCompile(`(?i)\b(api_key|access_token|token)=[^&\s"']*`)
+
+// bearerPattern matches a bearer token, as in an Authorization header
+var bearerPattern = regexp.MustCompile(`(?i)\b(bearer)\s+[A-Za-z0-9._~+/=-]+`)
+
+// redactSecrets replaces API keys and bearer tokens in text with REDACTED.
+// Errors from the HTTP client include the request URL, so they are redacted
+// as well as the URL itself.
+func redactSecrets(text string) string {
+	text = secretParamPattern.ReplaceAllString(text, "${1}=REDACTED")
+	return bearerPattern.ReplaceAllString(text, "${1} REDACTED")
+}
+
+// recordFailure logs a request that returned an error or an error status.
+// Non-error responses, including 304 Not Modified, are not logged.
+func (s *Scraper) recordFailure(req *http.Request, endpoint string, resp *http.Response, err error) {
+	if s.requestLog == nil || (err == nil && resp.StatusCode < http.StatusBadRequest) {
+		return
+	}
+
+	entry := FailedRequest{
+		Time:     s.now().UTC(),
+		Endpoint: endpoint,
+		Method:   req.Method,
+		URL:      redactSecrets(req.URL.String()),
+	}
+	if err != nil {
+		entry.Error = redactSecrets(err.Error())
+	} else {
+		entry.Status = resp.StatusCode
+	}
+
+	s.requestLog.mu.Lock()
+	defer s.requestLog.mu.Unlock()
+	s.requestLog.enc.Encode(entry)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	testCases := map[string]string{
+		"https://api.themoviedb.org/3/search/movie?api_key=abc123&query=Ghost": "https://api.themoviedb.org/3/search/movie?api_key=REDACTED&query=Ghost",
+		"https://example.com/movie?query=Ghost&access_token=xyz":               "https://example.com/movie?query=Ghost&access_token=REDACTED",
+		"Authorization: Bearer eyJhbGciOi.J9.sig":                              "Authorization: Bearer REDACTED",
+		`Get "http://127.0.0.1:1/x?api_key=abc": connection refused`:           `Get "http://127.0.0.1:1/x?api_key=REDACTED": connection refused`,
+		"https://comedybangbang.fandom.com/wiki/Scott_Hasn%27t_Seen":           "https://comedybangbang.fandom.com/wiki/Scott_Hasn%27t_Seen",
+	}
+	for input, expected := range testCases {
+		if got := redactSecrets(input); got != expected {
+			t.Errorf("redactSecrets(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
+
+func TestFailedRequestLog(t *testing.T) {
+	mock := newMockTMDB(t, []string{"Space Jam", "Ghost", "The Addams Family"}, mockCatalog)
+	mock.rateLimited["Ghost"] = true
+	mock.idStatus[2907] = http.StatusInternalServerError
+
+	var buf bytes.Buffer
+	scraper := mock.newTestScraper(defaultConfig())
+	WithFailedRequestLog(&buf)(scraper)
+
+	if _, err := scraper.generateRadarrList(context.Background()); err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+
+	if strings.Contains(buf.String(), "dummy_key") {
+		t.Fatalf("API key leaked into the log:\n%s", buf.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 failed requests, got:\n%s", buf.String())
+	}
+	statuses := make(map[string]int)
+	for _, line := range lines {
+		var entry FailedRequest
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Failed to decode %s: %v", line, err)
+		}
+		if entry.Method != "GET" || !strings.Contains(entry.URL, "api_key=REDACTED") || entry.Time.IsZero() {
+			t.Errorf("Unexpected entry %+v", entry)
+		}
+		statuses[entry.Endpoint] = entry.Status
+	}
+	if statuses[endpointSearch] != http.StatusTooManyRequests || statuses[endpointExternalIDs] != http.StatusInternalServerError {
+		t.Errorf("Expected a 429 search and a 500 external IDs lookup, got %v", statuses)
+	}
+}
+
+func TestFailedRequestLogRedactsClientErrors(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := defaultConfig()
+	cfg.TMDBBaseURL = "http://127.0.0.1:1"
+	scraper := NewScraper("secret_key", WithConfig(cfg), WithFailedRequestLog(&buf))
+
+	if _, err := scraper.searchMovie("Ghost"); err == nil {
+		t.Fatal("Expected the search to fail")
+	}
+
+	var entry FailedRequest
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Failed to decode %s: %v", buf.String(), err)
+	}
+	if entry.Error == "" || entry.Status != 0 {
+		t.Errorf("Expected a connection error without a status, got %+v", entry)
+	}
+	if strings.Contains(buf.String(), "secret_key") {
+		t.Errorf("API key leaked into the log: %s", buf.String())
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// resolvedEntry is a title's result in the -resolved-set file, with when it
+// was looked up so it can expire
+type resolvedEntry struct {
+	Movie      Movie     `json:"movie"`
+	ResolvedAt time.Time `json:"resolved_at"`
+}
+
+// resolvedSet is the file kept by -resolved-set between runs. Titles in it
+// are answered from the file instead of TMDB until -resolved-ttl passes, so
+// a run only looks up titles that are new to the wiki.
+type resolvedSet struct {
+	Settings string                   `json:"settings,omitempty"` // matchSettings of the run that wrote it
+	Titles   map[string]resolvedEntry `json:"titles"`             // normalized wiki title -> result
+}
+
+// matchSettings describes the flags that change which movie a title resolves
+// to. A resolved set written under other settings holds answers to different
+// questions, so it is discarded rather than reused.
+func (c Config) matchSettings() string {
+	return fmt.Sprintf("language=%s match-strategy=%s prefer-original-title=%t include-adult=%t year-tiebreak=%s",
+		c.Language, c.MatchStrategy, c.PreferOriginalTitle, c.IncludeAdult, c.YearTieBreak)
+}
+
+// loadResolvedSet reads a -resolved-set file. A missing file is an empty set,
+// since the first run has nothing to reuse.
+func loadResolvedSet(filename string) (resolvedSet, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return resolvedSet{Titles: map[string]resolvedEntry{}}, nil
+	}
+	if err != nil {
+		return resolvedSet{}, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+
+	var set resolvedSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return resolvedSet{}, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	if set.Titles == nil {
+		set.Titles = map[string]resolvedEntry{}
+	}
+	return set, nil
+}
+
+// useResolvedSet seeds the cache with the unexpired titles in a -resolved-set
+// file. Expired titles are dropped and looked up again, as is the whole set
+// if it was written with different match settings.
+func (s *Scraper) useResolvedSet(filename string) error {
+	set, err := loadResolvedSet(filename)
+	if err != nil {
+		return err
+	}
+	if settings := s.config.matchSettings(); len(set.Titles) > 0 && set.Settings != settings {
+		fmt.Fprintf(s.logWriter(), "Discarding %d resolved titles from %s, which were matched with settings other than %s\n",
+			len(set.Titles), filename, settings)
+		set.Titles = map[string]resolvedEntry{}
+	}
+
+	s.resolved = make(map[string]resolvedEntry, len(set.Titles))
+	expired := 0
+	for title, entry := range set.Titles {
+		if s.config.ResolvedTTL > 0 && s.now().Sub(entry.ResolvedAt) > s.config.ResolvedTTL {
+			expired++
+			continue
+		}
+		s.resolved[title] = entry
+		s.cache.storeTitle(title, entry.Movie)
+		if entry.Movie.TMDBID > 0 && entry.Movie.IMDBID != "" {
+			s.cache.storeIMDBID(entry.Movie.TMDBID, entry.Movie.IMDBID)
+		}
+	}
+	fmt.Fprintf(s.logWriter(), "Reusing %d resolved titles from %s (%d expired)\n", len(s.resolved), filename, expired)
+	return nil
+}
+
+// saveResolvedSet writes every title resolved this run or reused from the
+// file back to it. Reused titles keep their original lookup time, so they
+// still expire on schedule.
+func (s *Scraper) saveResolvedSet(filename string) error {
+	titles := s.cache.titlesCopy()
+	set := resolvedSet{Settings: s.config.matchSettings(), Titles: make(map[string]resolvedEntry, len(titles))}
+	added := 0
+	for title, movie := range titles {
+		entry, ok := s.resolved[title]
+		if !ok {
+			entry = resolvedEntry{Movie: movie, ResolvedAt: s.now().UTC()}
+			added++
+		}
+		set.Titles[title] = entry
+	}
+
+	data, err := json.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode resolved set: %w", err)
+	}
+	if err := s.writeFile(filename, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write resolved set: %w", err)
+	}
+
+	fmt.Fprintf(s.logWriter(), "Saved %d resolved titles (%d new) to %s\n", len(set.Titles), added, filename)
+	return nil
+}
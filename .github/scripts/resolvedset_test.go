@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResolvedSet(t *testing.T) {
+	mock := newMockTMDB(t, []string{"Space Jam", "Ghost"}, mockCatalog)
+	var searches int64
+	mock.searchHook = func(query string) { atomic.AddInt64(&searches, 1) }
+	filename := filepath.Join(t.TempDir(), "resolved.json")
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cfg := defaultConfig()
+	run := func(now time.Time) []Movie {
+		t.Helper()
+		atomic.StoreInt64(&searches, 0)
+		scraper := mock.newTestScraper(cfg)
+		scraper.now = fixedClock(now)
+		if err := scraper.useResolvedSet(filename); err != nil {
+			t.Fatalf("Failed to load resolved set: %v", err)
+		}
+		movies, err := scraper.generateRadarrList(context.Background())
+		if err != nil {
+			t.Fatalf("Failed to generate list: %v", err)
+		}
+		if err := scraper.saveResolvedSet(filename); err != nil {
+			t.Fatalf("Failed to save resolved set: %v", err)
+		}
+		return movies
+	}
+
+	// The first run has nothing to reuse
+	run(start)
+	if got := atomic.LoadInt64(&searches); got != 2 {
+		t.Errorf("Expected 2 searches on the first run, got %d", got)
+	}
+
+	// A title new to the wiki is the only one looked up
+	mock.wikiTitles = append(mock.wikiTitles, "The Addams Family")
+	movies := run(start.Add(24 * time.Hour))
+	if len(movies) != 3 {
+		t.Errorf("Expected the new title merged with the reused ones, got %+v", movies)
+	}
+	if got := atomic.LoadInt64(&searches); got != 1 {
+		t.Errorf("Expected only the new title to be searched, got %d searches", got)
+	}
+
+	// Reused titles keep their lookup time, so the first two expire first
+	set, err := loadResolvedSet(filename)
+	if err != nil {
+		t.Fatalf("Failed to reload resolved set: %v", err)
+	}
+	if at := set.Titles[normalizeTitle("Ghost")].ResolvedAt; !at.Equal(start) {
+		t.Errorf("Expected Ghost to keep its first lookup time, got %v", at)
+	}
+	run(start.Add(30*24*time.Hour + time.Hour))
+	if got := atomic.LoadInt64(&searches); got != 2 {
+		t.Errorf("Expected the 2 expired titles to be searched again, got %d", got)
+	}
+
+	// A set matched with other settings is looked up afresh
+	cfg.MatchStrategy = matchStrategyMostPopular
+	run(start.Add(30*24*time.Hour + 2*time.Hour))
+	if got := atomic.LoadInt64(&searches); got != 3 {
+		t.Errorf("Expected every title to be searched again after the settings changed, got %d", got)
+	}
+}
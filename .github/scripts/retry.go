@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// retryable reports whether a failed lookup is worth another attempt: TMDB
+// rate limiting, server errors and network errors are usually transient,
+// while a search without results or an exhausted quota won't change
+func retryable(err error) bool {
+	if errors.Is(err, errNoResults) || errors.Is(err, errQuotaExceeded) {
+		return false
+	}
+	var status *tmdbStatusError
+	if errors.As(err, &status) {
+		return status.StatusCode == http.StatusTooManyRequests || status.StatusCode >= http.StatusInternalServerError
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// maxRetryDelay is where the doubling stops, so a large -retries waits at
+// most this long between attempts instead of overflowing
+const maxRetryDelay = 10 * time.Minute
+
+// retryDelay returns the pause before the given retry, doubling -retry-backoff
+// for each one up to maxRetryDelay. A -retry-backoff above it is used as is.
+func (s *Scraper) retryDelay(retry int) time.Duration {
+	delay := s.config.RetryBackoff
+	for i := 1; i < retry && delay > 0 && delay <= maxRetryDelay/2; i++ {
+		delay *= 2
+	}
+	return delay
+}
+
+// retryLookup calls lookup, retrying transient failures up to -retries times
+// like searchWithRetries. The label names the lookup in progress lines.
+func (s *Scraper) retryLookup(ctx context.Context, label string, lookup func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := lookup()
+		if err == nil || attempt > s.config.Retries || !retryable(err) {
+			return err
+		}
+
+		delay := s.retryDelay(attempt)
+		s.titlef("  Retrying %s in %s (retry %d of %d): %v\n", label, delay, attempt, s.config.Retries, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// searchWithRetries resolves a title, retrying transient failures up to
+// -retries times. It returns the number of attempts made alongside the
+// result of the last one.
+func (s *Scraper) searchWithRetries(ctx context.Context, title string) (*Movie, int, error) {
+	for attempt := 1; ; attempt++ {
+		movie, err := s.searchMovie(title)
+		if err == nil || attempt > s.config.Retries || !retryable(err) {
+			return movie, attempt, err
+		}
+
+		delay := s.retryDelay(attempt)
+		s.titlef("  Retrying %s in %s (retry %d of %d): %v\n", title, delay, attempt, s.config.Retries, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, attempt, err
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRetryable(t *testing.T) {
+	connectionRefused := &url.Error{Op: "Get", URL: "http://127.0.0.1:1/search/movie", Err: errors.New("connection refused")}
+	testCases := []struct {
+		err      error
+		expected bool
+	}{
+		{fmt.Errorf("%w for 'Ghost'", errNoResults), false},
+		{errQuotaExceeded, false},
+		{&tmdbStatusError{StatusCode: http.StatusTooManyRequests}, true},
+		{fmt.Errorf("failed to get IMDB ID: %w", &tmdbStatusError{StatusCode: http.StatusBadGateway}), true},
+		{&tmdbStatusError{StatusCode: http.StatusUnauthorized}, false},
+		{fmt.Errorf("failed to make request: %w", connectionRefused), true},
+		{errors.New("failed to decode response"), false},
+	}
+	for _, tc := range testCases {
+		if got := retryable(tc.err); got != tc.expected {
+			t.Errorf("retryable(%v) = %v, expected %v", tc.err, got, tc.expected)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.RetryBackoff = 100 * time.Millisecond
+	scraper := NewScraper("dummy_key", WithConfig(cfg))
+	for retry, expected := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond} {
+		if got := scraper.retryDelay(retry); got != expected {
+			t.Errorf("Retry %d: expected %s, got %s", retry, expected, got)
+		}
+	}
+
+	for _, retry := range []int{13, 64, 1000} {
+		if got := scraper.retryDelay(retry); got <= 0 || got > maxRetryDelay {
+			t.Errorf("Retry %d: expected a delay capped at %s, got %s", retry, maxRetryDelay, got)
+		}
+	}
+}
+
+func TestRetriesTrackAttempts(t *testing.T) {
+	mock := newMockTMDB(t, []string{"Space Jam", "Ghost", "Unknown Movie"}, mockCatalog)
+	mock.flakySearch["Space Jam"] = 1 // recovers on the first retry
+	mock.rateLimited["Ghost"] = true  // never recovers
+
+	cfg := defaultConfig()
+	cfg.Retries = 2
+	cfg.RetryBackoff = time.Millisecond
+	scraper := mock.newTestScraper(cfg)
+
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if len(movies) != 1 || movies[0].Title != "Space Jam" {
+		t.Errorf("Expected Space Jam to resolve after a retry, got %+v", movies)
+	}
+
+	attempts := make(map[string]int)
+	for _, failure := range scraper.failures {
+		attempts[failure.Title] = failure.Attempts
+	}
+	if attempts["Ghost"] != 3 {
+		t.Errorf("Expected Ghost to fail after 3 attempts, got %d", attempts["Ghost"])
+	}
+	if attempts["Unknown Movie"] != 1 {
+		t.Errorf("Expected no retries for a search without results, got %d attempts", attempts["Unknown Movie"])
+	}
+}
+
+func TestRetriesStopOnCancel(t *testing.T) {
+	mock := newMockTMDB(t, nil, mockCatalog)
+	mock.rateLimited["Ghost"] = true
+
+	cfg := defaultConfig()
+	cfg.Retries = 5
+	cfg.RetryBackoff = time.Hour
+	scraper := mock.newTestScraper(cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, attempts, err := scraper.searchWithRetries(ctx, "Ghost"); err == nil || attempts != 1 {
+		t.Errorf("Expected the first failure after cancellation, got %d attempts (%v)", attempts, err)
+	}
+}
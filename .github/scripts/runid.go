@@ -0,0 +1,25 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// newRunID returns a random version 4 UUID identifying one run, so its logs,
+// summary and output files can be tied together
+func newRunID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("failed to generate run ID: %v", err))
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// WithRunID sets the run ID instead of generating one
+func WithRunID(id string) Option {
+	return func(s *Scraper) {
+		s.runID = id
+	}
+}
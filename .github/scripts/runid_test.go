@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestNewRunID(t *testing.T) {
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	first, second := newRunID(), newRunID()
+	for _, id := range []string{first, second} {
+		if !uuidPattern.MatchString(id) {
+			t.Errorf("Expected a version 4 UUID, got %q", id)
+		}
+	}
+	if first == second {
+		t.Errorf("Expected distinct run IDs, got %q twice", first)
+	}
+	if id := NewScraper("dummy_key").runID; id == "" {
+		t.Error("Expected NewScraper to generate a run ID")
+	}
+}
+
+func TestRunIDInArtifacts(t *testing.T) {
+	const runID = "0f8fad5b-d9cb-469f-a165-70867728950e"
+	movies := []Movie{{Title: "Space Jam", IMDBID: "tt0117705", Year: 1996}}
+
+	cfg := defaultConfig()
+	cfg.Format = formatWrapped
+	cfg.RunIDInFilename = true
+	clock := fixedClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	scraper := NewScraper("dummy_key", WithConfig(cfg), WithClock(clock), WithRunID(runID))
+
+	var buf bytes.Buffer
+	if err := scraper.writeList(&buf, movies); err != nil {
+		t.Fatalf("Failed to write list: %v", err)
+	}
+	var wrapped wrappedList
+	if err := json.Unmarshal(buf.Bytes(), &wrapped); err != nil || wrapped.RunID != runID {
+		t.Errorf("Expected run_id %s in the wrapped list, got %s (%v)", runID, buf.String(), err)
+	}
+
+	if got := scraper.snapshot(&runCounters{}, newProgress(&buf, 0, false, 0), clock()).RunID; got != runID {
+		t.Errorf("Expected run_id %s in the summary, got %q", runID, got)
+	}
+
+	if got := scraper.archiveFilename(".json"); got != mainOutputBase+"_20240102_030405_"+runID+".json" {
+		t.Errorf("Unexpected archive filename %s", got)
+	}
+
+	// The bare-array format has nowhere to put it
+	scraper.config.Format = formatNative
+	buf.Reset()
+	scraper.writeList(&buf, movies)
+	if bytes.Contains(buf.Bytes(), []byte(runID)) {
+		t.Errorf("Expected no run ID in the native format, got %s", buf.String())
+	}
+}
+
+func TestOutputUnchangedIgnoresRunID(t *testing.T) {
+	movies := []Movie{{Title: "Space Jam", IMDBID: "tt0117705", Year: 1996}}
+	filename := filepath.Join(t.TempDir(), "scott_hasnt_seen.json")
+
+	cfg := defaultConfig()
+	cfg.Format = formatWrapped
+	if err := NewScraper("dummy_key", WithConfig(cfg)).saveToFile(movies, filename); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+
+	// A later run has a new run ID but the same movies
+	if unchanged, err := NewScraper("dummy_key", WithConfig(cfg)).outputUnchanged(movies, filename); err != nil || !unchanged {
+		t.Errorf("Expected the list to count as unchanged, got %v (%v)", unchanged, err)
+	}
+}
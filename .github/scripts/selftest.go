@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// selfTestTitle is resolved by -selftest; any of selfTestIMDBIDs is accepted
+// since TMDB may rank either adaptation first
+const selfTestTitle = "Dune"
+
+var selfTestIMDBIDs = map[string]bool{
+	"tt1160419": true, // Dune (2021)
+	"tt0087182": true, // Dune (1984)
+}
+
+// selfTestCheck is one step of -selftest
+type selfTestCheck struct {
+	Name string
+	Run  func() error
+}
+
+// selfTestChecks returns the -selftest steps in the order they run
+func (s *Scraper) selfTestChecks() []selfTestCheck {
+	return []selfTestCheck{
+		{Name: "TMDB authentication", Run: s.checkTMDBAuth},
+		{Name: "wiki reachable", Run: s.checkWikiReachable},
+		{Name: fmt.Sprintf("%q resolves", selfTestTitle), Run: s.checkKnownTitle},
+	}
+}
+
+// selfTest runs every check, printing the outcome of each, and reports
+// whether all of them passed
+func (s *Scraper) selfTest() bool {
+	passed := true
+	for _, check := range s.selfTestChecks() {
+		if err := check.Run(); err != nil {
+			fmt.Fprintf(s.logWriter(), "  %s %s: %v\n", s.failMark(), check.Name, err)
+			passed = false
+			continue
+		}
+		fmt.Fprintf(s.logWriter(), "  %s %s\n", s.okMark(), check.Name)
+	}
+	return passed
+}
+
+// checkTMDBAuth verifies the API key against TMDB's authentication endpoint
+func (s *Scraper) checkTMDBAuth() error {
+	params := url.Values{}
+	params.Add("api_key", s.tmdbAPIKey)
+
+	req, err := http.NewRequest("GET", s.tmdbBaseURL+"/authentication?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.doRequest(req, endpointAuth, "api key")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &tmdbStatusError{StatusCode: resp.StatusCode, Target: "authentication"}
+	}
+	return nil
+}
+
+// checkWikiReachable fetches the wiki page, ignoring any saved validators
+func (s *Scraper) checkWikiReachable() error {
+	req, err := http.NewRequest("GET", s.wikiURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.doRequest(req, endpointWiki, s.wikiURL)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("wiki page returned status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// checkKnownTitle resolves selfTestTitle and checks its IMDB ID
+func (s *Scraper) checkKnownTitle() error {
+	movie, err := s.searchMovieExact(selfTestTitle)
+	if err != nil {
+		return err
+	}
+	if !selfTestIMDBIDs[movie.IMDBID] {
+		return fmt.Errorf("resolved to %s (IMDB %q), not a known %s", movie.Title, movie.IMDBID, selfTestTitle)
+	}
+	return nil
+}
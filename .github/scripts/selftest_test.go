@@ -0,0 +1,40 @@
+package main
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestSelfTest(t *testing.T) {
+	dune := mockMovie{ID: 438631, Title: "Dune", ReleaseDate: "2021-09-15", IMDBID: "tt1160419"}
+	mock := newMockTMDB(t, nil, []mockMovie{dune})
+
+	scraper := mock.newTestScraper(defaultConfig())
+	if !scraper.selfTest() {
+		t.Error("Expected the self-test to pass")
+	}
+	if requests := atomic.LoadInt64(&scraper.tmdbRequests); requests > 5 {
+		t.Errorf("Expected at most a handful of TMDB requests, got %d", requests)
+	}
+
+	// A bad API key fails authentication
+	scraper = mock.newTestScraper(defaultConfig())
+	scraper.tmdbAPIKey = "wrong_key"
+	if err := scraper.checkTMDBAuth(); err == nil {
+		t.Error("Expected authentication to fail with a bad key")
+	}
+
+	// An unreachable wiki fails
+	scraper = mock.newTestScraper(defaultConfig())
+	scraper.wikiURL = mock.URL + "/missing"
+	if err := scraper.checkWikiReachable(); err == nil {
+		t.Error("Expected an unreachable wiki to fail")
+	}
+
+	// An unexpected match for the known title fails
+	mock.movies["Dune"] = mockMovie{ID: 1, Title: "Dune", ReleaseDate: "2000-12-03", IMDBID: "tt0142032"}
+	scraper = mock.newTestScraper(defaultConfig())
+	if scraper.selfTest() {
+		t.Error("Expected the self-test to fail for an unknown IMDB ID")
+	}
+}
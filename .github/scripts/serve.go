@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// server answers serve mode's HTTP requests. Each refresh builds a fresh
+// scraper, since a scraper holds the state of a single run.
+type server struct {
+	newScraper func(opts ...Option) *Scraper
+
+	refreshing sync.Mutex // held for the whole of a refresh
+}
+
+// handler routes serve mode's endpoints
+func (v *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stream", v.handleStream)
+	return mux
+}
+
+// handleStream runs a refresh, streaming each movie as a JSON line as soon as
+// it resolves, then writes the list files as a normal run would. The refresh
+// runs under the request's context, so it is cancelled if the client
+// disconnects. Only one refresh runs at a time.
+func (v *server) handleStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !v.refreshing.TryLock() {
+		http.Error(w, "a refresh is already running", http.StatusConflict)
+		return
+	}
+	defer v.refreshing.Unlock()
+
+	stream := newStreamSink(w)
+	scraper := v.newScraper(WithSink(stream))
+	defer scraper.Close()
+	stream.scraper = scraper
+	log.Printf("Refreshing for /stream, run ID %s", scraper.runID)
+
+	movies, err := scraper.generateRadarrList(r.Context())
+	if errors.Is(err, errWikiNotModified) && !stream.started() {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if err != nil {
+		log.Printf("Refresh failed: %v", err)
+		if !stream.started() {
+			http.Error(w, "refresh failed", http.StatusBadGateway)
+		}
+		return
+	}
+
+	if err := scraper.finishSinks(movies); err != nil {
+		log.Printf("Failed to write the list: %v", err)
+	}
+	// A refresh that resolved nothing still answers 200 with an empty body
+	stream.start()
+}
+
+// streamSink writes each resolved movie to an HTTP response as a JSON line,
+// flushing after every movie so the client sees it straight away. Movies are
+// prepared as the list files write them.
+type streamSink struct {
+	scraper *Scraper
+
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	enc     *json.Encoder
+	flusher http.Flusher
+	written bool
+}
+
+func newStreamSink(w http.ResponseWriter) *streamSink {
+	flusher, _ := w.(http.Flusher)
+	return &streamSink{w: w, enc: json.NewEncoder(w), flusher: flusher}
+}
+
+// start sends the response header if nothing has been written yet
+func (s *streamSink) start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.startLocked()
+}
+
+func (s *streamSink) startLocked() {
+	if s.written {
+		return
+	}
+	s.w.Header().Set("Content-Type", "application/x-ndjson")
+	s.w.WriteHeader(http.StatusOK)
+	s.written = true
+}
+
+// started reports whether the response header has been sent
+func (s *streamSink) started() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.written
+}
+
+// Add writes the movie as one JSON line. Write errors mean the client has
+// gone; the request's context cancels the refresh, so they are ignored.
+func (s *streamSink) Add(movie Movie) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.startLocked()
+	s.enc.Encode(s.scraper.prepareForOutput([]Movie{movie})[0])
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+}
+
+// Finish does nothing; every movie has already been streamed
+func (s *streamSink) Finish(movies []Movie) error {
+	return nil
+}
+
+// serve listens on addr and answers serve mode's endpoints until ctx is
+// cancelled, then shuts down, cancelling any refresh in progress
+func serve(ctx context.Context, addr string, newScraper func(opts ...Option) *Scraper) error {
+	v := &server{newScraper: newScraper}
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           v.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// newTestServer serves refreshes against the mock. The default sinks are
+// dropped so refreshes don't write list files into the repository.
+func newTestServer(m *mockTMDB, cfg Config) *server {
+	return &server{newScraper: func(opts ...Option) *Scraper {
+		scraper := m.newTestScraper(cfg)
+		scraper.sinks = nil
+		for _, opt := range opts {
+			opt(scraper)
+		}
+		return scraper
+	}}
+}
+
+func TestServeStream(t *testing.T) {
+	mock := newMockTMDB(t, []string{"Space Jam", "Ghost", "Unknown Movie"}, mockCatalog)
+	srv := httptest.NewServer(newTestServer(mock, defaultConfig()).handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/stream")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("Expected a JSON-lines stream, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	titles := make(map[string]bool)
+	lines := bufio.NewScanner(resp.Body)
+	for lines.Scan() {
+		var movie Movie
+		if err := json.Unmarshal(lines.Bytes(), &movie); err != nil {
+			t.Fatalf("Failed to decode line %q: %v", lines.Text(), err)
+		}
+		titles[movie.Title] = true
+	}
+	if len(titles) != 2 || !titles["Space Jam"] || !titles["Ghost"] {
+		t.Errorf("Expected Space Jam and Ghost to be streamed, got %v", titles)
+	}
+}
+
+func TestServeStreamRejects(t *testing.T) {
+	mock := newMockTMDB(t, []string{"Space Jam"}, mockCatalog)
+	v := newTestServer(mock, defaultConfig())
+	srv := httptest.NewServer(v.handler())
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/stream", "text/plain", nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected POST to be rejected, got %d", resp.StatusCode)
+	}
+
+	v.refreshing.Lock()
+	resp, err = http.Get(srv.URL + "/stream")
+	v.refreshing.Unlock()
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("Expected a second refresh to be refused, got %d", resp.StatusCode)
+	}
+}
+
+func TestServeStreamMatchesFile(t *testing.T) {
+	mock := newMockTMDB(t, nil, mockCatalog)
+	mock.wikiPage = `<html><body><table>
+<tr><th>Film</th><th>Guest</th></tr>
+<tr><td><i>Space Jam</i>[1]</td><td>Paul F. Tompkins</td></tr>
+<tr><td><i>Ghost</i></td><td>Lauren Lapkus</td></tr>
+</table></body></html>`
+	cfg := defaultConfig()
+	cfg.IncludeGuest = true
+	cfg.IncludeRawTitle = true
+
+	var scraper *Scraper
+	file := &recordingSink{}
+	v := &server{newScraper: func(opts ...Option) *Scraper {
+		scraper = mock.newTestScraper(cfg)
+		scraper.sinks = []Sink{file}
+		for _, opt := range opts {
+			opt(scraper)
+		}
+		return scraper
+	}}
+	srv := httptest.NewServer(v.handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/stream")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	streamed := make(map[string]Movie)
+	lines := bufio.NewScanner(resp.Body)
+	for lines.Scan() {
+		var movie Movie
+		if err := json.Unmarshal(lines.Bytes(), &movie); err != nil {
+			t.Fatalf("Failed to decode line %q: %v", lines.Text(), err)
+		}
+		streamed[movie.Title] = movie
+	}
+
+	// The file sink's records, as written
+	var buf bytes.Buffer
+	if err := scraper.writeList(&buf, scraper.prepareForOutput(file.finished)); err != nil {
+		t.Fatalf("Failed to write list: %v", err)
+	}
+	var written []Movie
+	if err := json.Unmarshal(buf.Bytes(), &written); err != nil {
+		t.Fatalf("Failed to decode list: %v", err)
+	}
+	if len(written) != 2 || len(streamed) != 2 {
+		t.Fatalf("Expected 2 movies in both, got %+v and %+v", written, streamed)
+	}
+	for _, movie := range written {
+		if !reflect.DeepEqual(streamed[movie.Title], movie) {
+			t.Errorf("Expected the streamed record to match the file's:\n%+v\n%+v", streamed[movie.Title], movie)
+		}
+		if movie.Guest == "" || movie.RawTitle == "" || movie.Hash == "" || movie.MatchMethod != "" {
+			t.Errorf("Expected the guest, raw title and hash without match info, got %+v", movie)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Sink is a destination for the resolved movie list. Add is called by the
+// worker pool as each movie resolves, possibly from several goroutines at
+// once, before deduplication and the list filters run. Finish is called once
+// with the final list. Sinks that only need the final list can ignore Add.
+type Sink interface {
+	Add(movie Movie)
+	Finish(movies []Movie) error
+}
+
+// WithSink adds a sink the list is written to, alongside the default sinks
+// that write the files in the repository root
+func WithSink(sink Sink) Option {
+	return func(s *Scraper) {
+		s.sinks = append(s.sinks, sink)
+	}
+}
+
+// defaultSinks returns the sinks writing the files in the repository root:
+// the combined list, per-genre lists with -split-by-genre, or both
+func (s *Scraper) defaultSinks() []Sink {
+	// A -titles-file list isn't the wiki's, so it never replaces the
+	// committed files or their archives
+	if s.config.TitleListFile != "" {
+		return nil
+	}
+	switch s.config.SplitByGenre {
+	case splitByGenreAlso:
+		return []Sink{fileSink{scraper: s}, genreSink{scraper: s}}
+	case splitByGenreOnly:
+		return []Sink{genreSink{scraper: s}}
+	default:
+		return []Sink{fileSink{scraper: s}}
+	}
+}
+
+// addToSinks passes a resolved movie to every sink
+func (s *Scraper) addToSinks(movie Movie) {
+	for _, sink := range s.sinks {
+		sink.Add(movie)
+	}
+}
+
+// finishSinks passes the final list to every sink, returning their errors
+// joined. A failing sink doesn't stop the others.
+func (s *Scraper) finishSinks(movies []Movie) error {
+	var errs []error
+	for _, sink := range s.sinks {
+		if err := sink.Finish(movies); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// fileSink writes the list and RSS files, with their timestamped copies
+type fileSink struct {
+	scraper *Scraper
+}
+
+// Add does nothing; the files are written from the final list
+func (f fileSink) Add(movie Movie) {}
+
+// Finish writes the output files
+func (f fileSink) Finish(movies []Movie) error {
+	return f.scraper.saveOutputs(movies)
+}
+
+// Modes accepted by -split-by-genre
+const (
+	splitByGenreAlso = "also" // per-genre files alongside the combined list
+	splitByGenreOnly = "only" // per-genre files instead of the combined list
+)
+
+// genreSink writes one list file per genre. A movie with several genres is in
+// each of their files.
+type genreSink struct {
+	scraper *Scraper
+}
+
+// Add does nothing; the files are written from the final list
+func (g genreSink) Add(movie Movie) {}
+
+// Finish writes a file per genre, named from the genre's output label
+func (g genreSink) Finish(movies []Movie) error {
+	groups := moviesByGenre(movies)
+	genres := make([]string, 0, len(groups))
+	for genre := range groups {
+		genres = append(genres, genre)
+	}
+	sort.Strings(genres)
+
+	var errs []error
+	for _, genre := range genres {
+		filename := genreFilename(genre, formatExtension(g.scraper.config.Format))
+		if err := g.scraper.saveToFile(groups[genre], filename); err != nil {
+			errs = append(errs, fmt.Errorf("genre %s: %w", genre, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// moviesByGenre groups movies by each of their genres, keeping the list's
+// sort order within each group. Movies without genres are left out.
+func moviesByGenre(movies []Movie) map[string][]Movie {
+	groups := make(map[string][]Movie)
+	for _, movie := range movies {
+		for _, genre := range movie.Genres {
+			groups[genre] = append(groups[genre], movie)
+		}
+	}
+	for _, group := range groups {
+		sortMovies(group)
+	}
+	return groups
+}
+
+// unsafeFilenameChars matches characters replaced in genre filenames
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._+-]+`)
+
+// genreFilename returns the per-genre list file for a genre label, next to the
+// combined list, e.g. ../../scott_horror.json
+func genreFilename(genre, extension string) string {
+	name := strings.Trim(unsafeFilenameChars.ReplaceAllString(strings.ToLower(genre), "_"), "_.")
+	return fmt.Sprintf("%s/scott_%s%s", filepath.Dir(mainOutputBase), name, extension)
+}
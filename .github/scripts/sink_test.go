@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingSink remembers what it was given
+type recordingSink struct {
+	mu       sync.Mutex
+	added    []string
+	finished []Movie
+	err      error
+}
+
+func (r *recordingSink) Add(movie Movie) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.added = append(r.added, movie.Title)
+}
+
+func (r *recordingSink) Finish(movies []Movie) error {
+	r.finished = movies
+	return r.err
+}
+
+func TestSinkReceivesResolvedMovies(t *testing.T) {
+	mock := newMockTMDB(t, []string{"Space Jam", "The Addams Family", "Ghost", "Unknown Movie"}, mockCatalog)
+
+	sink := &recordingSink{}
+	cfg := defaultConfig()
+	cfg.StateFile = ""
+	cfg.TMDBBaseURL = mock.URL
+	scraper := NewScraper("dummy_key", WithConfig(cfg), WithSink(sink))
+	scraper.wikiURL = mock.URL + "/wiki"
+
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+
+	sort.Strings(sink.added)
+	if len(sink.added) != 3 || sink.added[0] != "Ghost" || sink.added[1] != "Space Jam" || sink.added[2] != "The Addams Family" {
+		t.Errorf("Expected each resolved movie to be added once, got %v", sink.added)
+	}
+	if len(scraper.sinks) != 2 {
+		t.Errorf("Expected the file sink to be kept alongside the added sink, got %d sinks", len(scraper.sinks))
+	}
+
+	// Replace the file sink so the test doesn't write to the repository root
+	failing := &recordingSink{err: errors.New("unreachable")}
+	scraper.sinks = []Sink{failing, sink}
+	if err := scraper.finishSinks(movies); err == nil || err.Error() != "unreachable" {
+		t.Errorf("Expected the failing sink's error, got %v", err)
+	}
+	if len(sink.finished) != 3 {
+		t.Errorf("Expected later sinks to still be finished after a failure, got %+v", sink.finished)
+	}
+}
+
+func TestMoviesByGenre(t *testing.T) {
+	movies := []Movie{
+		{Title: "Ghost", IMDBID: "tt0099653", Genres: []string{"fantasy", "drama", "romance"}},
+		{Title: "Space Jam", IMDBID: "tt0117705", Genres: []string{"animation", "comedy", "family"}},
+		{Title: "The Addams Family", IMDBID: "tt0101272", Genres: []string{"comedy", "family"}},
+		{Title: "Undated Film", IMDBID: "tt1234567"},
+	}
+
+	groups := moviesByGenre(movies)
+	if len(groups) != 6 {
+		t.Errorf("Expected 6 genres, got %v", groups)
+	}
+	comedy := groups["comedy"]
+	if len(comedy) != 2 || comedy[0].Title != "Space Jam" || comedy[1].Title != "The Addams Family" {
+		t.Errorf("Expected both comedies in order, got %+v", comedy)
+	}
+	if len(groups["romance"]) != 1 {
+		t.Errorf("Expected Ghost in each of its genres, got %+v", groups["romance"])
+	}
+}
+
+func TestGenreFilename(t *testing.T) {
+	testCases := map[string]string{
+		"horror":          "../../scott_horror.json",
+		"science_fiction": "../../scott_science_fiction.json",
+		"Sci-Fi":          "../../scott_sci-fi.json",
+		"Kids & Family":   "../../scott_kids_family.json",
+		"../etc":          "../../scott_etc.json",
+	}
+	for genre, expected := range testCases {
+		if got := genreFilename(genre, ".json"); got != expected {
+			t.Errorf("genreFilename(%q) = %s, expected %s", genre, got, expected)
+		}
+	}
+}
+
+func TestDefaultSinksSplitByGenre(t *testing.T) {
+	for mode, expected := range map[string][]Sink{
+		"":               {fileSink{}},
+		splitByGenreAlso: {fileSink{}, genreSink{}},
+		splitByGenreOnly: {genreSink{}},
+	} {
+		cfg := defaultConfig()
+		cfg.SplitByGenre = mode
+		sinks := NewScraper("dummy_key", WithConfig(cfg)).sinks
+		if len(sinks) != len(expected) {
+			t.Errorf("Mode %q: expected %d sinks, got %d", mode, len(expected), len(sinks))
+			continue
+		}
+		for i := range sinks {
+			if reflect.TypeOf(sinks[i]) != reflect.TypeOf(expected[i]) {
+				t.Errorf("Mode %q: sink %d is %T, expected %T", mode, i, sinks[i], expected[i])
+			}
+		}
+	}
+
+	if _, err := parseFlags([]string{"-split-by-genre", "yes"}); err == nil {
+		t.Errorf("Expected an unknown -split-by-genre mode to be rejected")
+	}
+}
+
+func TestFileSinkReportsWriteFailures(t *testing.T) {
+	// The list files go two directories up, into a temporary root here
+	root := t.TempDir()
+	work := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(work, 0755); err != nil {
+		t.Fatalf("Failed to create working directory: %v", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(work); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(cwd) })
+
+	// A directory where the RSS file belongs makes its write fail
+	if err := os.Mkdir(filepath.Join(root, "scott_hasnt_seen.xml"), 0755); err != nil {
+		t.Fatalf("Failed to create blocking directory: %v", err)
+	}
+
+	cfg := defaultConfig()
+	cfg.NoTimestamp = true
+	scraper := NewScraper("dummy_key", WithConfig(cfg), WithLogOutput(io.Discard))
+	err = fileSink{scraper: scraper}.Finish([]Movie{{Title: "Ghost", IMDBID: "tt0099653"}})
+	if err == nil || !strings.Contains(err.Error(), "main RSS file") {
+		t.Errorf("Expected the failed RSS write to be reported, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "scott_hasnt_seen.json")); err != nil {
+		t.Errorf("Expected the list to be written despite the RSS failure, got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"sort"
+)
+
+// compareMovies is the default output order: by title, then year, then TMDB
+// ID. Two distinct movies never compare equal, so the order doesn't depend
+// on the order the workers finished in.
+func compareMovies(a, b Movie) int {
+	if c := cmp.Compare(a.Title, b.Title); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Year, b.Year); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.TMDBID, b.TMDBID)
+}
+
+// sortWith sorts movies by a comparator
+func sortWith(movies []Movie, compare func(a, b Movie) int) {
+	sort.Slice(movies, func(i, j int) bool {
+		return compare(movies[i], movies[j]) < 0
+	})
+}
+
+// sortComparator returns the comparator the list is sorted with, or nil
+// with -no-sort, which keeps wiki page order
+func (s *Scraper) sortComparator() func(a, b Movie) int {
+	switch {
+	case s.config.NoSort:
+		return nil
+	case s.config.SortByPopularity:
+		return compareByPopularity
+	case s.config.Sort == sortEpisodeDesc:
+		return compareByEpisodeDesc
+	default:
+		return compareMovies
+	}
+}
+
+// checkTotalOrder reports the first pair of neighbouring movies in a sorted
+// list that the comparator can't tell apart, or that are out of order. Such
+// a pair could come out either way round between runs.
+func checkTotalOrder(movies []Movie, compare func(a, b Movie) int) error {
+	for i := 1; i < len(movies); i++ {
+		switch c := compare(movies[i-1], movies[i]); {
+		case c == 0:
+			return fmt.Errorf("%s and %s compare equal on every sort key", describeMovie(movies[i-1]), describeMovie(movies[i]))
+		case c > 0:
+			return fmt.Errorf("%s sorts after %s but comes first", describeMovie(movies[i-1]), describeMovie(movies[i]))
+		}
+	}
+	return nil
+}
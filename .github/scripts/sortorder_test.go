@@ -0,0 +1,71 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// tiedMovies share titles, popularity and episodes, so every sort relies on
+// its tie-breakers
+var tiedMovies = []Movie{
+	{Title: "The Mummy", IMDBID: "tt0023245", TMDBID: 15849, Year: 1932, Popularity: 12, Episodes: []string{"2020-03-02"}},
+	{Title: "The Mummy", IMDBID: "tt0120616", TMDBID: 564, Year: 1999, Popularity: 12, Episodes: []string{"2020-03-02"}},
+	{Title: "The Mummy", IMDBID: "tt2345759", TMDBID: 282035, Year: 2017, Popularity: 40},
+	{Title: "Dune", IMDBID: "tt0087182", TMDBID: 841, Year: 1984, Popularity: 12, Episodes: []string{"2021-10-18"}},
+	{Title: "Dune", IMDBID: "tt1160419", TMDBID: 438631, Year: 2021, Popularity: 40, Episodes: []string{"2021-10-18"}},
+	{Title: "Ghost", TMDBID: 251, Year: 1990},
+	newPlaceholder("Unknown Movie"),
+}
+
+func TestSortOrdersAreTotal(t *testing.T) {
+	for _, sortBy := range []string{sortTitle, sortPopularity, sortEpisodeDesc} {
+		cfg, err := parseFlags([]string{"-sort", sortBy})
+		if err != nil {
+			t.Fatalf("Failed to parse -sort %s: %v", sortBy, err)
+		}
+		scraper := NewScraper("dummy_key", WithConfig(cfg))
+		compare := scraper.sortComparator()
+
+		var first []Movie
+		shuffle := rand.New(rand.NewSource(1))
+		for i := 0; i < 20; i++ {
+			movies := append([]Movie{}, tiedMovies...)
+			shuffle.Shuffle(len(movies), func(i, j int) { movies[i], movies[j] = movies[j], movies[i] })
+			sortWith(movies, compare)
+
+			if err := checkTotalOrder(movies, compare); err != nil {
+				t.Fatalf("-sort %s is not a total order: %v", sortBy, err)
+			}
+			if first == nil {
+				first = movies
+			} else if !reflect.DeepEqual(movies, first) {
+				t.Fatalf("-sort %s depends on the input order:\n%+v\n%+v", sortBy, first, movies)
+			}
+		}
+	}
+
+	cfg, _ := parseFlags([]string{"-sort", sortWiki})
+	if NewScraper("dummy_key", WithConfig(cfg)).sortComparator() != nil {
+		t.Error("Expected wiki order to have no comparator")
+	}
+}
+
+func TestCheckTotalOrder(t *testing.T) {
+	dune := Movie{Title: "Dune", IMDBID: "tt0087182", TMDBID: 841, Year: 1984}
+	ghost := Movie{Title: "Ghost", IMDBID: "tt0099653", TMDBID: 251, Year: 1990}
+
+	if err := checkTotalOrder([]Movie{dune, ghost}, compareMovies); err != nil {
+		t.Errorf("Expected a sorted list to pass, got %v", err)
+	}
+	if err := checkTotalOrder([]Movie{ghost, dune}, compareMovies); err == nil {
+		t.Error("Expected an out-of-order list to be reported")
+	}
+
+	// Equal on every key, e.g. the same movie listed twice under one ID
+	twin := dune
+	twin.IMDBID = "tt9999999"
+	if err := checkTotalOrder([]Movie{dune, twin}, compareMovies); err == nil {
+		t.Error("Expected movies that compare equal to be reported")
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// errWikiNotModified is returned when the wiki page has not changed since the last run
+var errWikiNotModified = errors.New("wiki page not modified since last run")
+
+// wikiState holds the HTTP validators from the last successful wiki fetch
+type wikiState struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// loadWikiState reads the saved validators, returning an empty state if none exist
+func loadWikiState(filename string) (wikiState, error) {
+	var state wikiState
+	if filename == "" {
+		return state, nil
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return state, nil
+		}
+		return state, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return wikiState{}, fmt.Errorf("failed to parse state file: %w", err)
+	}
+	return state, nil
+}
+
+// save writes the validators to the state file with the given permissions
+func (w wikiState) save(filename string, perm os.FileMode) error {
+	data, err := json.Marshal(w)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+	data = append(data, '\n')
+
+	if err := writeFileMode(filename, data, perm); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// applyTo adds conditional request headers for the saved validators
+func (w wikiState) applyTo(req *http.Request) {
+	if w.ETag != "" {
+		req.Header.Set("If-None-Match", w.ETag)
+	}
+	if w.LastModified != "" {
+		req.Header.Set("If-Modified-Since", w.LastModified)
+	}
+}
+
+// wikiStateFrom captures the validators from a wiki response
+func wikiStateFrom(resp *http.Response) wikiState {
+	return wikiState{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+}
+
+// saveWikiState persists the validators from this run's wiki fetch.
+// It is called only after the outputs are written, so a failed run is retried in full.
+func (s *Scraper) saveWikiState() error {
+	if s.config.StateFile == "" || s.wikiState == nil {
+		return nil
+	}
+	return s.wikiState.save(s.config.StateFile, s.config.FileMode)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestScrapeWikiPageNotModified(t *testing.T) {
+	const etag = `"abc123"`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte("<html><body><i>Space Jam</i></body></html>"))
+	}))
+	defer server.Close()
+
+	cfg := defaultConfig()
+	cfg.StateFile = filepath.Join(t.TempDir(), "state.json")
+	scraper := NewScraper("dummy_key", WithConfig(cfg))
+	scraper.wikiURL = server.URL
+
+	if _, err := scraper.scrapeWikiPage(); err != nil {
+		t.Fatalf("First fetch failed: %v", err)
+	}
+	if err := scraper.saveWikiState(); err != nil {
+		t.Fatalf("Failed to save wiki state: %v", err)
+	}
+
+	if _, err := scraper.scrapeWikiPage(); !errors.Is(err, errWikiNotModified) {
+		t.Errorf("Expected errWikiNotModified on second fetch, got %v", err)
+	}
+
+	scraper.config.Force = true
+	if _, err := scraper.scrapeWikiPage(); err != nil {
+		t.Errorf("Expected -force to bypass the conditional request, got %v", err)
+	}
+}
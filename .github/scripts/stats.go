@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// GenreCount is the number of movies in the list tagged with a genre
+type GenreCount struct {
+	Genre string `json:"genre"`
+	Count int    `json:"count"`
+}
+
+// genreDistribution counts movies per genre, most common first.
+// Genres with equal counts are ordered by name.
+func genreDistribution(movies []Movie) []GenreCount {
+	counts := make(map[string]int)
+	for _, movie := range movies {
+		for _, genre := range movie.Genres {
+			counts[genre]++
+		}
+	}
+
+	distribution := make([]GenreCount, 0, len(counts))
+	for genre, count := range counts {
+		distribution = append(distribution, GenreCount{Genre: genre, Count: count})
+	}
+
+	sort.Slice(distribution, func(i, j int) bool {
+		if distribution[i].Count != distribution[j].Count {
+			return distribution[i].Count > distribution[j].Count
+		}
+		return distribution[i].Genre < distribution[j].Genre
+	})
+
+	return distribution
+}
+
+// printGenreDistribution prints the genre breakdown for the run summary
+func printGenreDistribution(w io.Writer, distribution []GenreCount) {
+	if len(distribution) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\nGenres:\n")
+	for _, entry := range distribution {
+		fmt.Fprintf(w, "  %-16s %d\n", entry.Genre, entry.Count)
+	}
+}
+
+// saveGenreDistribution writes the genre breakdown to a JSON file
+func (s *Scraper) saveGenreDistribution(distribution []GenreCount, filename string) error {
+	data, err := json.MarshalIndent(distribution, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal genre stats: %w", err)
+	}
+	data = append(data, '\n')
+
+	if err := s.writeFile(filename, data); err != nil {
+		return fmt.Errorf("failed to write genre stats file: %w", err)
+	}
+
+	fmt.Fprintf(s.logWriter(), "Saved genre stats to %s\n", filename)
+	return nil
+}
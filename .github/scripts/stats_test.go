@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestGenreDistribution(t *testing.T) {
+	movies := []Movie{
+		{Title: "Ghost", Genres: []string{"romance", "drama", "fantasy"}},
+		{Title: "Dune", Genres: []string{"science_fiction", "adventure"}},
+		{Title: "Sister Act", Genres: []string{"comedy", "drama"}},
+		{Title: "Unmatched Title"},
+	}
+
+	distribution := genreDistribution(movies)
+
+	if len(distribution) != 6 {
+		t.Fatalf("Expected 6 genres, got %d", len(distribution))
+	}
+	if distribution[0] != (GenreCount{Genre: "drama", Count: 2}) {
+		t.Errorf("Expected drama first with 2 movies, got %+v", distribution[0])
+	}
+	// Ties are ordered by genre name
+	if distribution[1].Genre != "adventure" || distribution[5].Genre != "science_fiction" {
+		t.Errorf("Expected ties ordered by name, got %+v", distribution)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// streamRadarrList generates the list like generateRadarrList, but feeds each
+// title to the TMDB workers as soon as it is extracted instead of waiting for
+// the whole page to be parsed. The output is sorted, so it is identical.
+// -since needs a title's earliest air date, which is only known once the
+// whole page is parsed, so with it the titles are resolved afterwards.
+func (s *Scraper) streamRadarrList(ctx context.Context) ([]Movie, error) {
+	htmlContent, err := s.fetchWikiPage()
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.config.Since.IsZero() {
+		fmt.Fprintln(s.logWriter(), "Extracting movie titles (-since needs every air date before resolving)...")
+		movieTitles, err := s.extractMovieTitles(htmlContent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract movie titles: %w", err)
+		}
+		fmt.Fprintf(s.logWriter(), "Found %d unique movies\n", len(movieTitles))
+		return s.resolveTitles(ctx, movieTitles)
+	}
+
+	fmt.Fprintln(s.logWriter(), "Extracting and resolving movie titles...")
+	titles := make(chan string)
+	extracted := make(chan error, 1)
+	go func() {
+		defer close(titles)
+
+		count := 0
+		err := s.eachMovieTitle(htmlContent, func(title string) {
+			count++
+			titles <- title
+		})
+		if err == nil {
+			fmt.Fprintf(s.logWriter(), "Found %d unique movies\n", count)
+		}
+		extracted <- err
+	}()
+
+	movies, err := s.resolveStream(ctx, titles)
+	if extractErr := <-extracted; extractErr != nil {
+		return nil, fmt.Errorf("failed to extract movie titles: %w", extractErr)
+	}
+	return movies, err
+}
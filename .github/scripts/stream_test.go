@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStreamRadarrListMatchesBatch(t *testing.T) {
+	wikiTitles := []string{"The Addams Family", "Space Jam", "Missing Movie", "Ghost", "Space Jam"}
+	mock := newMockTMDB(t, wikiTitles, mockCatalog)
+
+	batch, err := mock.newTestScraper(defaultConfig()).generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate batch list: %v", err)
+	}
+
+	cfg := defaultConfig()
+	cfg.StreamTitles = true
+	scraper := mock.newTestScraper(cfg)
+	streamed, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate streamed list: %v", err)
+	}
+
+	if !reflect.DeepEqual(batch, streamed) {
+		t.Errorf("Streamed list differs from batch list:\n%+v\n%+v", streamed, batch)
+	}
+	if len(scraper.failures) != 1 || scraper.failures[0].Title != "Missing Movie" {
+		t.Errorf("Expected Missing Movie to fail, got %+v", scraper.failures)
+	}
+	if scraper.dropCounts[dropRuleDuplicate] != 1 {
+		t.Errorf("Expected the duplicate to be dropped, got %v", scraper.dropCounts)
+	}
+}
+
+func TestStreamRadarrListTableColumns(t *testing.T) {
+	mock := newMockTMDB(t, nil, mockCatalog)
+	mock.wikiPage = `<html><body><table>
+<tr><th>Episode</th><th>Film</th><th>Guest</th><th>Air date</th></tr>
+<tr><td>Hoops</td><td><i>Space Jam</i></td><td>Paul F. Tompkins</td><td>March 1, 2021</td></tr>
+<tr><td>Spooky</td><td><i>Ghost</i></td><td>Lauren Lapkus</td><td>March 8, 2021</td></tr>
+<tr><td>Family</td><td><i>The Addams Family</i></td><td>Nick Kroll</td><td>March 15, 2021</td></tr>
+<tr><td>Hoops Again</td><td><i>Space Jam</i></td><td>Jason Mantzoukas</td><td>June 7, 2021</td></tr>
+<tr><td>Haunted</td><td><i>Ghost</i></td><td>Lauren Lapkus</td><td>June 14, 2021</td></tr>
+</table></body></html>`
+
+	cfg := defaultConfig()
+	cfg.MergeEpisodes = true
+	batch, err := mock.newTestScraper(cfg).generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate batch list: %v", err)
+	}
+
+	cfg.StreamTitles = true
+	streamed, err := mock.newTestScraper(cfg).generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate streamed list: %v", err)
+	}
+
+	if !reflect.DeepEqual(batch, streamed) {
+		t.Errorf("Streamed list differs from batch list:\n%+v\n%+v", streamed, batch)
+	}
+	for _, movie := range streamed {
+		if movie.Title != "Space Jam" {
+			continue
+		}
+		if want := []string{"2021-03-01", "2021-06-07"}; !reflect.DeepEqual(movie.Episodes, want) {
+			t.Errorf("Expected Space Jam's episodes %v, got %v", want, movie.Episodes)
+		}
+		if want := []string{"Hoops", "Hoops Again"}; !reflect.DeepEqual(movie.episodeTitles, want) {
+			t.Errorf("Expected Space Jam's episode titles %v, got %v", want, movie.episodeTitles)
+		}
+		if movie.Guest != "Paul F. Tompkins" {
+			t.Errorf("Expected the first guest, got %q", movie.Guest)
+		}
+	}
+}
+
+func TestStreamRadarrListSinceUsesEarliestAirDate(t *testing.T) {
+	mock := newMockTMDB(t, nil, mockCatalog)
+	// Ghost's first row has no air date and Space Jam's first row is the
+	// recent one; only the earliest date counts
+	mock.wikiPage = `<html><body><table>
+<tr><th>Film</th><th>Air date</th></tr>
+<tr><td><i>Ghost</i></td><td>TBA</td></tr>
+<tr><td><i>Space Jam</i></td><td>June 7, 2021</td></tr>
+<tr><td><i>Ghost</i></td><td>June 14, 2021</td></tr>
+<tr><td><i>Space Jam</i></td><td>March 1, 2021</td></tr>
+</table></body></html>`
+
+	cfg := defaultConfig()
+	cfg.StreamTitles = true
+	cfg.Since = time.Date(2021, time.May, 1, 0, 0, 0, 0, time.UTC)
+	movies, err := mock.newTestScraper(cfg).generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+
+	if len(movies) != 1 || movies[0].Title != "Ghost" {
+		t.Errorf("Expected only Ghost to pass -since, got %+v", movies)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// RunSummary is a snapshot of a run's counts, printed periodically with
+// -summary-interval and written to -summary-file
+type RunSummary struct {
+	RunID        string  `json:"run_id"`
+	Queued       int64   `json:"queued"`
+	Completed    int64   `json:"completed"`
+	Successful   int64   `json:"successful"`
+	Failed       int64   `json:"failed"`
+	Retries      int64   `json:"retries"`
+	TMDBRequests int64   `json:"tmdb_requests"`
+	CacheHits    int64   `json:"cache_hits"`
+	Elapsed      float64 `json:"elapsed_seconds"`
+	Final        bool    `json:"final"`
+
+	FailuresByCategory []FailureGroup `json:"failures_by_category,omitempty"`
+}
+
+// runCounters are the per-title counts updated by the worker pool. They are
+// atomic so the summary reporter can read them while titles are resolving.
+type runCounters struct {
+	successful int64
+	failed     int64
+	retried    int64 // titles that needed more than one attempt
+	retries    int64 // attempts after the first, over all titles
+}
+
+// recordAttempts counts the retries a title needed
+func (c *runCounters) recordAttempts(attempts int) {
+	if attempts > 1 {
+		atomic.AddInt64(&c.retried, 1)
+		atomic.AddInt64(&c.retries, int64(attempts-1))
+	}
+}
+
+// snapshot returns the current counts as a RunSummary
+func (s *Scraper) snapshot(counters *runCounters, progress *progress, started time.Time) RunSummary {
+	return RunSummary{
+		RunID:        s.runID,
+		Queued:       atomic.LoadInt64(&progress.total),
+		Completed:    atomic.LoadInt64(&progress.completed),
+		Successful:   atomic.LoadInt64(&counters.successful),
+		Failed:       atomic.LoadInt64(&counters.failed),
+		Retries:      atomic.LoadInt64(&counters.retries),
+		TMDBRequests: atomic.LoadInt64(&s.tmdbRequests),
+		CacheHits:    atomic.LoadInt64(&s.cache.hits),
+		Elapsed:      s.now().Sub(started).Round(time.Second).Seconds(),
+	}
+}
+
+// startSummaryReporter prints an interim summary every -summary-interval
+// until the returned stop function is called. Stop writes the final summary
+// to -summary-file, keeps it for -post-hook and waits for the reporter to
+// exit. With a zero interval
+// only the final summary is written.
+func (s *Scraper) startSummaryReporter(snapshot func() RunSummary) (stop func()) {
+	quit := make(chan struct{})
+	exited := make(chan struct{})
+
+	go func() {
+		defer close(exited)
+		if s.config.SummaryInterval <= 0 {
+			<-quit
+			return
+		}
+
+		ticker := time.NewTicker(s.config.SummaryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				summary := snapshot()
+				fmt.Fprintf(s.logWriter(), "Interim summary after %s: %d/%d done, %d successful, %d failed, %d TMDB requests, %d cache hits\n",
+					time.Duration(summary.Elapsed)*time.Second, summary.Completed, summary.Queued, summary.Successful, summary.Failed, summary.TMDBRequests, summary.CacheHits)
+				s.writeSummary(summary)
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(quit)
+		<-exited
+
+		summary := snapshot()
+		summary.Final = true
+		s.summary = summary
+		s.writeSummary(summary)
+	}
+}
+
+// writeSummary replaces -summary-file with summary. The write is atomic so a
+// reader polling the file during a long run never sees a partial document.
+func (s *Scraper) writeSummary(summary RunSummary) {
+	if s.config.SummaryFile == "" {
+		return
+	}
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		log.Printf("Failed to encode run summary: %v", err)
+		return
+	}
+	if err := s.writeFile(s.config.SummaryFile, append(data, '\n')); err != nil {
+		log.Printf("Failed to write run summary: %v", err)
+	}
+}
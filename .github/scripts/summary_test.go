@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func readSummary(t *testing.T, filename string) RunSummary {
+	t.Helper()
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read summary: %v", err)
+	}
+	var summary RunSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatalf("Failed to decode summary %s: %v", data, err)
+	}
+	return summary
+}
+
+func TestSummaryInterval(t *testing.T) {
+	mock := newMockTMDB(t, []string{"Space Jam", "Ghost", "Unknown Movie"}, mockCatalog)
+
+	cfg := defaultConfig()
+	cfg.SummaryInterval = 10 * time.Millisecond
+	cfg.SummaryFile = filepath.Join(t.TempDir(), "summary.json")
+
+	// Hold the first search until an interim summary has been written
+	var once sync.Once
+	var interim RunSummary
+	mock.searchHook = func(query string) {
+		once.Do(func() {
+			deadline := time.Now().Add(5 * time.Second)
+			for time.Now().Before(deadline) {
+				if _, err := os.Stat(cfg.SummaryFile); err == nil {
+					interim = readSummary(t, cfg.SummaryFile)
+					return
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+			t.Error("Timed out waiting for an interim summary")
+		})
+	}
+
+	if _, err := mock.newTestScraper(cfg).generateRadarrList(context.Background()); err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+
+	if interim.Final || interim.Completed == 3 {
+		t.Errorf("Expected an unfinished interim summary, got %+v", interim)
+	}
+
+	final := readSummary(t, cfg.SummaryFile)
+	if !final.Final || final.Queued != 3 || final.Completed != 3 || final.Successful != 2 || final.Failed != 1 {
+		t.Errorf("Unexpected final summary %+v", final)
+	}
+	expected := []FailureGroup{{Category: failureNoResults, Count: 1, Examples: []string{"Unknown Movie"}}}
+	if !reflect.DeepEqual(final.FailuresByCategory, expected) {
+		t.Errorf("Expected the failures grouped by category, got %+v", final.FailuresByCategory)
+	}
+	if final.TMDBRequests == 0 {
+		t.Errorf("Expected TMDB requests to be counted, got %+v", final)
+	}
+}
+
+func TestSummaryFileWithoutInterval(t *testing.T) {
+	mock := newMockTMDB(t, []string{"Space Jam"}, mockCatalog)
+
+	cfg := defaultConfig()
+	cfg.SummaryFile = filepath.Join(t.TempDir(), "summary.json")
+	if _, err := mock.newTestScraper(cfg).generateRadarrList(context.Background()); err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+
+	if summary := readSummary(t, cfg.SummaryFile); !summary.Final || summary.Successful != 1 {
+		t.Errorf("Expected only the final summary, got %+v", summary)
+	}
+}
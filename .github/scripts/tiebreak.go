@@ -0,0 +1,76 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+)
+
+// Tie-break keys accepted by -year-tiebreak
+const (
+	tieBreakVotes      = "votes"      // most TMDB votes first
+	tieBreakPopularity = "popularity" // most popular first
+	tieBreakID         = "id"         // lowest TMDB ID first
+)
+
+// defaultYearTieBreak ends with the TMDB ID so that, by default, the same
+// candidates always resolve to the same movie whatever order TMDB lists them in
+const defaultYearTieBreak = tieBreakVotes + "," + tieBreakPopularity + "," + tieBreakID
+
+// defaultTieBreak is the parsed default chain
+var defaultTieBreak, _ = parseTieBreak(defaultYearTieBreak)
+
+// tieBreakers compare two candidates by one key, returning a negative number
+// when a is preferred
+var tieBreakers = map[string]func(a, b TMDBMovie) int{
+	tieBreakVotes:      func(a, b TMDBMovie) int { return cmp.Compare(b.VoteCount, a.VoteCount) },
+	tieBreakPopularity: func(a, b TMDBMovie) int { return cmp.Compare(b.Popularity, a.Popularity) },
+	tieBreakID:         func(a, b TMDBMovie) int { return cmp.Compare(a.ID, b.ID) },
+}
+
+// parseTieBreak splits a comma-separated -year-tiebreak chain, rejecting
+// unknown and repeated keys
+func parseTieBreak(value string) ([]string, error) {
+	var chain []string
+	seen := make(map[string]bool)
+	for _, key := range strings.Split(value, ",") {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if _, ok := tieBreakers[key]; !ok {
+			return nil, fmt.Errorf("unknown tie-break %q (expected %s, %s or %s)", key, tieBreakVotes, tieBreakPopularity, tieBreakID)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("tie-break %s is given more than once", key)
+		}
+		seen[key] = true
+		chain = append(chain, key)
+	}
+	return chain, nil
+}
+
+// yearTieBreak returns the -year-tiebreak chain
+func (c Config) yearTieBreak() []string {
+	chain, _ := parseTieBreak(c.YearTieBreak)
+	return chain
+}
+
+// breakTie picks from candidates released in the same year by comparing them
+// on each key of the chain in turn. Candidates equal on every key keep TMDB's
+// order. A nil chain uses the default.
+func breakTie(chain []string, candidates []TMDBMovie) TMDBMovie {
+	if chain == nil {
+		chain = defaultTieBreak
+	}
+
+	best := candidates[0]
+	for _, candidate := range candidates[1:] {
+		for _, key := range chain {
+			if order := tieBreakers[key](candidate, best); order != 0 {
+				if order < 0 {
+					best = candidate
+				}
+				break
+			}
+		}
+	}
+	return best
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestYearTieBreak(t *testing.T) {
+	year := time.Date(2021, 10, 22, 0, 0, 0, 0, time.UTC)
+	// The film and its making-of documentary, released the same year under
+	// the same title, with the documentary listed first
+	candidates := []TMDBMovie{
+		{ID: 900001, Title: "Dune", ReleaseDate: year, Popularity: 80, VoteCount: 12},
+		{ID: 438631, Title: "Dune", ReleaseDate: year, Popularity: 80, VoteCount: 12000},
+		{ID: 841, Title: "Dune", ReleaseDate: time.Date(1984, 12, 14, 0, 0, 0, 0, time.UTC), Popularity: 30, VoteCount: 4000},
+	}
+
+	testCases := []struct {
+		strategy string
+		tieBreak string
+		expected int
+	}{
+		{matchStrategyFirst, defaultYearTieBreak, 438631},
+		{matchStrategyFirst, "id", 438631},
+		{matchStrategyFirst, "popularity", 900001},
+		{matchStrategyExactYearThenPopular, defaultYearTieBreak, 438631},
+		{matchStrategyExactYearThenPopular, "popularity", 900001},
+	}
+
+	for _, tc := range testCases {
+		cfg, err := parseFlags([]string{"-match-strategy", tc.strategy, "-year-tiebreak", tc.tieBreak})
+		if err != nil {
+			t.Fatalf("Failed to parse flags: %v", err)
+		}
+		got, _ := NewScraper("dummy_key", WithConfig(cfg)).selectMatch(matchQuery{Title: "Dune", Year: 2021}, candidates)
+		if got.ID != tc.expected {
+			t.Errorf("%s with -year-tiebreak %s: expected TMDB ID %d, got %d", tc.strategy, tc.tieBreak, tc.expected, got.ID)
+		}
+	}
+
+	// The lowest ID settles candidates equal on everything else, whatever
+	// order TMDB returns them in
+	tied := []TMDBMovie{candidates[0], candidates[1]}
+	tied[1].VoteCount = tied[0].VoteCount
+	if got := breakTie(nil, tied); got.ID != 438631 {
+		t.Errorf("Expected the lowest TMDB ID to win a full tie, got %d", got.ID)
+	}
+
+	for _, invalid := range []string{"votes,rating", "id,id", ""} {
+		if _, err := parseFlags([]string{"-year-tiebreak", invalid}); err == nil {
+			t.Errorf("Expected -year-tiebreak %q to be rejected", invalid)
+		}
+	}
+}
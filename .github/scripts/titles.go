@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// titlesArtifactVersion is the schema version of the titles artifact written
+// by the scrape command. Bump it when the schema changes incompatibly.
+const titlesArtifactVersion = 1
+
+// TitlesArtifact is the output of the scrape command and the input of the
+// resolve command
+type TitlesArtifact struct {
+	Version   int       `json:"version"`
+	Source    string    `json:"source"`
+	ScrapedAt time.Time `json:"scraped_at"`
+	Titles    []string  `json:"titles"`
+
+	// AirDates maps titles to the YYYY-MM-DD date of the earliest episode
+	// they were discussed on, where the wiki lists one
+	AirDates map[string]string `json:"air_dates,omitempty"`
+
+	// Guests maps titles to the guest who picked them, where the wiki names one
+	Guests map[string]string `json:"guests,omitempty"`
+
+	// EpisodeTitles maps titles to the titles of the episodes they were
+	// discussed on, where the wiki lists them
+	EpisodeTitles map[string][]string `json:"episode_titles,omitempty"`
+
+	// RawTitles maps titles to their wiki text before cleanup
+	RawTitles map[string]string `json:"raw_titles,omitempty"`
+
+	// Years maps titles to the release year written next to them on the wiki
+	Years map[string]int `json:"years,omitempty"`
+}
+
+// newTitlesArtifact wraps titles scraped from source at scrapedAt
+func newTitlesArtifact(source string, titles []string, scrapedAt time.Time) TitlesArtifact {
+	return TitlesArtifact{
+		Version:   titlesArtifactVersion,
+		Source:    source,
+		ScrapedAt: scrapedAt.UTC().Truncate(time.Second),
+		Titles:    titles,
+	}
+}
+
+// loadTitlesArtifact reads a titles artifact, rejecting unknown schema versions
+func loadTitlesArtifact(filename string) (TitlesArtifact, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return TitlesArtifact{}, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+
+	var artifact TitlesArtifact
+	if err := json.Unmarshal(data, &artifact); err != nil {
+		return TitlesArtifact{}, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	if artifact.Version != titlesArtifactVersion {
+		return TitlesArtifact{}, fmt.Errorf("%s has titles schema version %d, expected %d", filename, artifact.Version, titlesArtifactVersion)
+	}
+	for title, date := range artifact.AirDates {
+		if _, err := time.Parse(airDateLayout, date); err != nil {
+			return TitlesArtifact{}, fmt.Errorf("%s has invalid air date %q for %q", filename, date, title)
+		}
+	}
+
+	return artifact, nil
+}
+
+// saveTitlesArtifact writes a titles artifact as indented JSON
+func (s *Scraper) saveTitlesArtifact(artifact TitlesArtifact, filename string) error {
+	data, err := json.MarshalIndent(artifact, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal titles: %w", err)
+	}
+
+	if err := s.writeFile(filename, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write titles file: %w", err)
+	}
+
+	fmt.Fprintf(s.logWriter(), "Saved %d titles to %s\n", len(artifact.Titles), filename)
+	return nil
+}
+
+// readTitleList reads the -titles-file list in place of the wiki: one title
+// per line, cleaned up as wiki titles are. The wiki's drop rules, which are
+// specific to the show, don't apply, but -config-dir skip lists do.
+func (s *Scraper) readTitleList(filename string) ([]string, error) {
+	lines, err := readTitleLines(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read titles file: %w", err)
+	}
+
+	var titles []string
+	seen := make(titleYears)
+	for _, line := range lines {
+		cleaned := s.cleanTitle(line)
+		year := releaseYearHint(line)
+
+		// Same-title entries with different years are different films
+		title, isNew := seen.key(cleaned, year)
+		s.recordYearHint(title, year)
+		s.recordRawTitle(title, line)
+
+		if !isNew {
+			s.recordDrop(title, dropRuleDuplicate, "")
+			continue
+		}
+		if s.skipTitles[normalizeTitle(cleaned)] {
+			s.recordDrop(title, dropRuleSkipList, "")
+			continue
+		}
+		titles = append(titles, title)
+	}
+	return titles, nil
+}
+
+// scrapeToFile scrapes the wiki and writes its titles to a titles artifact,
+// saving the wiki page validators once the artifact is written
+func (s *Scraper) scrapeToFile(filename string) error {
+	titles, err := s.scrapeTitles()
+	if err != nil {
+		return err
+	}
+
+	source := s.wikiURL
+	if s.config.TitleListFile != "" {
+		source = s.config.TitleListFile
+	}
+	artifact := newTitlesArtifact(source, titles, s.now())
+	for _, title := range titles {
+		if date, ok := s.airDates[title]; ok {
+			if artifact.AirDates == nil {
+				artifact.AirDates = make(map[string]string)
+			}
+			artifact.AirDates[title] = date.Format(airDateLayout)
+		}
+		if guest, ok := s.guests[title]; ok {
+			if artifact.Guests == nil {
+				artifact.Guests = make(map[string]string)
+			}
+			artifact.Guests[title] = guest
+		}
+		if episodes, ok := s.episodeTitles[title]; ok {
+			if artifact.EpisodeTitles == nil {
+				artifact.EpisodeTitles = make(map[string][]string)
+			}
+			artifact.EpisodeTitles[title] = episodes
+		}
+		if raw, ok := s.rawTitles[title]; ok {
+			if artifact.RawTitles == nil {
+				artifact.RawTitles = make(map[string]string)
+			}
+			artifact.RawTitles[title] = raw
+		}
+		if year, ok := s.yearHints[title]; ok {
+			if artifact.Years == nil {
+				artifact.Years = make(map[string]int)
+			}
+			artifact.Years[title] = year
+		}
+	}
+
+	if err := s.saveTitlesArtifact(artifact, filename); err != nil {
+		return err
+	}
+
+	return s.saveWikiState()
+}
+
+// resolveTitlesFile resolves the titles in a titles artifact on TMDB
+func (s *Scraper) resolveTitlesFile(ctx context.Context, filename string) ([]Movie, error) {
+	artifact, err := loadTitlesArtifact(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	for title, date := range artifact.AirDates {
+		airDate, _ := time.Parse(airDateLayout, date)
+		s.recordAirDate(title, airDate)
+	}
+	for title, guest := range artifact.Guests {
+		s.recordGuest(title, guest)
+	}
+	for title, episodes := range artifact.EpisodeTitles {
+		for _, episode := range episodes {
+			s.recordEpisodeTitle(title, episode)
+		}
+	}
+	for title, raw := range artifact.RawTitles {
+		s.recordRawTitle(title, raw)
+	}
+	for title, year := range artifact.Years {
+		s.recordYearHint(title, year)
+	}
+
+	fmt.Fprintf(s.logWriter(), "Loaded %d titles scraped from %s at %s\n", len(artifact.Titles), artifact.Source, artifact.ScrapedAt.Format(time.RFC3339))
+	return s.resolveTitles(ctx, artifact.Titles)
+}
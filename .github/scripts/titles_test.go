@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScrapeThenResolve(t *testing.T) {
+	mock := newMockTMDB(t, []string{"Space Jam", "Ghost"}, mockCatalog)
+	filename := filepath.Join(t.TempDir(), "titles.json")
+
+	if err := mock.newTestScraper(defaultConfig()).scrapeToFile(filename); err != nil {
+		t.Fatalf("Failed to scrape titles: %v", err)
+	}
+
+	artifact, err := loadTitlesArtifact(filename)
+	if err != nil {
+		t.Fatalf("Failed to load titles artifact: %v", err)
+	}
+	if artifact.Version != titlesArtifactVersion || artifact.Source != mock.URL+"/wiki" || artifact.ScrapedAt.IsZero() {
+		t.Errorf("Unexpected artifact metadata: %+v", artifact)
+	}
+	if strings.Join(artifact.Titles, ",") != "Space Jam,Ghost" {
+		t.Errorf("Expected scraped titles in page order, got %v", artifact.Titles)
+	}
+
+	// Resolution works from the artifact alone, even with the wiki gone
+	mock.wikiTitles = nil
+	movies, err := mock.newTestScraper(defaultConfig()).resolveTitlesFile(context.Background(), filename)
+	if err != nil {
+		t.Fatalf("Failed to resolve titles: %v", err)
+	}
+	if len(movies) != 2 || movies[0].Title != "Ghost" || movies[1].Title != "Space Jam" {
+		t.Errorf("Expected Ghost and Space Jam, got %+v", movies)
+	}
+}
+
+func TestLoadTitlesArtifactRejectsUnknownVersion(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "titles.json")
+	if err := os.WriteFile(filename, []byte(`{"version": 2, "titles": ["Dune"]}`), 0644); err != nil {
+		t.Fatalf("Failed to write artifact: %v", err)
+	}
+
+	if _, err := loadTitlesArtifact(filename); err == nil {
+		t.Error("Expected an unknown schema version to be rejected")
+	}
+}
+
+func TestParseFlagsCommand(t *testing.T) {
+	cfg, err := parseFlags([]string{"-force"})
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if cfg.Command != commandRun {
+		t.Errorf("Expected the run command by default, got %s", cfg.Command)
+	}
+
+	cfg, err = parseFlags([]string{"resolve", "-titles", "cached.json", "-format", "radarr"})
+	if err != nil {
+		t.Fatalf("Failed to parse resolve command: %v", err)
+	}
+	if cfg.Command != commandResolve || cfg.TitlesFile != "cached.json" || cfg.Format != formatRadarr {
+		t.Errorf("Unexpected config for resolve: %+v", cfg)
+	}
+
+	if _, err := parseFlags([]string{"publish"}); err == nil {
+		t.Error("Expected an unknown command to be rejected")
+	}
+	if _, err := parseFlags([]string{"scrape", "-titles", ""}); err == nil {
+		t.Error("Expected scrape without a titles file to be rejected")
+	}
+}
+
+func TestTitlesFile(t *testing.T) {
+	mock := newMockTMDB(t, nil, mockCatalog)
+	filename := filepath.Join(t.TempDir(), "titles.txt")
+	content := "# Curated matching cases\nSpace Jam (1996)[1]\n\n  Ghost  \nghost\nDid You Hear About the Morgans?\n"
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write titles file: %v", err)
+	}
+
+	cfg, err := parseFlags([]string{"-titles-file", filename, "-stdout"})
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	scraper := mock.newTestScraper(cfg)
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+
+	// Titles are cleaned and deduplicated, but the wiki's drop rules, such as
+	// the "did" keyword, don't apply
+	if len(movies) != 2 || movies[0].Title != "Ghost" || movies[1].Title != "Space Jam" {
+		t.Errorf("Expected Ghost and Space Jam, got %+v", movies)
+	}
+	if len(scraper.failures) != 1 || scraper.failures[0].Title != "Did You Hear About the Morgans?" {
+		t.Errorf("Expected the unknown title to be searched and fail, got %+v", scraper.failures)
+	}
+	if scraper.dropCounts[dropRuleDuplicate] != 1 {
+		t.Errorf("Expected the repeated title to be dropped, got %v", scraper.dropCounts)
+	}
+
+	if len(scraper.sinks) != 0 {
+		t.Errorf("Expected no list files to be written, got %+v", scraper.sinks)
+	}
+
+	if _, err := parseFlags([]string{"resolve", "-titles", "titles.json", "-titles-file", filename}); err == nil {
+		t.Error("Expected -titles-file with the resolve command to be rejected")
+	}
+	if _, err := parseFlags([]string{"-titles-file", filename}); err == nil {
+		t.Error("Expected -titles-file without -stdout or -radarr-url to be rejected")
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import "fmt"
+
+// recordYearHint remembers the release year the wiki gave with a title's
+// first entry that had one, to pick between same-title search results
+func (s *Scraper) recordYearHint(title string, year int) {
+	if year <= 0 {
+		return
+	}
+	s.metaMu.Lock()
+	defer s.metaMu.Unlock()
+	if _, ok := s.yearHints[title]; !ok {
+		s.yearHints[title] = year
+	}
+}
+
+// yearHint returns the year recorded for a title, or 0 if there is none
+func (s *Scraper) yearHint(title string) int {
+	s.metaMu.Lock()
+	defer s.metaMu.Unlock()
+	return s.yearHints[title]
+}
+
+// datedTitle is one film seen under a title
+type datedTitle struct {
+	year int // 0 if the wiki didn't give one
+	key  string
+}
+
+// titleYears tracks the films seen under each normalized title while
+// extracting, so entries that share a title but name different years, such
+// as "The Mummy (1999)" and "The Mummy (2017)", are kept as different films
+type titleYears map[string][]datedTitle
+
+// key returns the title to resolve an entry under, and whether it is a film
+// not seen before. An entry is the same film as an earlier one with the same
+// year, or when either year is unknown. The first film with a title keeps the
+// plain title; later ones get their year appended, e.g. "The Mummy (2017)".
+func (t titleYears) key(title string, year int) (string, bool) {
+	normalized := normalizeTitle(title)
+	films := t[normalized]
+	for i, film := range films {
+		if film.year == year || film.year == 0 || year == 0 {
+			if film.year == 0 {
+				films[i].year = year
+			}
+			return film.key, false
+		}
+	}
+
+	key := title
+	if len(films) > 0 {
+		key = fmt.Sprintf("%s (%d)", title, year)
+	}
+	t[normalized] = append(films, datedTitle{year: year, key: key})
+	return key, true
+}
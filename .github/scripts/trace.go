@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Endpoints tracked in the latency summary
+const (
+	endpointWiki         = "wiki"
+	endpointSearch       = "search"
+	endpointSearchMulti  = "search_multi"
+	endpointExternalIDs  = "external_ids"
+	endpointFind         = "find"
+	endpointAuth         = "auth"
+	endpointReleaseDates = "release_dates"
+	endpointIMDBSuggest  = "imdb_suggest"
+	endpointKeywords     = "keywords"
+)
+
+// LatencySummary aggregates request durations for one endpoint
+type LatencySummary struct {
+	Endpoint string        `json:"endpoint"`
+	Count    int           `json:"count"`
+	Min      time.Duration `json:"min"`
+	Avg      time.Duration `json:"avg"`
+	Max      time.Duration `json:"max"`
+	P95      time.Duration `json:"p95"`
+}
+
+// latencyStats collects request durations per endpoint
+type latencyStats struct {
+	mu      sync.Mutex
+	samples map[string][]time.Duration
+}
+
+func newLatencyStats() *latencyStats {
+	return &latencyStats{samples: make(map[string][]time.Duration)}
+}
+
+// record adds a request duration for an endpoint
+func (l *latencyStats) record(endpoint string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.samples[endpoint] = append(l.samples[endpoint], d)
+}
+
+// summaries returns min/avg/max/p95 per endpoint, ordered by endpoint name
+func (l *latencyStats) summaries() []LatencySummary {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	summaries := make([]LatencySummary, 0, len(l.samples))
+	for endpoint, samples := range l.samples {
+		sorted := append([]time.Duration(nil), samples...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		var total time.Duration
+		for _, d := range sorted {
+			total += d
+		}
+
+		// Nearest-rank 95th percentile
+		rank := (len(sorted)*95 + 99) / 100
+		summaries = append(summaries, LatencySummary{
+			Endpoint: endpoint,
+			Count:    len(sorted),
+			Min:      sorted[0],
+			Avg:      total / time.Duration(len(sorted)),
+			Max:      sorted[len(sorted)-1],
+			P95:      sorted[rank-1],
+		})
+	}
+
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Endpoint < summaries[j].Endpoint })
+	return summaries
+}
+
+// doRequest sends a request, recording its duration for the latency summary
+// and logging the endpoint, subject, status and duration at debug level.
+// Failed requests are also written to -failed-requests-log.
+// TMDB requests are counted against -max-requests and refused once it is reached.
+func (s *Scraper) doRequest(req *http.Request, endpoint, subject string) (*http.Response, error) {
+	tmdb := endpoint != endpointWiki && endpoint != endpointIMDBSuggest
+	if tmdb {
+		count := atomic.AddInt64(&s.tmdbRequests, 1)
+		if s.config.MaxRequests > 0 && count > int64(s.config.MaxRequests) {
+			atomic.AddInt64(&s.tmdbRequests, -1)
+			return nil, errQuotaExceeded
+		}
+	}
+
+	// Checked after the quota, so a half-open trial request the breaker lets
+	// through is always sent and its outcome recorded
+	breaker := s.breakerFor(req.URL.Hostname())
+	if breaker != nil && !breaker.allow() {
+		if tmdb {
+			atomic.AddInt64(&s.tmdbRequests, -1)
+		}
+		return nil, fmt.Errorf("%s: %w", req.URL.Hostname(), errCircuitOpen)
+	}
+
+	if endpoint == endpointWiki && s.sendsWikiCookie(req.URL) {
+		req.Header.Set("Cookie", s.wikiCookie)
+	}
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	elapsed := time.Since(start)
+	if breaker != nil {
+		breaker.record(requestFailed(resp, err))
+	}
+
+	s.latency.record(endpoint, elapsed)
+	s.recordFailure(req, endpoint, resp, err)
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	s.debugf("%s %q -> %s in %s", endpoint, subject, status, elapsed.Round(time.Millisecond))
+
+	if err == nil && endpoint == endpointWiki {
+		return s.checkWikiRedirect(req, resp)
+	}
+	return resp, err
+}
+
+// printLatencySummary prints request latency per endpoint for the run summary
+func (s *Scraper) printLatencySummary() {
+	summaries := s.latency.summaries()
+	if len(summaries) == 0 {
+		return
+	}
+
+	fmt.Fprintf(s.logWriter(), "\nRequest latency:\n")
+	for _, l := range summaries {
+		fmt.Fprintf(s.logWriter(), "  %-13s n=%-4d min=%-8s avg=%-8s max=%-8s p95=%s\n", l.Endpoint, l.Count,
+			l.Min.Round(time.Millisecond), l.Avg.Round(time.Millisecond),
+			l.Max.Round(time.Millisecond), l.P95.Round(time.Millisecond))
+	}
+}
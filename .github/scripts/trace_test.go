@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLatencySummaries(t *testing.T) {
+	stats := newLatencyStats()
+	for i := 1; i <= 20; i++ {
+		stats.record(endpointSearch, time.Duration(i)*time.Millisecond)
+	}
+	stats.record(endpointWiki, 500*time.Millisecond)
+
+	summaries := stats.summaries()
+	if len(summaries) != 2 {
+		t.Fatalf("Expected 2 endpoints, got %d", len(summaries))
+	}
+
+	search := summaries[0]
+	if search.Endpoint != endpointSearch {
+		t.Fatalf("Expected endpoints ordered by name, got %s first", search.Endpoint)
+	}
+	if search.Count != 20 || search.Min != time.Millisecond || search.Max != 20*time.Millisecond {
+		t.Errorf("Unexpected count/min/max: %+v", search)
+	}
+	if search.Avg != 10500*time.Microsecond {
+		t.Errorf("Expected avg 10.5ms, got %s", search.Avg)
+	}
+	if search.P95 != 19*time.Millisecond {
+		t.Errorf("Expected p95 19ms, got %s", search.P95)
+	}
+
+	wiki := summaries[1]
+	if wiki.Count != 1 || wiki.P95 != 500*time.Millisecond {
+		t.Errorf("Unexpected single-sample summary: %+v", wiki)
+	}
+}
+
+func TestMaxRequestsQuota(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"page":1,"total_pages":1,"results":[{"id":841,"title":"Dune"}]}`))
+	}))
+	defer server.Close()
+
+	cfg := defaultConfig()
+	cfg.MaxRequests = 1
+	scraper := NewScraper("dummy_key", WithConfig(cfg))
+	scraper.tmdbBaseURL = server.URL
+
+	// The search uses the only request; the external IDs lookup is refused
+	_, err := scraper.searchMovie("Dune")
+	if err == nil {
+		t.Fatal("Expected the quota to stop the external IDs lookup")
+	}
+	if got := failureCategory(err); got != failureQuotaExceeded {
+		t.Errorf("Expected category %s, got %s (%v)", failureQuotaExceeded, got, err)
+	}
+	if scraper.tmdbRequests != 1 {
+		t.Errorf("Expected 1 request counted, got %d", scraper.tmdbRequests)
+	}
+}
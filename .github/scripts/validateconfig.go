@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// validateConfigInputs loads every file and setting a run would read before
+// doing any work, returning each problem found rather than stopping at the
+// first. Flag consistency is already checked by parseFlags. Nothing is
+// fetched and no output is written.
+func validateConfigInputs(cfg Config) []error {
+	var problems []error
+	check := func(input string, err error) {
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", input, err))
+		}
+	}
+
+	if cfg.APIKeyFile != "" {
+		_, err := cfg.apiKey()
+		check("-api-key-file", err)
+	}
+	if cfg.WikiCookieFile != "" {
+		_, err := cfg.wikiCookie()
+		check("-cookie-file", err)
+	}
+	if cfg.OverridesFile != "" {
+		_, err := loadOverrides(cfg.OverridesFile)
+		check("-overrides", err)
+	}
+	if cfg.GenreAliasesFile != "" {
+		_, err := loadGenreAliases(cfg.GenreAliasesFile)
+		check("-genre-aliases", err)
+	}
+	if cfg.ConfigDir != "" {
+		_, err := loadConfigDir(cfg.ConfigDir)
+		check("-config-dir", err)
+	}
+	if cfg.TitleListFile != "" {
+		_, err := readTitleLines(cfg.TitleListFile)
+		check("-titles-file", err)
+	}
+	if cfg.ResolvedSet != "" {
+		_, err := loadResolvedSet(cfg.ResolvedSet)
+		check("-resolved-set", err)
+	}
+	if cfg.Command == commandResolve {
+		_, err := loadTitlesArtifact(cfg.TitlesFile)
+		check("-titles", err)
+	}
+	if !cfg.Force {
+		_, err := loadWikiState(cfg.StateFile)
+		check("-state-file", err)
+	}
+	if cfg.Offline != "" {
+		if info, err := os.Stat(cfg.Offline); err != nil {
+			check("-offline", err)
+		} else if !info.IsDir() {
+			check("-offline", fmt.Errorf("%s is not a directory", cfg.Offline))
+		}
+	}
+	if cfg.RadarrURL != "" && os.Getenv("RADARR_API_KEY") == "" {
+		check("-radarr-url", fmt.Errorf("RADARR_API_KEY environment variable not set"))
+	}
+	return problems
+}
+
+// reportConfigProblems prints the result of -validate-config and reports
+// whether the configuration is valid
+func reportConfigProblems(w io.Writer, problems []error) bool {
+	if len(problems) == 0 {
+		fmt.Fprintln(w, "Configuration is valid")
+		return true
+	}
+
+	fmt.Fprintf(w, "Found %d configuration problems:\n", len(problems))
+	for _, problem := range problems {
+		fmt.Fprintf(w, "  - %v\n", problem)
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateConfigInputs(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return filename
+	}
+
+	cfg := defaultConfig()
+	cfg.StateFile = ""
+	cfg.OverridesFile = write("overrides.json", `{"Dune": "tt0087182"}`)
+	cfg.GenreAliasesFile = write("aliases.json", `{"science_fiction": "sci-fi"}`)
+	if problems := validateConfigInputs(cfg); len(problems) != 0 {
+		t.Errorf("Expected a valid configuration, got %v", problems)
+	}
+
+	// Every problem is reported, not just the first
+	cfg.OverridesFile = write("overrides.json", `{"Dune": "tt87182"}`)
+	cfg.GenreAliasesFile = write("aliases.json", `{"science_fiction": "drama"}`)
+	cfg.Offline = filepath.Join(dir, "missing")
+	problems := validateConfigInputs(cfg)
+	if len(problems) != 3 {
+		t.Fatalf("Expected 3 problems, got %v", problems)
+	}
+	for i, flag := range []string{"-overrides", "-genre-aliases", "-offline"} {
+		if !strings.HasPrefix(problems[i].Error(), flag+": ") {
+			t.Errorf("Expected problem %d to name %s, got %v", i, flag, problems[i])
+		}
+	}
+
+	var output strings.Builder
+	if reportConfigProblems(&output, problems) || !strings.Contains(output.String(), "Found 3 configuration problems") {
+		t.Errorf("Expected the problems to be reported, got %q", output.String())
+	}
+}
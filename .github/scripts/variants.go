@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// romanNumerals maps the sequel numerals worth trying to their values. "I" is
+// left out since it is far more often the pronoun than a numeral.
+var romanNumerals = map[string]int{
+	"II": 2, "III": 3, "IV": 4, "V": 5, "VI": 6, "VII": 7, "VIII": 8, "IX": 9, "X": 10,
+	"XI": 11, "XII": 12, "XIII": 13, "XIV": 14, "XV": 15, "XVI": 16, "XVII": 17, "XVIII": 18, "XIX": 19, "XX": 20,
+}
+
+// arabicNumerals is the reverse of romanNumerals
+var arabicNumerals = func() map[int]string {
+	arabic := make(map[int]string, len(romanNumerals))
+	for roman, value := range romanNumerals {
+		arabic[value] = roman
+	}
+	return arabic
+}()
+
+// numeralWordPattern matches standalone words that may be numerals
+var numeralWordPattern = regexp.MustCompile(`\b([IVX]+|\d{1,2})\b`)
+
+// titleVariants returns alternative spellings of a title to search for when
+// the title itself finds nothing: roman numerals swapped for arabic ones and
+// back ("Rocky IV" and "Rocky 4"), and "&" swapped with "and"
+func titleVariants(title string) []string {
+	var variants []string
+	seen := map[string]bool{title: true}
+	add := func(variant string) {
+		if !seen[variant] {
+			seen[variant] = true
+			variants = append(variants, variant)
+		}
+	}
+
+	numerals := numeralWordPattern.ReplaceAllStringFunc(title, func(word string) string {
+		if value, ok := romanNumerals[word]; ok {
+			return strconv.Itoa(value)
+		}
+		if value, err := strconv.Atoi(word); err == nil {
+			if roman, ok := arabicNumerals[value]; ok {
+				return roman
+			}
+		}
+		return word
+	})
+	add(numerals)
+
+	for _, base := range []string{title, numerals} {
+		switch {
+		case strings.Contains(base, " & "):
+			add(strings.ReplaceAll(base, " & ", " and "))
+		case strings.Contains(base, " and "):
+			add(strings.ReplaceAll(base, " and ", " & "))
+		}
+	}
+
+	return variants
+}
+
+// leadingArticlePattern matches an English article at the start of a title
+var leadingArticlePattern = regexp.MustCompile(`(?i)^(?:the|an?)\s+`)
+
+// stripLeadingArticle drops a leading article, so "The Addams Family" can be
+// found where TMDB lists it as "Addams Family". It returns false if the title
+// has no article or would be left empty.
+func stripLeadingArticle(title string) (string, bool) {
+	stripped := leadingArticlePattern.ReplaceAllString(title, "")
+	if stripped == title || strings.TrimSpace(stripped) == "" {
+		return "", false
+	}
+	return stripped, true
+}
+
+// searchVariants tries each title variant in turn after the title itself
+// found no results, with -title-variants, then the title without its leading
+// article, returning the first match. It returns errNoResults if none
+// matched, or the first other error, which stops the search so the title's
+// failure keeps its real cause.
+func (s *Scraper) searchVariants(title string) (*Movie, error) {
+	type attempt struct{ title, method string }
+	var attempts []attempt
+	if s.config.TitleVariants {
+		for _, variant := range titleVariants(title) {
+			attempts = append(attempts, attempt{variant, matchMethodVariant})
+		}
+	}
+	if stripped, ok := stripLeadingArticle(title); ok {
+		attempts = append(attempts, attempt{stripped, matchMethodArticleStripped})
+	}
+
+	if len(attempts) > 0 {
+		s.explainf("No results; trying title variants")
+	}
+	for _, a := range attempts {
+		s.explainf("Trying variant %q", a.title)
+		movie, err := s.searchMovieUncached(a.title)
+		if err == nil {
+			s.titlef("  Matched %q as variant %q\n", title, a.title)
+			movie.MatchMethod = a.method
+			return movie, nil
+		}
+		if !errors.Is(err, errNoResults) {
+			return nil, err
+		}
+	}
+	return nil, errNoResults
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestTitleVariants(t *testing.T) {
+	testCases := []struct {
+		title    string
+		expected []string
+	}{
+		{"Rocky IV", []string{"Rocky 4"}},
+		{"Police Academy 4: Citizens on Patrol", []string{"Police Academy IV: Citizens on Patrol"}},
+		{"Star Trek II: The Wrath of Khan", []string{"Star Trek 2: The Wrath of Khan"}},
+		{"Harold & Kumar Go to White Castle", []string{"Harold and Kumar Go to White Castle"}},
+		{"Bill and Ted's Excellent Adventure", []string{"Bill & Ted's Excellent Adventure"}},
+		{"Bill & Ted Face the Music 3", []string{"Bill & Ted Face the Music III", "Bill and Ted Face the Music 3", "Bill and Ted Face the Music III"}},
+		{"I, Robot", nil},
+		{"Ghost", nil},
+	}
+
+	for _, tc := range testCases {
+		if got := titleVariants(tc.title); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("titleVariants(%q) = %q, expected %q", tc.title, got, tc.expected)
+		}
+	}
+}
+
+func TestSearchMovieTitleVariants(t *testing.T) {
+	catalog := []mockMovie{
+		{ID: 1374, Title: "Rocky IV", ReleaseDate: "1985-11-21", IMDBID: "tt0089927"},
+		{ID: 10153, Title: "Police Academy 4: Citizens on Patrol", ReleaseDate: "1987-04-03", IMDBID: "tt0093756"},
+	}
+	mock := newMockTMDB(t, []string{"Rocky 4", "Police Academy IV: Citizens on Patrol"}, catalog)
+
+	movies, err := mock.newTestScraper(defaultConfig()).generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if len(movies) != 0 {
+		t.Errorf("Expected no matches without -title-variants, got %+v", movies)
+	}
+
+	cfg := defaultConfig()
+	cfg.TitleVariants = true
+	movies, err = mock.newTestScraper(cfg).generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if len(movies) != 2 {
+		t.Fatalf("Expected both sequels to match through a variant, got %+v", movies)
+	}
+	for _, movie := range movies {
+		if movie.MatchMethod != matchMethodVariant {
+			t.Errorf("%s: expected match method %s, got %s", movie.Title, matchMethodVariant, movie.MatchMethod)
+		}
+	}
+}
+
+func TestStripLeadingArticle(t *testing.T) {
+	testCases := []struct {
+		title    string
+		expected string // empty if nothing should be stripped
+	}{
+		{"The Addams Family", "Addams Family"},
+		{"A Quiet Place", "Quiet Place"},
+		{"An American Tail", "American Tail"},
+		{"the thing", "thing"},
+		{"Theodore Rex", ""},
+		{"Amélie", ""},
+		{"The", ""},
+	}
+
+	for _, tc := range testCases {
+		got, ok := stripLeadingArticle(tc.title)
+		if got != tc.expected || ok != (tc.expected != "") {
+			t.Errorf("stripLeadingArticle(%q) = %q, %v, expected %q", tc.title, got, ok, tc.expected)
+		}
+	}
+}
+
+func TestSearchMovieArticleStripped(t *testing.T) {
+	catalog := []mockMovie{
+		{ID: 2907, Title: "Addams Family", ReleaseDate: "1991-11-22", IMDBID: "tt0101272"},
+		{ID: 1374, Title: "Rocky IV", ReleaseDate: "1985-11-21", IMDBID: "tt0089927"},
+	}
+	mock := newMockTMDB(t, nil, catalog)
+	cfg := defaultConfig()
+	cfg.TitleVariants = true
+	scraper := mock.newTestScraper(cfg)
+
+	// Fallback matches are less certain than a direct hit, by their method's penalty
+	testCases := []struct {
+		title      string
+		method     string
+		confidence float64
+	}{
+		{"Addams Family", matchMethodExact, 1},
+		{"The Addams Family", matchMethodArticleStripped, 1 - articleStrippedPenalty},
+		{"Rocky 4", matchMethodVariant, 1 - variantPenalty},
+	}
+	for _, tc := range testCases {
+		movie, err := scraper.searchMovie(tc.title)
+		if err != nil {
+			t.Errorf("%s: failed to resolve: %v", tc.title, err)
+			continue
+		}
+		if movie.MatchMethod != tc.method || movie.MatchConfidence != tc.confidence {
+			t.Errorf("%s: expected %s with confidence %.2f, got %s with %.2f", tc.title, tc.method, tc.confidence, movie.MatchMethod, movie.MatchConfidence)
+		}
+	}
+
+	if got := penalizedConfidence(matchMethodIMDBSuggestion, 0.05); got != 0 {
+		t.Errorf("Expected confidence not to go below 0, got %.2f", got)
+	}
+
+	// The article fallback doesn't need -title-variants
+	scraper = mock.newTestScraper(defaultConfig())
+	if movie, err := scraper.searchMovie("The Addams Family"); err != nil || movie.MatchMethod != matchMethodArticleStripped {
+		t.Errorf("Expected the article fallback by default, got %+v (%v)", movie, err)
+	}
+}
+
+func TestSearchVariantsKeepsOtherErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("query") == "Outage" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"page": 1, "results": [], "total_pages": 1, "total_results": 0}`))
+	}))
+	defer server.Close()
+
+	cfg := defaultConfig()
+	cfg.TMDBBaseURL = server.URL
+	scraper := NewScraper("dummy_key", WithConfig(cfg))
+
+	// Rate limiting on the fallback isn't a matching miss
+	_, err := scraper.searchMovie("The Outage")
+	if got := failureCategory(err); got != failureHTTPError {
+		t.Errorf("Expected the fallback's error to be kept, got %s (%v)", got, err)
+	}
+}
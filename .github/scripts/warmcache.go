@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+)
+
+// cacheFile is the file written by -warm-cache and read by -import-cache.
+// Unlike a list, it keys each movie by the wiki title it resolved from, so
+// titles TMDB spells differently still hit the cache.
+type cacheFile struct {
+	Titles map[string]Movie `json:"titles"` // normalized wiki title -> movie
+}
+
+// loadCacheFile reads a -warm-cache file, reporting false if filename holds
+// something else, such as a list
+func loadCacheFile(filename string) (map[string]Movie, bool, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, false, nil
+	}
+
+	var file cacheFile
+	if err := json.Unmarshal(data, &file); err != nil || file.Titles == nil {
+		return nil, false, nil
+	}
+	return file.Titles, true, nil
+}
+
+// warmCache resolves every title only to fill the cache, then saves it to
+// filename. A cache already saved there is loaded first, so repeated runs
+// only look up titles it doesn't have yet. No list files are written.
+func (s *Scraper) warmCache(ctx context.Context, filename string) error {
+	if _, err := os.Stat(filename); err == nil {
+		if err := s.importCache(filename); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	before := len(s.cache.titlesCopy())
+
+	var err error
+	if s.config.Command == commandResolve {
+		_, err = s.resolveTitlesFile(ctx, s.config.TitlesFile)
+	} else {
+		_, err = s.generateRadarrList(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	titles := s.cache.titlesCopy()
+	data, err := json.MarshalIndent(cacheFile{Titles: titles}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cache: %w", err)
+	}
+	if err := s.writeFile(filename, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+
+	fmt.Fprintf(s.logWriter(), "\nWarmed cache with %d titles (%d new) in %s\n", len(titles), len(titles)-before, filename)
+	fmt.Fprintf(s.logWriter(), "  TMDB requests: %d\n", atomic.LoadInt64(&s.tmdbRequests))
+	fmt.Fprintf(s.logWriter(), "  Cache hits: %d\n", atomic.LoadInt64(&s.cache.hits))
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWarmCache(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "cache.json")
+	cfg := defaultConfig()
+	cfg.WarmCache = filename
+
+	mock := newMockTMDB(t, []string{"Space Jam", "Ghost", "Unknown Movie"}, mockCatalog)
+	scraper := mock.newTestScraper(cfg)
+	output := captureStdout(t, func() {
+		if err := scraper.warmCache(context.Background(), filename); err != nil {
+			t.Fatalf("Failed to warm cache: %v", err)
+		}
+	})
+	if strings.Contains(output, "Summary:") || !strings.Contains(output, "Warmed cache with 2 titles (2 new)") {
+		t.Errorf("Expected only the cache stats, got:\n%s", output)
+	}
+	warmed, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read cache: %v", err)
+	}
+
+	// A second run finds every resolvable title in the saved cache and
+	// writes it back unchanged
+	mock = newMockTMDB(t, []string{"Space Jam", "Ghost"}, nil)
+	scraper = mock.newTestScraper(cfg)
+	captureStdout(t, func() {
+		if err := scraper.warmCache(context.Background(), filename); err != nil {
+			t.Fatalf("Failed to warm cache again: %v", err)
+		}
+	})
+	if requests := atomic.LoadInt64(&scraper.tmdbRequests); requests != 0 {
+		t.Errorf("Expected no TMDB requests from a warm cache, got %d", requests)
+	}
+	if rewarmed, _ := os.ReadFile(filename); !bytes.Equal(rewarmed, warmed) {
+		t.Errorf("Expected the cache file to be unchanged, got:\n%s", rewarmed)
+	}
+
+	// A normal run imports it with -import-cache
+	scraper = mock.newTestScraper(defaultConfig())
+	if err := scraper.importCache(filename); err != nil {
+		t.Fatalf("Failed to import cache: %v", err)
+	}
+	movies, err := scraper.generateRadarrList(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate list: %v", err)
+	}
+	if len(movies) != 2 || atomic.LoadInt64(&scraper.tmdbRequests) != 0 {
+		t.Errorf("Expected both movies from the cache without TMDB requests, got %+v", movies)
+	}
+
+	if _, err := parseFlags([]string{"-warm-cache", filename, "-stdout"}); err == nil {
+		t.Error("Expected -warm-cache with -stdout to be rejected")
+	}
+}